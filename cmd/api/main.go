@@ -2,57 +2,98 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/health"
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
 	"github.com/AlexKhomenko00/hotel-system/internal/server"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
 )
 
-func gracefulShutdown(apiServer *http.Server, done chan bool) {
-	// Create context that listens for the interrupt signal from the OS.
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
+func gracefulShutdown(ctx context.Context, apiServer *http.Server, checker *health.Checker, drainDelay time.Duration, done chan bool) {
 	// Listen for the interrupt signal.
 	<-ctx.Done()
 
-	log.Println("shutting down gracefully, press Ctrl+C again to force")
+	slog.Info("Shutting down gracefully, press Ctrl+C again to force")
+
+	// Keep serving with readiness failing until the probes have taken the pod out of rotation, requests
+	// routed to it in the meantime would otherwise be refused.
+	checker.Drain()
+	slog.Info("Draining", "delay", drainDelay)
+	time.Sleep(drainDelay)
 
 	// The context is used to inform the server it has 5 seconds to finish
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := apiServer.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown with error: %v", err)
+		slog.Error("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("Server exiting")
+	slog.Info("Server exiting")
 
 	// Notify the main goroutine that the shutdown is complete
 	done <- true
 }
 
 func main() {
+	// Create context that listens for the interrupt signal from the OS.
+	// Background workers stop with it as well.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Parse()
 
-	server := server.NewServer()
+	validator := shared.NewValidator()
+	cfg, err := loader.Load(validator)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger, err := logging.New(os.Stderr, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Exporter, "hotel-system-api")
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	server, checker := server.NewServer(ctx, cfg, validator)
 
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
 	// Run graceful shutdown in a separate goroutine
-	go gracefulShutdown(server, done)
+	go gracefulShutdown(ctx, server, checker, cfg.Server.ShutdownDrainDelay, done)
 
-	log.Println("Starting server")
-	err := server.ListenAndServe()
+	slog.Info("Starting server", "addr", server.Addr)
+	err = server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		panic(fmt.Sprintf("http server error: %s", err))
 	}
 
 	// Wait for the graceful shutdown to complete
 	<-done
-	log.Println("Graceful shutdown complete.")
+
+	// Spans are exported in batches, flush the last ones.
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("Failed to flush spans", "error", err)
+	}
+
+	slog.Info("Graceful shutdown complete")
 }
@@ -2,147 +2,120 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"flag"
 	"log"
 	"log/slog"
+	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/config"
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
-	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
-	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
+	"github.com/AlexKhomenko00/hotel-system/internal/jobs"
+	"github.com/AlexKhomenko00/hotel-system/internal/lock"
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
+	"github.com/AlexKhomenko00/hotel-system/internal/metrics"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
 	_ "github.com/joho/godotenv/autoload"
 )
 
+const (
+	lockRetry     = 5 * time.Second
+	lockHeartbeat = 10 * time.Second
+)
+
 func main() {
-	validator := validator.New()
-	cfg := config.GetConfig(validator)
+	var partition jobs.Partition
+	workers := flag.Int("workers", 4, "hotels populated concurrently")
+	flag.StringVar(&partition.TimeZone, "time-zone", "", "only hotels in this IANA time zone, all zones when empty")
+	flag.IntVar(&partition.Shard, "shard", 0, "shard of hotels to populate, from 0 to -shards minus one")
+	flag.IntVar(&partition.Shards, "shards", 1, "number of shards hotels are split into")
+	windowDays := flag.Int("window-days", jobs.DefaultWindowDays, "days ahead inventory is kept for")
+	wait := flag.Bool("wait", false, "wait for a run of the same partition to finish instead of exiting")
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Parse()
+
+	if *workers < 1 || partition.Shards < 1 || partition.Shard < 0 || partition.Shard >= partition.Shards || *windowDays < 1 {
+		log.Fatal("Invalid flags: -workers, -shards and -window-days must be positive and -shard below -shards")
+	}
+
+	validator := shared.NewValidator()
+	cfg, err := loader.Load(validator)
+	if err != nil {
+		log.Fatal(err)
+	}
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	db, err := database.Create(cfg)
+	logger, err := logging.New(os.Stderr, cfg.Log.Format, cfg.Log.Level)
 	if err != nil {
-		log.Fatalf("Failed to init db: %v", err)
+		log.Fatalf("Invalid configuration: %v", err)
 	}
-	queries := database.New(db.GetDB())
+	slog.SetDefault(logger)
 
-	hotelSvc := hotel.New(queries, validator)
-
-	/* More complex partition based job processing should be applied.
-	Probably hotels may be partitioned by country or timezones so CRON schedule should account for it.
-	For presentation purpose assume that we don't have thousands of hotels and they all in the same time zone so we can safely CRON once per day.
-	**/
-	hotels, err := hotelSvc.GetActiveHotels(ctx)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Exporter, "inventory-cron")
 	if err != nil {
-		log.Fatalf("Failed to retrieve active hotels: %v", err)
+		log.Fatalf("Failed to set up tracing: %v", err)
 	}
 
-	if len(hotels) == 0 {
-		log.Fatal("No active hotels found!")
+	db, err := database.Create(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init db: %v", err)
 	}
+	queries := database.New(db.GetDB())
 
-	slog.Info("Starting inventory population", "hotel_count", len(hotels))
-
-	var wg sync.WaitGroup
-
-	wg.Add(len(hotels))
-	for _, hotel := range hotels {
-		go func() {
-			defer wg.Done()
-			err := fetchAndPopulateHotelInventory(ctx, queries, hotel)
-			if err != nil {
-				slog.Error("Failed to update inventory for hotel!",
-					"hotel", hotel.ID,
-					"error", err)
-				return
-			}
-
-			slog.Info("Successfully updated hotel", "hotel_id", hotel.ID)
-
-		}()
+	jobSvc := jobs.New(queries, db)
+	locker := lock.New(db.GetDB())
+
+	// Overlapping runs of the same partition would race over the same checkpoints, other partitions may
+	// run side by side.
+	lockName := jobs.LockName(partition.Key())
+	var lease *lock.Lease
+	if *wait {
+		slog.Info("Waiting for lock", "lock", lockName)
+		lease, err = locker.WaitLease(ctx, lockName, lockRetry, lockHeartbeat)
+	} else {
+		lease, err = locker.TryLease(ctx, lockName, lockHeartbeat)
+	}
+	if errors.Is(err, lock.ErrLocked) {
+		slog.Info("Another run of the partition is in progress, exiting", "lock", lockName)
+		return
 	}
-
-	wg.Wait()
-}
-
-func fetchAndPopulateHotelInventory(ctx context.Context, queries *database.Queries, hotel database.BookingHotel) error {
-	today := time.Now().Truncate(24 * time.Hour)
-	windowEnd := today.AddDate(2, 0, 0) // 2 years ahead
-
-	capacityPlans, err := getHotelInventoryFromTo(ctx, queries, hotel.ID, today, windowEnd)
 	if err != nil {
-		return fmt.Errorf("failed to get capacity plans: %w", err)
+		log.Fatalf("Failed to take lock: %v", err)
 	}
 
-	if len(capacityPlans) == 0 {
-		return fmt.Errorf("capacity plans not found for hotel %s", hotel.ID)
+	// Losing the lease stops the run, it stays unfinished and is resumed by the next one.
+	runCtx, cancel := lease.Context(ctx)
+	start := time.Now()
+	run, err := jobSvc.RunInventory(runCtx, partition, *workers, *windowDays)
+	cancel()
+
+	if cfg.Jobs.PushgatewayURL != "" {
+		if pushErr := metrics.PushInventoryRun(context.Background(), cfg.Jobs.PushgatewayURL, metrics.InventoryRun{
+			Partition:       partition.Key(),
+			HotelsProcessed: run.HotelsProcessed,
+			RowsWritten:     run.RowsInserted,
+			Duration:        time.Since(start),
+		}); pushErr != nil {
+			slog.Error("Failed to push run metrics", "error", pushErr)
+		}
 	}
 
-	var wg sync.WaitGroup
-
-	wg.Add(len(capacityPlans))
-	for rTypeId, capacity := range capacityPlans {
-		go func() {
-			defer wg.Done()
-
-			var dates []time.Time
-			for d := capacity.fromDate; d.Before(capacity.toDate.AddDate(0, 0, 1)); d = d.AddDate(0, 0, 1) {
-				dates = append(dates, d)
-			}
-
-			_, err := queries.BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
-				Dates:          dates,
-				HotelID:        hotel.ID,
-				RoomTypeID:     rTypeId,
-				TotalInventory: capacity.totalInventory,
-			})
-
-			if err != nil {
-				slog.Error("failed to insert capacity for room",
-					"room_type_id", rTypeId,
-					"error", err.Error())
-				return
-			}
-
-			slog.Info("successfully updated room type inventory",
-				"hotel_id", hotel.ID,
-				"room_type_id", rTypeId,
-				"date_count", len(dates))
-
-		}()
+	if releaseErr := lease.Release(context.Background()); releaseErr != nil {
+		slog.Error("Failed to release lock", "lock", lockName, "error", releaseErr)
 	}
 
-	wg.Wait()
-
-	return nil
-}
-
-type RoomCapacityPerType map[uuid.UUID]PlannedRoomTypeCapacity
-type PlannedRoomTypeCapacity struct {
-	fromDate       time.Time
-	toDate         time.Time
-	totalInventory int32
-}
-
-// Mocked approach for inventory management. Usually calculated and provided by business
-func getHotelInventoryFromTo(ctx context.Context, queries *database.Queries, hotelID uuid.UUID, dateFrom, dateTo time.Time) (RoomCapacityPerType, error) {
-	roomTypes, err := queries.GetHotelUniqueRoomTypes(ctx, hotelID)
-	if err != nil {
-		return nil, err
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("Failed to flush spans", "error", err)
 	}
 
-	capacity := make(RoomCapacityPerType)
-	for _, rTypeID := range roomTypes {
-		capacity[rTypeID] = PlannedRoomTypeCapacity{
-			fromDate:       dateFrom,
-			toDate:         dateFrom.AddDate(2, 0, 0), // 2 years
-			totalInventory: 30,
-		}
+	// Runs are recorded in the database, GET /admin/jobs lists them.
+	if err != nil {
+		slog.Error("Inventory population failed", "error", err)
+		os.Exit(1)
 	}
-
-	return capacity, nil
 }
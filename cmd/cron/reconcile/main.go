@@ -0,0 +1,123 @@
+// Command reconcile recounts total_reserved of room type inventory from the reservations holding it.
+// It writes one JSON report per hotel to stdout and, with -repair, fixes the drifted dates.
+// The exit status is 1 when a hotel fails and 2 when discrepancies are left unrepaired.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
+	"github.com/AlexKhomenko00/hotel-system/internal/inventory"
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	hotelFlag := flag.String("hotel", "", "reconcile a single hotel, all active hotels when empty")
+	fromFlag := flag.String("from", "", "first date to reconcile (YYYY-MM-DD), today when empty")
+	repair := flag.Bool("repair", false, "overwrite drifted total_reserved with the recount")
+	loader := config.NewLoader(flag.CommandLine)
+	flag.Parse()
+
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	if *fromFlag != "" {
+		parsed, err := time.Parse(time.DateOnly, *fromFlag)
+		if err != nil {
+			log.Fatalf("Invalid -from date: %v", err)
+		}
+		from = parsed
+	}
+
+	validator := shared.NewValidator()
+	cfg, err := loader.Load(validator)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger, err := logging.New(os.Stderr, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.Create(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init db: %v", err)
+	}
+	queries := database.New(db.GetDB())
+
+	hotelSvc := hotel.New(queries, validator)
+	inventorySvc := inventory.New(queries, validator, db)
+
+	hotelIDs, err := hotelsToReconcile(ctx, hotelSvc, *hotelFlag)
+	if err != nil {
+		log.Fatalf("Failed to retrieve hotels: %v", err)
+	}
+
+	slog.Info("Starting inventory reconciliation", "hotel_count", len(hotelIDs), "from", from.Format(time.DateOnly), "repair", *repair)
+
+	out := json.NewEncoder(os.Stdout)
+	failed, unrepaired := false, false
+	for _, hotelID := range hotelIDs {
+		report, err := inventorySvc.Reconcile(ctx, hotelID, from, *repair)
+		if err != nil {
+			slog.Error("Failed to reconcile hotel inventory", "hotel_id", hotelID, "error", err)
+			failed = true
+			continue
+		}
+
+		if err := out.Encode(report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+
+		if report.Unrepaired > 0 {
+			unrepaired = true
+		}
+		slog.Info("Reconciled hotel inventory",
+			"hotel_id", hotelID,
+			"discrepancies", len(report.Discrepancies),
+			"unrepaired", report.Unrepaired)
+	}
+
+	switch {
+	case failed:
+		os.Exit(1)
+	case unrepaired:
+		os.Exit(2)
+	}
+}
+
+func hotelsToReconcile(ctx context.Context, hotelSvc *hotel.HotelService, hotelFlag string) ([]uuid.UUID, error) {
+	if hotelFlag != "" {
+		hotelID, err := uuid.Parse(hotelFlag)
+		if err != nil {
+			return nil, err
+		}
+		return []uuid.UUID{hotelID}, nil
+	}
+
+	hotels, err := hotelSvc.GetActiveHotels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hotelIDs := make([]uuid.UUID, 0, len(hotels))
+	for _, h := range hotels {
+		hotelIDs = append(hotelIDs, h.ID)
+	}
+	return hotelIDs, nil
+}
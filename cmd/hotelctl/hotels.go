@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
+	"github.com/google/uuid"
+)
+
+func createHotel(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("hotels create")
+	var body hotel.CreateHotelBody
+	fs.StringVar(&body.Name, "name", "", "hotel name, unique across hotels")
+	fs.StringVar(&body.Location, "location", "", "hotel location")
+	fs.StringVar(&body.TimeZone, "time-zone", "UTC", "IANA time zone of the hotel")
+	if err := a.parse(fs, args, "name", "location"); err != nil {
+		return err
+	}
+	if err := a.validator.Struct(body); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	var created database.BookingHotel
+	err := a.inTx(ctx, func(qtx *database.Queries) error {
+		_, err := qtx.GetHotelByName(ctx, body.Name)
+		if err == nil {
+			return hotel.ErrDuplicateHotelByName
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check hotel existence: %w", err)
+		}
+
+		created, err = qtx.CreateHotel(ctx, database.CreateHotelParams{
+			ID:       uuid.New(),
+			Name:     body.Name,
+			Location: body.Location,
+			TimeZone: body.TimeZone,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create hotel: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.print("hotel", created, fmt.Sprintf("Created hotel %s %q in %s (%s)",
+		created.ID, created.Name, created.Location, created.TimeZone))
+}
+
+func createRoomType(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("room-types create")
+	hotelFlag := fs.String("hotel", "", "ID of the hotel the room type belongs to")
+	name := fs.String("name", "", "room type name, unique within the hotel")
+	description := fs.String("description", "", "room type description")
+	if err := a.parse(fs, args, "hotel", "name"); err != nil {
+		return err
+	}
+	hotelID, err := parseUUID("hotel", *hotelFlag)
+	if err != nil {
+		return err
+	}
+
+	var created database.BookingRoomType
+	err = a.inTx(ctx, func(qtx *database.Queries) error {
+		if _, err := qtx.GetHotelById(ctx, hotelID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("hotel %s not found", hotelID)
+			}
+			return fmt.Errorf("failed to get hotel: %w", err)
+		}
+
+		_, err := qtx.FindRoomTypesByHotelIdAndName(ctx, database.FindRoomTypesByHotelIdAndNameParams{
+			HotelID: hotelID,
+			Name:    *name,
+		})
+		if err == nil {
+			return hotel.ErrDuplicateRoomByName
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check room type existence: %w", err)
+		}
+
+		created, err = qtx.CreateRoomType(ctx, database.CreateRoomTypeParams{
+			ID:      uuid.New(),
+			HotelID: hotelID,
+			Name:    *name,
+			Description: sql.NullString{
+				String: *description,
+				Valid:  *description != "",
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create room type: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.print("room_type", created, fmt.Sprintf("Created room type %s %q of hotel %s",
+		created.ID, created.Name, created.HotelID))
+}
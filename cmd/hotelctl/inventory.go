@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type SeededRoomType struct {
+	RoomTypeID uuid.UUID `json:"room_type_id"`
+	Name       string    `json:"name"`
+	// Dates that already had inventory are left as they are.
+	RowsInserted int64 `json:"rows_inserted"`
+}
+
+type SeedResult struct {
+	HotelID        uuid.UUID        `json:"hotel_id"`
+	From           shared.Date      `json:"from"`
+	To             shared.Date      `json:"to"`
+	TotalInventory int32            `json:"total_inventory"`
+	RoomTypes      []SeededRoomType `json:"room_types"`
+}
+
+func seedInventory(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("inventory seed")
+	hotelFlag := fs.String("hotel", "", "ID of the hotel to seed")
+	roomTypeFlag := fs.String("room-type", "", "seed a single room type, every room type of the hotel when empty")
+	fromFlag := fs.String("from", "", "first date to seed (YYYY-MM-DD)")
+	toFlag := fs.String("to", "", "last date to seed (YYYY-MM-DD), inclusive")
+	total := fs.Int("total", 0, "total inventory of each seeded date")
+	if err := a.parse(fs, args, "hotel", "from", "to", "total"); err != nil {
+		return err
+	}
+
+	hotelID, err := parseUUID("hotel", *hotelFlag)
+	if err != nil {
+		return err
+	}
+	from, err := parseDate("from", *fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseDate("to", *toFlag)
+	if err != nil {
+		return err
+	}
+	if to.Before(from) {
+		return fmt.Errorf("%w: -to must not be before -from", errUsage)
+	}
+	if *total < 0 {
+		return fmt.Errorf("%w: -total must not be negative", errUsage)
+	}
+
+	result := SeedResult{
+		HotelID:        hotelID,
+		From:           shared.Date(from),
+		To:             shared.Date(to),
+		TotalInventory: int32(*total),
+	}
+	dates := shared.DatesInRange(from, to, nil)
+
+	err = a.inTx(ctx, func(qtx *database.Queries) error {
+		roomTypes, err := roomTypesToSeed(ctx, qtx, hotelID, *roomTypeFlag)
+		if err != nil {
+			return err
+		}
+
+		for _, rt := range roomTypes {
+			inserted, err := qtx.BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+				HotelID:        hotelID,
+				RoomTypeID:     rt.ID,
+				Dates:          dates,
+				TotalInventory: result.TotalInventory,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to seed inventory of room type %q: %w", rt.ID, err)
+			}
+			result.RoomTypes = append(result.RoomTypes, SeededRoomType{
+				RoomTypeID:   rt.ID,
+				Name:         rt.Name,
+				RowsInserted: inserted,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Seeded inventory of hotel %s from %s to %s with %d rooms per date\n",
+		hotelID, from.Format(time.DateOnly), to.Format(time.DateOnly), *total)
+	for _, rt := range result.RoomTypes {
+		fmt.Fprintf(&text, "  %s %q: %d of %d dates inserted\n", rt.RoomTypeID, rt.Name, rt.RowsInserted, len(dates))
+	}
+	return a.print("inventory", result, text.String())
+}
+
+func roomTypesToSeed(ctx context.Context, qtx *database.Queries, hotelID uuid.UUID, roomTypeFlag string) ([]database.BookingRoomType, error) {
+	if roomTypeFlag == "" {
+		roomTypes, err := qtx.GetHotelRoomTypes(ctx, hotelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get room types of hotel %s: %w", hotelID, err)
+		}
+		if len(roomTypes) == 0 {
+			return nil, fmt.Errorf("hotel %s has no room types", hotelID)
+		}
+		return roomTypes, nil
+	}
+
+	roomTypeID, err := parseUUID("room-type", roomTypeFlag)
+	if err != nil {
+		return nil, err
+	}
+	roomType, err := qtx.GetRoomTypeByIdAndHotelId(ctx, database.GetRoomTypeByIdAndHotelIdParams{
+		ID:      roomTypeID,
+		HotelID: hotelID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("room type %s not found in hotel %s", roomTypeID, hotelID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room type %s: %w", roomTypeID, err)
+	}
+	return []database.BookingRoomType{roomType}, nil
+}
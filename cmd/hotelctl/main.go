@@ -0,0 +1,220 @@
+// Command hotelctl operates the hotel system from the command line: it creates hotels and room types,
+// seeds inventory, inspects and repairs reservations, grants roles, replays failed outbox events and runs
+// migrations.
+//
+// Usage:
+//
+//	hotelctl <group> <command> [flags]
+//
+// Every command accepts -dry-run, which runs it in a transaction that is rolled back, and -json, which
+// writes the result as a single JSON object to stdout. The exit status is 1 when a command fails and 2
+// on invalid usage.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"hotels create":        {"create a hotel", createHotel},
+	"room-types create":    {"create a room type of a hotel", createRoomType},
+	"inventory seed":       {"seed room type inventory of a hotel for a date range", seedInventory},
+	"reservations inspect": {"show a reservation and the inventory of its dates", inspectReservation},
+	"reservations repair":  {"recount reserved inventory of a hotel and repair drift", repairReservations},
+	"roles grant":          {"grant a role to a user", grantRole},
+	"outbox replay":        {"retry outbox events that failed", replayOutbox},
+	"migrate status":       {"list applied and pending migrations", migrationStatus},
+	"migrate up":           {"apply pending migrations", migrateUp},
+	"migrate down":         {"roll back the most recently applied migration", migrateDown},
+}
+
+type app struct {
+	db        database.Service
+	queries   *database.Queries
+	validator *validator.Validate
+	out       io.Writer
+
+	jsonOutput bool
+	dryRun     bool
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	name := os.Args[1] + " " + os.Args[2]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	validator := shared.NewValidator()
+	// Subcommands own the flags, the configuration comes from CONFIG_FILE, the environment and SECRETS_DIR.
+	cfg, err := config.NewLoader(nil).Load(validator)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.Create(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init db: %v", err)
+	}
+	defer db.Close()
+
+	a := &app{
+		db:        db,
+		queries:   database.New(db.GetDB()),
+		validator: validator,
+		out:       os.Stdout,
+	}
+
+	err = cmd.run(ctx, a, os.Args[3:])
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.Is(err, errUsage):
+		// Bare usage errors were already reported along with the command's flags.
+		if err != errUsage {
+			a.fail(err)
+		}
+		os.Exit(2)
+	case err != nil:
+		a.fail(err)
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: hotelctl <group> <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-22s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Every command accepts -dry-run and -json, run a command with -h for its flags.")
+}
+
+// flags returns a flag set for the named command with -dry-run and -json already registered.
+func (a *app) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("hotelctl "+name, flag.ContinueOnError)
+	fs.BoolVar(&a.dryRun, "dry-run", false, "run the command without committing any change")
+	fs.BoolVar(&a.jsonOutput, "json", false, "write the result as JSON to stdout")
+	return fs
+}
+
+// parse parses args into fs and reports a usage error for missing required flags.
+func (a *app) parse(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range required {
+		if !set[name] {
+			fmt.Fprintf(fs.Output(), "Missing required flag -%s\n", name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction that is committed unless the command is a dry run.
+func (a *app) inTx(ctx context.Context, fn func(qtx *database.Queries) error) error {
+	tx, err := a.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(a.queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	if a.dryRun {
+		return nil
+	}
+	return tx.Commit()
+}
+
+// print writes the result of a command, as {"dry_run": ..., key: result} with -json and as text otherwise.
+func (a *app) print(key string, result any, text string) error {
+	if a.jsonOutput {
+		return json.NewEncoder(a.out).Encode(shared.Envelope{"dry_run": a.dryRun, key: result})
+	}
+
+	fmt.Fprintln(a.out, strings.TrimRight(text, "\n"))
+	if a.dryRun {
+		fmt.Fprintln(a.out, "Dry run, nothing was changed.")
+	}
+	return nil
+}
+
+// fail reports a failed command, as {"error": ...} on stdout with -json so scripts read a single stream.
+func (a *app) fail(err error) {
+	if a.jsonOutput {
+		json.NewEncoder(a.out).Encode(shared.Envelope{"error": err.Error()})
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+}
+
+func parseUUID(name, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: -%s must be a UUID: %v", errUsage, name, err)
+	}
+	return id, nil
+}
+
+func parseDate(name, value string) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: -%s must be a YYYY-MM-DD date: %v", errUsage, name, err)
+	}
+	return date, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/migrate"
+)
+
+func migrationStatus(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("migrate status")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	migrator, err := migrate.New(a.db.GetDB())
+	if err != nil {
+		return err
+	}
+
+	migrations, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var text strings.Builder
+	for _, m := range migrations {
+		applied := "pending"
+		if m.AppliedAt != nil {
+			applied = m.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(&text, "%-25s %s\n", applied, m.Name)
+	}
+	return a.print("migrations", migrations, text.String())
+}
+
+// migrateUp applies pending migrations. Goose runs each migration in its own transaction, so a dry run
+// lists the pending ones rather than applying and rolling them back.
+func migrateUp(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("migrate up")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	migrator, err := migrate.New(a.db.GetDB())
+	if err != nil {
+		return err
+	}
+
+	var migrations []migrate.Migration
+	if a.dryRun {
+		migrations, err = migrator.Pending(ctx)
+	} else {
+		migrations, err = migrator.Up(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	var text strings.Builder
+	verb := "Applied"
+	if a.dryRun {
+		verb = "Would apply"
+	}
+	fmt.Fprintf(&text, "%s %d migrations\n", verb, len(migrations))
+	for _, m := range migrations {
+		fmt.Fprintf(&text, "  %s\n", m.Name)
+	}
+	return a.print("migrations", migrations, text.String())
+}
+
+// migrateDown rolls back the most recently applied migration, a dry run names it without rolling back.
+func migrateDown(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("migrate down")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	migrator, err := migrate.New(a.db.GetDB())
+	if err != nil {
+		return err
+	}
+
+	var migration migrate.Migration
+	if a.dryRun {
+		migration, err = migrator.Last(ctx)
+	} else {
+		migration, err = migrator.Down(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	verb := "Rolled back"
+	if a.dryRun {
+		verb = "Would roll back"
+	}
+	return a.print("migration", migration, fmt.Sprintf("%s %s", verb, migration.Name))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/google/uuid"
+)
+
+type ReplayResult struct {
+	Replayed int64 `json:"replayed"`
+}
+
+// replayOutbox gives unpublished events that failed a fresh set of attempts, after an outage outlasted the
+// relay's retries. The relay claims them on its next poll.
+func replayOutbox(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("outbox replay")
+	eventFlag := fs.String("event", "", "ID of the event to replay, every failed event when empty")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	var eventID uuid.NullUUID
+	if *eventFlag != "" {
+		id, err := parseUUID("event", *eventFlag)
+		if err != nil {
+			return err
+		}
+		eventID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	var result ReplayResult
+	err := a.inTx(ctx, func(qtx *database.Queries) error {
+		replayed, err := qtx.ReplayFailedOutboxEvents(ctx, eventID)
+		if err != nil {
+			return fmt.Errorf("failed to replay outbox events: %w", err)
+		}
+		result.Replayed = replayed
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.print("replay", result, fmt.Sprintf("Replaying %d outbox events", result.Replayed))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/inventory"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+)
+
+type NightInventory struct {
+	Date           shared.Date `json:"date"`
+	TotalInventory int32       `json:"total_inventory"`
+	TotalReserved  int32       `json:"total_reserved"`
+	// Missing is set when the night has no inventory row, the reservation could not have taken a room for it.
+	Missing bool `json:"missing"`
+}
+
+type ReservationReport struct {
+	Reservation database.BookingReservation `json:"reservation"`
+	// HoldsInventory tells whether the reservation's status counts towards total_reserved of its nights.
+	HoldsInventory bool             `json:"holds_inventory"`
+	Nights         []NightInventory `json:"nights"`
+}
+
+func inspectReservation(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("reservations inspect")
+	idFlag := fs.String("id", "", "ID of the reservation")
+	if err := a.parse(fs, args, "id"); err != nil {
+		return err
+	}
+	id, err := parseUUID("id", *idFlag)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.queries.GetReservationById(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("reservation %s not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	dates := shared.DatesInRange(res.StartDate, res.EndDate, nil)
+	inv, err := a.queries.GetRoomTypeInventoryForDates(ctx, database.GetRoomTypeInventoryForDatesParams{
+		HotelID:    res.HotelID,
+		RoomTypeID: res.RoomTypeID,
+		Dates:      dates,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get inventory of reservation %s: %w", id, err)
+	}
+	byDate := make(map[string]database.BookingRoomTypeInventory, len(inv))
+	for _, row := range inv {
+		byDate[row.Date.Format(time.DateOnly)] = row
+	}
+
+	report := ReservationReport{
+		Reservation:    res,
+		HoldsInventory: slices.Contains(reservation.HoldingStatuses, reservation.ReservationState(res.Status)),
+		Nights:         make([]NightInventory, 0, len(dates)),
+	}
+	for _, date := range dates {
+		row, ok := byDate[date.Format(time.DateOnly)]
+		report.Nights = append(report.Nights, NightInventory{
+			Date:           shared.Date(date),
+			TotalInventory: row.TotalInventory,
+			TotalReserved:  row.TotalReserved,
+			Missing:        !ok,
+		})
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Reservation %s: %s, hotel %s, room type %s, guest %s\n",
+		res.ID, res.Status, res.HotelID, res.RoomTypeID, res.GuestID)
+	fmt.Fprintf(&text, "Stay %s to %s, holds inventory: %t\n",
+		res.StartDate.Format(time.DateOnly), res.EndDate.Format(time.DateOnly), report.HoldsInventory)
+	for _, night := range report.Nights {
+		if night.Missing {
+			fmt.Fprintf(&text, "  %s  no inventory\n", time.Time(night.Date).Format(time.DateOnly))
+			continue
+		}
+		fmt.Fprintf(&text, "  %s  %d/%d reserved\n", time.Time(night.Date).Format(time.DateOnly), night.TotalReserved, night.TotalInventory)
+	}
+	return a.print("reservation", report, text.String())
+}
+
+// repairReservations recounts total_reserved of a hotel from the reservations holding inventory. A dry run
+// only reports the drift.
+func repairReservations(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("reservations repair")
+	hotelFlag := fs.String("hotel", "", "ID of the hotel to repair")
+	fromFlag := fs.String("from", "", "first date to repair (YYYY-MM-DD), today when empty")
+	if err := a.parse(fs, args, "hotel"); err != nil {
+		return err
+	}
+	hotelID, err := parseUUID("hotel", *hotelFlag)
+	if err != nil {
+		return err
+	}
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	if *fromFlag != "" {
+		if from, err = parseDate("from", *fromFlag); err != nil {
+			return err
+		}
+	}
+
+	inventorySvc := inventory.New(a.queries, a.validator, a.db)
+	report, err := inventorySvc.Reconcile(ctx, hotelID, from, !a.dryRun)
+	if err != nil {
+		return err
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Hotel %s from %s: %d discrepancies, %d unrepaired\n",
+		hotelID, from.Format(time.DateOnly), len(report.Discrepancies), report.Unrepaired)
+	for _, d := range report.Discrepancies {
+		fmt.Fprintf(&text, "  %s  room type %s: reserved %d, counted %d, repaired: %t\n",
+			time.Time(d.Date).Format(time.DateOnly), d.RoomTypeID, d.TotalReserved, d.ActualReserved, d.Repaired)
+	}
+	if err := a.print("report", report, text.String()); err != nil {
+		return err
+	}
+
+	if !a.dryRun && report.Unrepaired > 0 {
+		return fmt.Errorf("%d discrepancies left unrepaired, bookings kept changing them", report.Unrepaired)
+	}
+	return nil
+}
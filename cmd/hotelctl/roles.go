@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/google/uuid"
+)
+
+type GrantResult struct {
+	UserID  uuid.UUID     `json:"user_id"`
+	Email   string        `json:"email"`
+	Role    auth.Role     `json:"role"`
+	HotelID uuid.NullUUID `json:"hotel_id"`
+	// AlreadyGranted is set when the user held the role before, nothing is changed then.
+	AlreadyGranted bool `json:"already_granted"`
+}
+
+func grantRole(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("roles grant")
+	userFlag := fs.String("user", "", "email or ID of the user")
+	roleFlag := fs.String("role", "", fmt.Sprintf("role to grant, %s or %s", auth.RoleAdmin, auth.RoleHotelAdmin))
+	hotelFlag := fs.String("hotel", "", fmt.Sprintf("ID of the hotel a %s role is scoped to", auth.RoleHotelAdmin))
+	if err := a.parse(fs, args, "user", "role"); err != nil {
+		return err
+	}
+
+	role := auth.Role(*roleFlag)
+	var hotelID uuid.NullUUID
+	switch role {
+	case auth.RoleAdmin:
+		if *hotelFlag != "" {
+			return fmt.Errorf("%w: -hotel is not allowed for the %s role", errUsage, auth.RoleAdmin)
+		}
+	case auth.RoleHotelAdmin:
+		id, err := parseUUID("hotel", *hotelFlag)
+		if err != nil {
+			return err
+		}
+		hotelID = uuid.NullUUID{UUID: id, Valid: true}
+	default:
+		return fmt.Errorf("%w: unknown role %q", errUsage, *roleFlag)
+	}
+
+	var result GrantResult
+	err := a.inTx(ctx, func(qtx *database.Queries) error {
+		user, err := findUser(ctx, qtx, *userFlag)
+		if err != nil {
+			return err
+		}
+		if hotelID.Valid {
+			if _, err := qtx.GetHotelById(ctx, hotelID.UUID); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("hotel %s not found", hotelID.UUID)
+				}
+				return fmt.Errorf("failed to get hotel: %w", err)
+			}
+		}
+
+		roles, err := qtx.GetUserRoles(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get roles of user %s: %w", user.ID, err)
+		}
+		result = GrantResult{UserID: user.ID, Email: user.Email, Role: role, HotelID: hotelID}
+		for _, r := range roles {
+			if auth.Role(r.Role) == role && r.HotelID == hotelID {
+				result.AlreadyGranted = true
+				return nil
+			}
+		}
+
+		if err := qtx.GrantUserRole(ctx, database.GrantUserRoleParams{
+			UserID:  user.ID,
+			Role:    string(role),
+			HotelID: hotelID,
+		}); err != nil {
+			return fmt.Errorf("failed to grant role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	scope := "every hotel"
+	if hotelID.Valid {
+		scope = "hotel " + hotelID.UUID.String()
+	}
+	text := fmt.Sprintf("Granted %s of %s to %s", role, scope, result.Email)
+	if result.AlreadyGranted {
+		text = fmt.Sprintf("%s already holds %s of %s", result.Email, role, scope)
+	}
+	return a.print("grant", result, text)
+}
+
+// findUser looks a user up by ID when the value is a UUID and by email otherwise.
+func findUser(ctx context.Context, qtx *database.Queries, value string) (database.AuthUser, error) {
+	var (
+		user database.AuthUser
+		err  error
+	)
+	if id, parseErr := uuid.Parse(value); parseErr == nil {
+		user, err = qtx.GetUserById(ctx, id)
+	} else {
+		user, err = qtx.GetUserByEmail(ctx, value)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, fmt.Errorf("user %q not found", value)
+	}
+	if err != nil {
+		return user, fmt.Errorf("failed to get user %q: %w", value, err)
+	}
+	return user, nil
+}
@@ -0,0 +1,23 @@
+// Command mockchannel runs an in-memory OTA XML channel for local development. Point a channel's
+// endpoint URL at it to watch ARI pushes and reservation imports without a real OTA.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/channel/mockchannel"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	hotelCode := flag.String("hotel-code", "HOTEL1", "hotel code the channel expects")
+	apiKey := flag.String("api-key", "local-channel-api-key", "API key the channel expects")
+	flag.Parse()
+
+	log.Printf("mock channel listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, mockchannel.New(*hotelCode, *apiKey)); err != nil {
+		log.Fatalf("mock channel stopped: %v", err)
+	}
+}
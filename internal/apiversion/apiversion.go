@@ -0,0 +1,75 @@
+// Package apiversion lets versions of the API be served side by side under their own prefix, and marks the
+// ones being retired with Deprecation and Sunset headers (RFC 9745, RFC 8594).
+package apiversion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+)
+
+const CodeVersionSunset = "api.version_sunset"
+
+type ctxKey struct{}
+
+// Tag records the version serving the request, handlers shared between versions branch on FromContext.
+func Tag(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), ctxKey{}, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// FromContext returns the version tagged on the request, empty outside versioned routes.
+func FromContext(ctx context.Context) string {
+	version, _ := ctx.Value(ctxKey{}).(string)
+	return version
+}
+
+// Prefix returns the path prefix of the version tagged on the request, for building links to other routes of
+// the same version.
+func Prefix(ctx context.Context) string {
+	if version := FromContext(ctx); version != "" {
+		return "/" + version
+	}
+	return ""
+}
+
+// Deprecation describes routes clients should move away from.
+type Deprecation struct {
+	// Since is when the routes were deprecated.
+	Since time.Time
+	// Sunset is when the routes stop being served, zero while not scheduled.
+	Sunset time.Time
+	// Successor is the prefix serving the same resources in the replacing version, such as /v1.
+	Successor string
+}
+
+// Handler adds the deprecation headers to every response and answers with 410 once the sunset has passed.
+func (d Deprecation) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", d.Since.Unix()))
+		if d.Successor != "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, d.Successor, r.URL.Path))
+		}
+		if !d.Sunset.IsZero() {
+			w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+
+			if !time.Now().Before(d.Sunset) {
+				shared.WriteProblem(w, shared.Problem{
+					Status: http.StatusGone,
+					Code:   CodeVersionSunset,
+					Detail: fmt.Sprintf("This route was retired on %s, use %s%s", d.Sunset.Format(time.DateOnly), d.Successor, r.URL.Path),
+				})
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,12 +1,28 @@
 package auth
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/AlexKhomenko00/hotel-system/internal/auth/jwt"
 	"github.com/AlexKhomenko00/hotel-system/internal/config"
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
 	"github.com/go-playground/validator/v10"
 )
 
+var (
+	ErrInvalidCredentials = errors.New("authService: Invalid credentials")
+	ErrInvalidToken       = errors.New("authService: Invalid token")
+)
+
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrInvalidCredentials: {Status: http.StatusUnauthorized, Code: "auth.invalid_credentials", Message: "Invalid credentials"},
+		ErrInvalidToken:       {Status: http.StatusUnauthorized, Code: "auth.invalid_token"},
+	})
+}
+
 type AuthService struct {
 	queries   *database.Queries
 	validator *validator.Validate
@@ -24,6 +40,8 @@ type RegisterBody struct {
 	Password  string `json:"password" validate:"required,min=3,max=30"`
 	FirstName string `json:"first_name" validate:"required,min=3,max=100"`
 	LastName  string `json:"last_name" validate:"required,min=3,max=100"`
+	// Language of guest emails, defaults to English.
+	Locale string `json:"locale" validate:"omitempty,oneof=en uk"`
 }
 
 type UserResponse struct {
@@ -37,7 +55,7 @@ type LoginResponse struct {
 }
 
 func New(queries *database.Queries, validator *validator.Validate, cfg *config.Config) *AuthService {
-	jwtAuth := jwt.NewAuthenticator(cfg.JWTSecret)
+	jwtAuth := jwt.NewAuthenticator(cfg.Auth.JWTSecret)
 
 	return &AuthService{
 		queries:   queries,
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
 	"github.com/AlexKhomenko00/hotel-system/internal/shared"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
@@ -33,43 +34,37 @@ func (s *AuthService) RegisterHandlers(r *chi.Mux) {
 func (s *AuthService) loginHandler(w http.ResponseWriter, r *http.Request) {
 	var body AuthBody
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
 		return
 	}
 
 	if err := s.validator.Struct(body); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		shared.WriteValidationError(w, err)
 		return
 	}
 
-	resEncoder := json.NewEncoder(w)
-
 	usr, err := s.queries.GetUserByEmail(r.Context(), body.Email)
 	if errors.Is(err, sql.ErrNoRows) {
-		w.WriteHeader(http.StatusUnauthorized)
-		resEncoder.Encode(shared.ErrorRes{Message: "Invalid credentials"})
+		shared.WriteDomainError(w, ErrInvalidCredentials)
 		return
 	}
 
 	if err != nil {
-		slog.Error("database error during login", "error", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "database error during login", "error", err)
+		shared.WriteError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(usr.PasswordHash), []byte(body.Password))
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		resEncoder.Encode(shared.ErrorRes{
-			Message: "Invalid credentials",
-		})
+		shared.WriteDomainError(w, ErrInvalidCredentials)
 		return
 	}
 
 	_, tokenString, err := s.jwt.EncodeUserClaims(usr)
 	if err != nil {
-		slog.Error("Failed to generate JWT token", "error", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Failed to generate JWT token", "error", err)
+		shared.WriteError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
@@ -86,25 +81,20 @@ func (s *AuthService) registerHandler(w http.ResponseWriter, r *http.Request) {
 	var body RegisterBody
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
 		return
 	}
 
-	resEncoder := json.NewEncoder(w)
-
 	if err := s.validator.Struct(&body); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		resEncoder.Encode(shared.ErrorRes{
-			Message: fmt.Sprintf("Invalid body: %v", err.Error()),
-		})
+		shared.WriteValidationError(w, err)
 		return
 	}
 
 	hashedPasswordBytes, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
 
 	if err != nil {
-		slog.Error("unable to hash password", "error", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "unable to hash password", "error", err)
+		shared.WriteError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
@@ -112,31 +102,31 @@ func (s *AuthService) registerHandler(w http.ResponseWriter, r *http.Request) {
 	if err == nil {
 		// User exists - add timing delay to match bcrypt operation
 		time.Sleep(100 * time.Millisecond)
-		w.WriteHeader(http.StatusBadRequest)
-		err := resEncoder.Encode(shared.ErrorRes{
-			Message: "Invalid credentials",
-		})
-		if err != nil {
-			slog.Error("failed to encode response", "error", err)
-		}
+		shared.WriteError(w, http.StatusBadRequest, "Invalid credentials")
 		return
 	}
 
 	if !errors.Is(err, sql.ErrNoRows) {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		shared.WriteError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
+	locale := body.Locale
+	if locale == "" {
+		locale = "en"
+	}
+
 	guest, err := s.queries.InsertGuest(r.Context(), database.InsertGuestParams{
 		ID:        uuid.New(),
 		FirstName: body.FirstName,
 		LastName:  body.LastName,
 		Email:     body.Email,
+		Locale:    locale,
 	})
 
 	if err != nil {
-		slog.Error("Unable to insert guest into db", "error", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Unable to insert guest into db", "error", err)
+		shared.WriteError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
@@ -147,8 +137,8 @@ func (s *AuthService) registerHandler(w http.ResponseWriter, r *http.Request) {
 		GuestID:      guest.ID,
 	})
 	if err != nil {
-		slog.Error("unable to insert user into db", "error", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "unable to insert user into db", "error", err)
+		shared.WriteError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
@@ -157,7 +147,7 @@ func (s *AuthService) registerHandler(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		slog.Error("failed to send user register response", "error", err)
+		slog.ErrorContext(r.Context(), "failed to send user register response", "error", err)
 	}
 }
 
@@ -186,41 +176,44 @@ func (s *AuthService) jwtAuthMiddleware(next http.Handler) http.Handler {
 		token, claims, err := jwtauth.FromContext(r.Context())
 
 		if err != nil {
-			slog.Error(err.Error())
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+			// The reason tells expired tokens from forged ones, the token itself stays out of the logs.
+			slog.DebugContext(r.Context(), "Rejected invalid token", "reason", err)
+			shared.WriteDomainError(w, fmt.Errorf("%w: %v", ErrInvalidToken, err))
 			return
 		}
 
 		if token == nil {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			shared.WriteDomainError(w, ErrInvalidToken)
 			return
 		}
 
 		usrID, ok := claims["UserId"].(string)
 		if !ok {
-			http.Error(w, "invalid token claims", http.StatusUnauthorized)
+			shared.WriteDomainError(w, ErrInvalidToken)
 			return
 		}
 
 		_, ok = claims["Email"].(string)
 		if !ok {
-			http.Error(w, "invalid token claims", http.StatusUnauthorized)
+			shared.WriteDomainError(w, ErrInvalidToken)
 			return
 		}
 
 		uid, err := uuid.Parse(usrID)
 		if err != nil {
-			http.Error(w, "invalid user ID in token", http.StatusUnauthorized)
+			shared.WriteDomainError(w, ErrInvalidToken)
 			return
 		}
 
 		usr, err := s.queries.GetUserById(r.Context(), uid)
 		if err != nil {
-			slog.Error("Can't retrieve user from token", "error", err, "user_id", usrID)
-			http.Error(w, "invalid token claims", http.StatusUnauthorized)
+			slog.ErrorContext(r.Context(), "Can't retrieve user from token", "error", err, "user_id", usrID)
+			shared.WriteDomainError(w, ErrInvalidToken)
 			return
 		}
 
+		logging.Add(r.Context(), "user_id", usr.ID)
+
 		usrCtx := context.WithValue(r.Context(), UsrCtxKey, UserContext{
 			Id:      usr.ID,
 			Email:   usr.Email,
@@ -242,18 +235,14 @@ func verifyTokenHandler(w http.ResponseWriter, r *http.Request) {
 	type TokenVerificationResponse struct {
 		Message string `json:"message"`
 	}
-	e := json.NewEncoder(w)
 	usr, ok := r.Context().Value(UsrCtxKey).(UserContext)
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		e.Encode(shared.ErrorRes{
-			Message: "Invalid token user claims",
-		})
+		shared.WriteError(w, http.StatusBadRequest, "Invalid token user claims")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	e.Encode(TokenVerificationResponse{
+	json.NewEncoder(w).Encode(TokenVerificationResponse{
 		Message: fmt.Sprintf("Hi! %v", usr),
 	})
 }
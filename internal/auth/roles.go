@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	// RoleAdmin manages every hotel and system wide settings.
+	RoleAdmin Role = "admin"
+	// RoleHotelAdmin manages a single hotel, the one referenced by the role's hotel_id.
+	RoleHotelAdmin Role = "hotel_admin"
+)
+
+// RequireAdmin only lets users holding the global admin role through.
+// It must be mounted after SetupJWTAuthMiddleware so the user context is populated.
+func (s *AuthService) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usr, ok := r.Context().Value(UsrCtxKey).(UserContext)
+		if !ok {
+			shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+			return
+		}
+
+		allowed, err := s.hasRole(r.Context(), usr.Id, uuid.Nil)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to load user roles", "error", err, "user_id", usr.Id)
+			shared.WriteError(w, http.StatusInternalServerError, "Sorry, something went wrong")
+			return
+		}
+
+		if !allowed {
+			shared.WriteError(w, http.StatusForbidden, "Admin role required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireHotelAdmin lets through global admins and hotel admins of the hotel
+// whose id is taken from the hotelIDParam URL parameter.
+func (s *AuthService) RequireHotelAdmin(hotelIDParam string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			usr, ok := r.Context().Value(UsrCtxKey).(UserContext)
+			if !ok {
+				shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+				return
+			}
+
+			hotelID, err := uuid.Parse(chi.URLParam(r, hotelIDParam))
+			if err != nil {
+				shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+				return
+			}
+
+			allowed, err := s.hasRole(r.Context(), usr.Id, hotelID)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "failed to load user roles", "error", err, "user_id", usr.Id)
+				shared.WriteError(w, http.StatusInternalServerError, "Sorry, something went wrong")
+				return
+			}
+
+			if !allowed {
+				shared.WriteError(w, http.StatusForbidden, "Hotel admin role required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasRole reports whether the user is a global admin, or a hotel admin of hotelID.
+// Passing uuid.Nil as hotelID only accepts global admins.
+func (s *AuthService) hasRole(ctx context.Context, userID, hotelID uuid.UUID) (bool, error) {
+	roles, err := s.queries.GetUserRoles(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		if isGlobalAdmin(role) {
+			return true, nil
+		}
+		if hotelID != uuid.Nil && Role(role.Role) == RoleHotelAdmin && role.HotelID.Valid && role.HotelID.UUID == hotelID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isGlobalAdmin(role database.AuthUserRole) bool {
+	return Role(role.Role) == RoleAdmin && !role.HotelID.Valid
+}
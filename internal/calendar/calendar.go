@@ -0,0 +1,39 @@
+package calendar
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrFeedNotFound     = errors.New("calendarService: Feed not found")
+	ErrHotelNotFound    = errors.New("calendarService: Hotel not found")
+	ErrRoomTypeNotFound = errors.New("calendarService: Room type not found")
+)
+
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrFeedNotFound:     {Status: http.StatusNotFound, Code: "calendar.feed_not_found", Message: "Calendar feed not found"},
+		ErrHotelNotFound:    {Status: http.StatusNotFound, Code: "hotel.not_found", Message: "Hotel not found"},
+		ErrRoomTypeNotFound: {Status: http.StatusNotFound, Code: "room_type.not_found", Message: "Room type not found"},
+	})
+}
+
+// Past stays are kept in feeds for this many days, so recent cancellations still reach subscribed calendars.
+const feedLookbackDays = 30
+
+type CalendarService struct {
+	queries   *database.Queries
+	validator *validator.Validate
+}
+
+func New(queries *database.Queries, validator *validator.Validate) *CalendarService {
+	return &CalendarService{
+		queries:   queries,
+		validator: validator,
+	}
+}
@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RegisterPublicHandlers mounts the token feeds. Calendar apps poll them without credentials.
+func (s *CalendarService) RegisterPublicHandlers(r chi.Router) {
+	r.Get("/calendar/feeds/{token}.ics", s.HotelFeedHandler)
+	r.Get("/calendar/guest-feeds/{token}.ics", s.GuestTokenFeedHandler)
+}
+
+// RegisterGuestHandlers mounts the feed of the authenticated guest and the management of its token URL.
+func (s *CalendarService) RegisterGuestHandlers(r chi.Router) {
+	r.Get("/calendar/reservations.ics", s.GuestFeedHandler)
+	r.Post("/calendar/guest-feed", s.IssueGuestFeedHandler)
+	r.Delete("/calendar/guest-feed", s.RevokeGuestFeedHandler)
+}
+
+// RegisterHotelHandlers mounts feed management. The router is expected to carry a {hotelId} URL parameter.
+func (s *CalendarService) RegisterHotelHandlers(r chi.Router) {
+	r.Get("/", s.ListFeedsHandler)
+	r.Post("/", s.CreateFeedHandler)
+	r.Delete("/{feedId}", s.DeleteFeedHandler)
+}
+
+func (s *CalendarService) GuestFeedHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	feed, err := s.guestFeed(r.Context(), usr.GuestId)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	writeCalendar(w, "reservations.ics", feed)
+}
+
+func (s *CalendarService) GuestTokenFeedHandler(w http.ResponseWriter, r *http.Request) {
+	feed, err := s.guestTokenFeed(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	writeCalendar(w, "reservations.ics", feed)
+}
+
+func (s *CalendarService) IssueGuestFeedHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	feed, err := s.issueGuestFeed(r.Context(), usr.GuestId)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"feed": feed})
+}
+
+func (s *CalendarService) RevokeGuestFeedHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	if err := s.revokeGuestFeed(r.Context(), usr.GuestId); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *CalendarService) HotelFeedHandler(w http.ResponseWriter, r *http.Request) {
+	feed, err := s.hotelFeed(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	writeCalendar(w, "hotel.ics", feed)
+}
+
+func (s *CalendarService) ListFeedsHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	feeds, err := s.listFeeds(r.Context(), hotelID)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"feeds": feeds})
+}
+
+func (s *CalendarService) CreateFeedHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	var body CreateFeedBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	feed, err := s.createFeed(r.Context(), hotelID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"feed": feed})
+}
+
+func (s *CalendarService) DeleteFeedHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+
+	if err := s.deleteFeed(r.Context(), hotelID, feedID); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeCalendar(w http.ResponseWriter, filename, body string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(body)); err != nil {
+		slog.Warn("Failed to write calendar feed", "error", err)
+	}
+}
@@ -0,0 +1,298 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/apiversion"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/ical"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type CreateFeedBody struct {
+	// Omit to include every room type of the hotel.
+	RoomTypeID *uuid.UUID `json:"roomTypeId"`
+}
+
+type Feed struct {
+	ID         uuid.UUID  `json:"id"`
+	HotelID    uuid.UUID  `json:"hotel_id"`
+	RoomTypeID *uuid.UUID `json:"room_type_id"`
+	// Path of the feed, to be prefixed with the public API origin.
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newFeed links the feed under the API version of the request, so clients of a version get URLs it serves.
+func newFeed(ctx context.Context, f database.BookingCalendarFeed) Feed {
+	feed := Feed{
+		ID:        f.ID,
+		HotelID:   f.HotelID,
+		URL:       fmt.Sprintf("%s/calendar/feeds/%s.ics", apiversion.Prefix(ctx), f.Token),
+		CreatedAt: f.CreatedAt,
+	}
+	if f.RoomTypeID.Valid {
+		feed.RoomTypeID = &f.RoomTypeID.UUID
+	}
+	return feed
+}
+
+// GuestFeed is the token URL of a guest's reservations, for calendar apps that can't authenticate.
+type GuestFeed struct {
+	// Path of the feed, to be prefixed with the public API origin.
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newGuestFeed(ctx context.Context, f database.BookingGuestCalendarFeed) GuestFeed {
+	return GuestFeed{
+		URL:       fmt.Sprintf("%s/calendar/guest-feeds/%s.ics", apiversion.Prefix(ctx), f.Token),
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func (s *CalendarService) listFeeds(ctx context.Context, hotelID uuid.UUID) ([]Feed, error) {
+	rows, err := s.queries.ListHotelCalendarFeeds(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar feeds for hotel %q: %w", hotelID, err)
+	}
+
+	feeds := make([]Feed, 0, len(rows))
+	for _, row := range rows {
+		feeds = append(feeds, newFeed(ctx, row))
+	}
+	return feeds, nil
+}
+
+func (s *CalendarService) createFeed(ctx context.Context, hotelID uuid.UUID, body CreateFeedBody) (Feed, error) {
+	_, err := s.queries.GetHotelById(ctx, hotelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Feed{}, ErrHotelNotFound
+	}
+	if err != nil {
+		return Feed{}, fmt.Errorf("failed to get hotel %q: %w", hotelID, err)
+	}
+
+	var roomTypeID uuid.NullUUID
+	if body.RoomTypeID != nil {
+		_, err := s.queries.GetRoomTypeByIdAndHotelId(ctx, database.GetRoomTypeByIdAndHotelIdParams{
+			ID:      *body.RoomTypeID,
+			HotelID: hotelID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return Feed{}, ErrRoomTypeNotFound
+		}
+		if err != nil {
+			return Feed{}, fmt.Errorf("failed to get room type %q: %w", *body.RoomTypeID, err)
+		}
+		roomTypeID = uuid.NullUUID{UUID: *body.RoomTypeID, Valid: true}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return Feed{}, err
+	}
+
+	feed, err := s.queries.CreateCalendarFeed(ctx, database.CreateCalendarFeedParams{
+		ID:         uuid.New(),
+		HotelID:    hotelID,
+		RoomTypeID: roomTypeID,
+		Token:      token,
+	})
+	if err != nil {
+		return Feed{}, fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+
+	return newFeed(ctx, feed), nil
+}
+
+func (s *CalendarService) deleteFeed(ctx context.Context, hotelID, feedID uuid.UUID) error {
+	deleted, err := s.queries.DeleteCalendarFeed(ctx, database.DeleteCalendarFeedParams{
+		ID:      feedID,
+		HotelID: hotelID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar feed %q: %w", feedID, err)
+	}
+	if deleted == 0 {
+		return ErrFeedNotFound
+	}
+	return nil
+}
+
+// issueGuestFeed gives the guest a new feed token. A guest has one feed, so the previous URL stops working.
+func (s *CalendarService) issueGuestFeed(ctx context.Context, guestID uuid.UUID) (GuestFeed, error) {
+	token, err := generateToken()
+	if err != nil {
+		return GuestFeed{}, err
+	}
+
+	feed, err := s.queries.UpsertGuestCalendarFeed(ctx, database.UpsertGuestCalendarFeedParams{
+		GuestID: guestID,
+		Token:   token,
+	})
+	if err != nil {
+		return GuestFeed{}, fmt.Errorf("failed to issue calendar feed of guest %q: %w", guestID, err)
+	}
+
+	return newGuestFeed(ctx, feed), nil
+}
+
+func (s *CalendarService) revokeGuestFeed(ctx context.Context, guestID uuid.UUID) error {
+	deleted, err := s.queries.DeleteGuestCalendarFeed(ctx, guestID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke calendar feed of guest %q: %w", guestID, err)
+	}
+	if deleted == 0 {
+		return ErrFeedNotFound
+	}
+	return nil
+}
+
+// guestTokenFeed is guestFeed for the guest the token was issued to.
+func (s *CalendarService) guestTokenFeed(ctx context.Context, token string) (string, error) {
+	feed, err := s.queries.GetGuestCalendarFeedByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrFeedNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get guest calendar feed: %w", err)
+	}
+
+	return s.guestFeed(ctx, feed.GuestID)
+}
+
+// guestFeed lists the guest's stays, one event per reservation. The UID matches the one attached to
+// confirmation emails, so both end up as the same calendar entry.
+func (s *CalendarService) guestFeed(ctx context.Context, guestID uuid.UUID) (string, error) {
+	reservations, err := s.queries.GetGuestCalendarReservations(ctx, database.GetGuestCalendarReservationsParams{
+		GuestID:  guestID,
+		FromDate: feedStart(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get reservations of guest %q: %w", guestID, err)
+	}
+
+	stamp := time.Now().UTC()
+	calendarEvents := make([]ical.Event, 0, len(reservations))
+	for _, r := range reservations {
+		calendarEvents = append(calendarEvents, ical.Event{
+			UID:         ical.ReservationUID(r.ID),
+			Sequence:    int(r.Revision),
+			Summary:     fmt.Sprintf("%s - %s", r.HotelName, r.RoomTypeName),
+			Description: fmt.Sprintf("Reservation %s", r.ID),
+			Location:    r.HotelLocation,
+			Start:       r.StartDate,
+			End:         shared.CheckOut(r.EndDate),
+			Status:      eventStatus(r.Status),
+			Stamp:       stamp,
+		})
+	}
+
+	return ical.Calendar(ical.MethodPublish, "My reservations", calendarEvents...), nil
+}
+
+// hotelFeed lists arrivals and departures of the feed's hotel, optionally narrowed to one room type.
+func (s *CalendarService) hotelFeed(ctx context.Context, token string) (string, error) {
+	feed, err := s.queries.GetCalendarFeedByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrFeedNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	hotel, err := s.queries.GetHotelById(ctx, feed.HotelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrHotelNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get hotel %q: %w", feed.HotelID, err)
+	}
+
+	name := hotel.Name
+	if feed.RoomTypeID.Valid {
+		roomType, err := s.queries.GetRoomTypeByIdAndHotelId(ctx, database.GetRoomTypeByIdAndHotelIdParams{
+			ID:      feed.RoomTypeID.UUID,
+			HotelID: feed.HotelID,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to get room type %q: %w", feed.RoomTypeID.UUID, err)
+		}
+		name = fmt.Sprintf("%s - %s", hotel.Name, roomType.Name)
+	}
+
+	reservations, err := s.queries.GetHotelCalendarReservations(ctx, database.GetHotelCalendarReservationsParams{
+		HotelID:    feed.HotelID,
+		RoomTypeID: feed.RoomTypeID,
+		FromDate:   feedStart(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get reservations of hotel %q: %w", feed.HotelID, err)
+	}
+
+	stamp := time.Now().UTC()
+	calendarEvents := make([]ical.Event, 0, 2*len(reservations))
+	for _, r := range reservations {
+		guest := fmt.Sprintf("%s %s", r.GuestFirstName, r.GuestLastName)
+		description := fmt.Sprintf("Reservation %s, %s", r.ID, r.RoomTypeName)
+		sequence := int(r.Revision)
+		status := eventStatus(r.Status)
+		departure := shared.CheckOut(r.EndDate)
+
+		calendarEvents = append(calendarEvents,
+			ical.Event{
+				UID:         ical.ReservationPartUID(r.ID, "arrival"),
+				Sequence:    sequence,
+				Summary:     fmt.Sprintf("Arrival: %s (%s)", guest, r.RoomTypeName),
+				Description: description,
+				Start:       r.StartDate,
+				End:         r.StartDate.AddDate(0, 0, 1),
+				Status:      status,
+				Stamp:       stamp,
+			},
+			ical.Event{
+				UID:         ical.ReservationPartUID(r.ID, "departure"),
+				Sequence:    sequence,
+				Summary:     fmt.Sprintf("Departure: %s (%s)", guest, r.RoomTypeName),
+				Description: description,
+				Start:       departure,
+				End:         departure.AddDate(0, 0, 1),
+				Status:      status,
+				Stamp:       stamp,
+			},
+		)
+	}
+
+	return ical.Calendar(ical.MethodPublish, name, calendarEvents...), nil
+}
+
+func eventStatus(reservationStatus string) ical.Status {
+	switch reservationStatus {
+	case "cancelled", "rejected", "refunded":
+		return ical.StatusCancelled
+	case "held":
+		return ical.StatusTentative
+	default:
+		return ical.StatusConfirmed
+	}
+}
+
+func feedStart() time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -feedLookbackDays)
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
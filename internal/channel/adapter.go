@@ -0,0 +1,82 @@
+package channel
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Connection is what an adapter needs to talk to one channel on behalf of one hotel.
+type Connection struct {
+	EndpointURL string
+	HotelCode   string
+	APIKey      string
+}
+
+// ARIUpdate carries availability, rate and inventory of one room type for one night.
+type ARIUpdate struct {
+	RoomCode     string
+	RatePlanCode string
+	Date         time.Time
+	// Inventory is the physical room count, Available what is still sellable after reservations and overbooking.
+	Inventory int32
+	Available int32
+	Rate      string
+	Currency  string
+	StopSell  bool
+}
+
+type BookingStatus string
+
+const (
+	BookingStatusBook   BookingStatus = "book"
+	BookingStatusCancel BookingStatus = "cancel"
+)
+
+type BookingGuest struct {
+	FirstName string
+	LastName  string
+	Email     string
+}
+
+// Booking is a reservation or cancellation received from a channel.
+type Booking struct {
+	ExternalID   string
+	Status       BookingStatus
+	RoomCode     string
+	RatePlanCode string
+	// CheckIn and CheckOut follow the channel convention, CheckOut is the departure day.
+	CheckIn  time.Time
+	CheckOut time.Time
+	Guest    BookingGuest
+	// Err is set by the adapter when the booking couldn't be decoded.
+	Err error
+}
+
+// BookingResult reports back to the channel whether a booking was taken. ReservationID is set on success.
+type BookingResult struct {
+	ExternalID    string
+	ReservationID uuid.UUID
+	Error         string
+}
+
+// Notification is a batch of bookings pushed by a channel. APIKey is what the channel authenticated with.
+type Notification struct {
+	APIKey   string
+	Bookings []Booking
+}
+
+// Adapter speaks the protocol of a family of channels.
+type Adapter interface {
+	// PushARI sends availability, rates and inventory.
+	PushARI(ctx context.Context, conn Connection, updates []ARIUpdate) error
+	// PullReservations fetches bookings and cancellations the channel hasn't had confirmed yet.
+	PullReservations(ctx context.Context, conn Connection) ([]Booking, error)
+	// ConfirmReservations reports the outcome of pulled bookings, so the channel stops resending them.
+	ConfirmReservations(ctx context.Context, conn Connection, results []BookingResult) error
+	// ParseNotification decodes bookings the channel pushed to us.
+	ParseNotification(body []byte) (Notification, error)
+	// NotificationResponse encodes the reply to a pushed notification and returns its content type.
+	NotificationResponse(results []BookingResult) ([]byte, string, error)
+}
@@ -0,0 +1,92 @@
+// Package channel connects hotels to online travel agencies and other booking channels: it pushes
+// availability, rates and inventory (ARI) and imports the reservations sold there.
+package channel
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/overbooking"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrChannelNotFound    = errors.New("channelService: Channel not found")
+	ErrHotelNotFound      = errors.New("channelService: Hotel not found")
+	ErrRoomTypeNotFound   = errors.New("channelService: Room type not found")
+	ErrMappingNotFound    = errors.New("channelService: Room mapping not found")
+	ErrUnknownAdapter     = errors.New("channelService: Unknown adapter")
+	ErrUnauthorized       = errors.New("channelService: Invalid channel credentials")
+	ErrInvalidMessage     = errors.New("channelService: Invalid channel message")
+	ErrChannelExists      = errors.New("channelService: Channel with this name already exists")
+	ErrRoomCodeTaken      = errors.New("channelService: Room code is already mapped on this channel")
+	ErrEndpointNotAllowed = errors.New("channelService: Endpoint not allowed")
+)
+
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrChannelNotFound:    {Status: http.StatusNotFound, Code: "channel.not_found", Message: "Channel not found"},
+		ErrHotelNotFound:      {Status: http.StatusNotFound, Code: "hotel.not_found", Message: "Hotel not found"},
+		ErrRoomTypeNotFound:   {Status: http.StatusNotFound, Code: "room_type.not_found", Message: "Room type not found"},
+		ErrMappingNotFound:    {Status: http.StatusNotFound, Code: "channel.mapping_not_found", Message: "Room mapping not found"},
+		ErrChannelExists:      {Status: http.StatusConflict, Code: "channel.duplicate_name", Message: "Channel with this name already exists"},
+		ErrRoomCodeTaken:      {Status: http.StatusConflict, Code: "channel.room_code_taken", Message: "Room code is already mapped on this channel"},
+		ErrUnknownAdapter:     {Status: http.StatusBadRequest, Code: "channel.unknown_adapter", Message: "Unknown channel adapter"},
+		ErrInvalidMessage:     {Status: http.StatusBadRequest, Code: "channel.invalid_message"},
+		ErrUnauthorized:       {Status: http.StatusUnauthorized, Code: "channel.invalid_credentials", Message: "Invalid channel credentials"},
+		ErrEndpointNotAllowed: {Status: http.StatusBadRequest, Code: "channel.endpoint_not_allowed", Message: "Channel endpoint URL must resolve to a public address"},
+	})
+}
+
+const (
+	// ARI is pushed for this many nights ahead on every full sync.
+	ariHorizonDays = 180
+	syncInterval   = 15 * time.Minute
+	// Imports are retried when a concurrent reservation bumped the inventory version first.
+	maxImportAttempts = 3
+	maxNotifySize     = 1 << 20
+)
+
+type ChannelService struct {
+	queries      *database.Queries
+	validator    *validator.Validate
+	db           database.Service
+	reservations *reservation.ReservationService
+	overbooking  *overbooking.OverbookingService
+	adapters     map[string]Adapter
+	// Endpoints share the webhook allow-private-targets switch, both are URLs registered through the API.
+	allowPrivateEndpoints bool
+}
+
+func New(queries *database.Queries, validator *validator.Validate, cfg *config.Config, db database.Service, reservationSvc *reservation.ReservationService, overbookingSvc *overbooking.OverbookingService, adapters map[string]Adapter) *ChannelService {
+	return &ChannelService{
+		queries:               queries,
+		validator:             validator,
+		db:                    db,
+		reservations:          reservationSvc,
+		overbooking:           overbookingSvc,
+		adapters:              adapters,
+		allowPrivateEndpoints: cfg.Webhooks.AllowPrivateTargets,
+	}
+}
+
+func (s *ChannelService) adapter(name string) (Adapter, error) {
+	a, ok := s.adapters[name]
+	if !ok {
+		return nil, ErrUnknownAdapter
+	}
+	return a, nil
+}
+
+func connection(c database.BookingChannel) Connection {
+	return Connection{
+		EndpointURL: c.EndpointUrl,
+		HotelCode:   c.HotelCode,
+		APIKey:      c.ApiKey,
+	}
+}
@@ -0,0 +1,213 @@
+package channel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RegisterHotelHandlers mounts channel management. The router is expected to carry a {hotelId} URL parameter.
+func (s *ChannelService) RegisterHotelHandlers(r chi.Router) {
+	r.Get("/", s.ListChannelsHandler)
+	r.Post("/", s.CreateChannelHandler)
+	r.Delete("/{channelId}", s.DeleteChannelHandler)
+	r.Post("/{channelId}/sync", s.SyncChannelHandler)
+	r.Get("/{channelId}/mappings", s.ListMappingsHandler)
+	r.Put("/{channelId}/mappings/{roomTypeId}", s.SetMappingHandler)
+	r.Delete("/{channelId}/mappings/{roomTypeId}", s.DeleteMappingHandler)
+}
+
+// RegisterPublicHandlers mounts the endpoint channels push reservations to. Channels authenticate
+// with the API key inside the message, so it must stay outside of JWT auth.
+func (s *ChannelService) RegisterPublicHandlers(r chi.Router) {
+	r.Post("/channels/{channelId}/notify", s.NotifyHandler)
+}
+
+func (s *ChannelService) ListChannelsHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	channels, err := s.listChannels(r.Context(), hotelID)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to list channels")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"channels": channels})
+}
+
+func (s *ChannelService) CreateChannelHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	var body CreateChannelBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	channel, err := s.createChannel(r.Context(), hotelID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"channel": channel})
+}
+
+func (s *ChannelService) DeleteChannelHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, channelID, ok := hotelChannelParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.deleteChannel(r.Context(), hotelID, channelID); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *ChannelService) SyncChannelHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, channelID, ok := hotelChannelParams(w, r)
+	if !ok {
+		return
+	}
+
+	channel, err := s.syncNow(r.Context(), hotelID, channelID)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"channel": channel})
+}
+
+func (s *ChannelService) ListMappingsHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, channelID, ok := hotelChannelParams(w, r)
+	if !ok {
+		return
+	}
+
+	mappings, err := s.listMappings(r.Context(), hotelID, channelID)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"mappings": mappings})
+}
+
+func (s *ChannelService) SetMappingHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, channelID, ok := hotelChannelParams(w, r)
+	if !ok {
+		return
+	}
+
+	roomTypeID, err := uuid.Parse(chi.URLParam(r, "roomTypeId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid room type ID")
+		return
+	}
+
+	var body RoomMappingBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	mapping, err := s.setMapping(r.Context(), hotelID, channelID, roomTypeID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"mapping": mapping})
+}
+
+func (s *ChannelService) DeleteMappingHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, channelID, ok := hotelChannelParams(w, r)
+	if !ok {
+		return
+	}
+
+	roomTypeID, err := uuid.Parse(chi.URLParam(r, "roomTypeId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid room type ID")
+		return
+	}
+
+	if err := s.deleteMapping(r.Context(), hotelID, channelID, roomTypeID); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// NotifyHandler receives reservations pushed by a channel and replies in the channel's protocol.
+// Per-booking failures are part of that reply, only failures of the whole message are HTTP errors.
+func (s *ChannelService) NotifyHandler(w http.ResponseWriter, r *http.Request) {
+	channelID, err := uuid.Parse(chi.URLParam(r, "channelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid channel ID")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxNotifySize))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	reply, contentType, err := s.handleNotification(r.Context(), channelID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(reply); err != nil {
+		slog.WarnContext(r.Context(), "Failed to write channel notification reply", "error", err)
+	}
+}
+
+func hotelChannelParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	channelID, err := uuid.Parse(chi.URLParam(r, "channelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid channel ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return hotelID, channelID, true
+}
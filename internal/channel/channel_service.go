@@ -0,0 +1,249 @@
+package channel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/egress"
+	"github.com/google/uuid"
+)
+
+type CreateChannelBody struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Adapter     string `json:"adapter" validate:"required,oneof=ota_xml"`
+	EndpointURL string `json:"endpointUrl" validate:"required,http_url"`
+	// HotelCode is how the channel identifies the hotel.
+	HotelCode string `json:"hotelCode" validate:"required,max=64"`
+	// APIKey is sent with every message and expected on notifications from the channel.
+	APIKey string `json:"apiKey" validate:"required,min=16,max=255"`
+	// Defaults to true.
+	Active *bool `json:"active"`
+}
+
+// Channel is a channel connection without its API key.
+type Channel struct {
+	ID            uuid.UUID  `json:"id"`
+	HotelID       uuid.UUID  `json:"hotel_id"`
+	Name          string     `json:"name"`
+	Adapter       string     `json:"adapter"`
+	EndpointURL   string     `json:"endpoint_url"`
+	HotelCode     string     `json:"hotel_code"`
+	Active        bool       `json:"active"`
+	LastSyncedAt  *time.Time `json:"last_synced_at"`
+	LastSyncError *string    `json:"last_sync_error"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func newChannel(c database.BookingChannel) Channel {
+	channel := Channel{
+		ID:          c.ID,
+		HotelID:     c.HotelID,
+		Name:        c.Name,
+		Adapter:     c.Adapter,
+		EndpointURL: c.EndpointUrl,
+		HotelCode:   c.HotelCode,
+		Active:      c.Active,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if c.LastSyncedAt.Valid {
+		channel.LastSyncedAt = &c.LastSyncedAt.Time
+	}
+	if c.LastSyncError.Valid {
+		channel.LastSyncError = &c.LastSyncError.String
+	}
+	return channel
+}
+
+type RoomMappingBody struct {
+	ExternalRoomCode string `json:"externalRoomCode" validate:"required,max=64"`
+	RatePlanCode     string `json:"ratePlanCode" validate:"required,max=64"`
+	// Nightly rate pushed to the channel.
+	Rate     string `json:"rate" validate:"required,numeric"`
+	Currency string `json:"currency" validate:"required,len=3,uppercase"`
+}
+
+func (s *ChannelService) listChannels(ctx context.Context, hotelID uuid.UUID) ([]Channel, error) {
+	rows, err := s.queries.ListHotelChannels(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channels for hotel %q: %w", hotelID, err)
+	}
+
+	channels := make([]Channel, 0, len(rows))
+	for _, row := range rows {
+		channels = append(channels, newChannel(row))
+	}
+	return channels, nil
+}
+
+func (s *ChannelService) createChannel(ctx context.Context, hotelID uuid.UUID, body CreateChannelBody) (Channel, error) {
+	if _, err := s.adapter(body.Adapter); err != nil {
+		return Channel{}, err
+	}
+
+	if err := s.checkEndpoint(ctx, body.EndpointURL); err != nil {
+		return Channel{}, err
+	}
+
+	_, err := s.queries.GetHotelById(ctx, hotelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Channel{}, ErrHotelNotFound
+	}
+	if err != nil {
+		return Channel{}, fmt.Errorf("failed to get hotel %q: %w", hotelID, err)
+	}
+
+	active := true
+	if body.Active != nil {
+		active = *body.Active
+	}
+
+	created, err := s.queries.CreateChannel(ctx, database.CreateChannelParams{
+		ID:          uuid.New(),
+		HotelID:     hotelID,
+		Name:        body.Name,
+		Adapter:     body.Adapter,
+		EndpointUrl: body.EndpointURL,
+		HotelCode:   body.HotelCode,
+		ApiKey:      body.APIKey,
+		Active:      active,
+	})
+	if isUniqueViolation(err) {
+		return Channel{}, ErrChannelExists
+	}
+	if err != nil {
+		return Channel{}, fmt.Errorf("failed to create channel for hotel %q: %w", hotelID, err)
+	}
+
+	return newChannel(created), nil
+}
+
+// checkEndpoint rejects an endpoint URL whose host resolves to an address that isn't public.
+func (s *ChannelService) checkEndpoint(ctx context.Context, rawURL string) error {
+	if s.allowPrivateEndpoints {
+		return nil
+	}
+
+	if err := egress.CheckURL(ctx, rawURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrEndpointNotAllowed, err)
+	}
+	return nil
+}
+
+func (s *ChannelService) deleteChannel(ctx context.Context, hotelID, channelID uuid.UUID) error {
+	affected, err := s.queries.DeleteChannel(ctx, database.DeleteChannelParams{
+		ID:      channelID,
+		HotelID: hotelID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete channel %q: %w", channelID, err)
+	}
+	if affected == 0 {
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// getHotelChannel returns the channel if it belongs to the hotel.
+func (s *ChannelService) getHotelChannel(ctx context.Context, hotelID, channelID uuid.UUID) (database.BookingChannel, error) {
+	channel, err := s.queries.GetChannelById(ctx, channelID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && channel.HotelID != hotelID) {
+		return database.BookingChannel{}, ErrChannelNotFound
+	}
+	if err != nil {
+		return database.BookingChannel{}, fmt.Errorf("failed to get channel %q: %w", channelID, err)
+	}
+	return channel, nil
+}
+
+func (s *ChannelService) listMappings(ctx context.Context, hotelID, channelID uuid.UUID) ([]database.BookingChannelRoomMapping, error) {
+	if _, err := s.getHotelChannel(ctx, hotelID, channelID); err != nil {
+		return nil, err
+	}
+
+	mappings, err := s.queries.ListChannelRoomMappings(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list room mappings for channel %q: %w", channelID, err)
+	}
+	return mappings, nil
+}
+
+func (s *ChannelService) setMapping(ctx context.Context, hotelID, channelID, roomTypeID uuid.UUID, body RoomMappingBody) (database.BookingChannelRoomMapping, error) {
+	if _, err := s.getHotelChannel(ctx, hotelID, channelID); err != nil {
+		return database.BookingChannelRoomMapping{}, err
+	}
+
+	_, err := s.queries.GetRoomTypeByIdAndHotelId(ctx, database.GetRoomTypeByIdAndHotelIdParams{
+		ID:      roomTypeID,
+		HotelID: hotelID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingChannelRoomMapping{}, ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return database.BookingChannelRoomMapping{}, fmt.Errorf("failed to get room type %q: %w", roomTypeID, err)
+	}
+
+	mapping, err := s.queries.UpsertChannelRoomMapping(ctx, database.UpsertChannelRoomMappingParams{
+		ChannelID:        channelID,
+		RoomTypeID:       roomTypeID,
+		ExternalRoomCode: body.ExternalRoomCode,
+		RatePlanCode:     body.RatePlanCode,
+		Rate:             body.Rate,
+		Currency:         body.Currency,
+	})
+	if isUniqueViolation(err) {
+		return database.BookingChannelRoomMapping{}, ErrRoomCodeTaken
+	}
+	if err != nil {
+		return database.BookingChannelRoomMapping{}, fmt.Errorf("failed to map room type %q on channel %q: %w", roomTypeID, channelID, err)
+	}
+	return mapping, nil
+}
+
+func (s *ChannelService) deleteMapping(ctx context.Context, hotelID, channelID, roomTypeID uuid.UUID) error {
+	if _, err := s.getHotelChannel(ctx, hotelID, channelID); err != nil {
+		return err
+	}
+
+	affected, err := s.queries.DeleteChannelRoomMapping(ctx, database.DeleteChannelRoomMappingParams{
+		ChannelID:  channelID,
+		RoomTypeID: roomTypeID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete room mapping of %q on channel %q: %w", roomTypeID, channelID, err)
+	}
+	if affected == 0 {
+		return ErrMappingNotFound
+	}
+	return nil
+}
+
+// syncNow runs a full sync of the channel. Sync failures are reported through last_sync_error of the returned channel.
+func (s *ChannelService) syncNow(ctx context.Context, hotelID, channelID uuid.UUID) (Channel, error) {
+	c, err := s.getHotelChannel(ctx, hotelID, channelID)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	if err := s.syncChannel(ctx, c); err != nil {
+		slog.WarnContext(ctx, "Channel sync failed", "channel_id", c.ID, "error", err)
+	}
+
+	c, err = s.getHotelChannel(ctx, hotelID, channelID)
+	if err != nil {
+		return Channel{}, err
+	}
+	return newChannel(c), nil
+}
+
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint")
+}
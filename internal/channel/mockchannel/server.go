@@ -0,0 +1,375 @@
+// Package mockchannel is an in-memory OTA XML channel for tests and local development. It records the
+// ARI it receives, hands out queued reservations and keeps track of which ones were confirmed.
+package mockchannel
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/channel/otaxml"
+)
+
+// Night is the last ARI received for one room code and date.
+type Night struct {
+	Inventory    int32
+	Available    int32
+	RatePlanCode string
+	Rate         string
+	Currency     string
+	StopSell     bool
+}
+
+type nightKey struct {
+	roomCode string
+	date     string
+}
+
+type Server struct {
+	hotelCode string
+	apiKey    string
+
+	mu        sync.Mutex
+	nights    map[nightKey]Night
+	pending   []otaxml.HotelReservation
+	confirmed map[string]string
+	failed    map[string]string
+}
+
+func New(hotelCode, apiKey string) *Server {
+	return &Server{
+		hotelCode: hotelCode,
+		apiKey:    apiKey,
+		nights:    make(map[nightKey]Night),
+		confirmed: make(map[string]string),
+		failed:    make(map[string]string),
+	}
+}
+
+// Booking builds a new reservation as the channel would send it. checkOut is the departure day.
+func Booking(externalID, roomCode, ratePlanCode string, checkIn, checkOut time.Time, givenName, surname, email string) otaxml.HotelReservation {
+	return otaxml.HotelReservation{
+		ResStatus: otaxml.ResStatusBook,
+		UniqueID:  otaxml.UniqueID{Type: otaxml.UniqueIDTypeReservation, ID: externalID},
+		RoomStays: []otaxml.RoomStay{{
+			RoomTypes: []otaxml.RoomType{{RoomTypeCode: roomCode}},
+			RatePlans: []otaxml.RatePlan{{RatePlanCode: ratePlanCode}},
+			TimeSpan:  otaxml.TimeSpan{Start: checkIn.Format(otaxml.DateLayout), End: checkOut.Format(otaxml.DateLayout)},
+		}},
+		ResGuests: []otaxml.ResGuest{{Customer: otaxml.Customer{GivenName: givenName, Surname: surname, Email: email}}},
+	}
+}
+
+// Cancellation builds the cancellation of a reservation the channel sent before.
+func Cancellation(externalID string) otaxml.HotelReservation {
+	return otaxml.HotelReservation{
+		ResStatus: otaxml.ResStatusCancel,
+		UniqueID:  otaxml.UniqueID{Type: otaxml.UniqueIDTypeReservation, ID: externalID},
+	}
+}
+
+// AddReservation queues a reservation to be returned by the next OTA_ReadRQ.
+func (s *Server) AddReservation(r otaxml.HotelReservation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, r)
+}
+
+// Night returns the ARI received for the room code and date.
+func (s *Server) Night(roomCode string, date time.Time) (Night, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.nights[nightKey{roomCode: roomCode, date: date.Format(otaxml.DateLayout)}]
+	return n, ok
+}
+
+// Confirmed returns the hotel's reservation ID reported for a confirmed reservation.
+func (s *Server) Confirmed(externalID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.confirmed[externalID]
+	return id, ok
+}
+
+// Failed returns the error reported for a reservation the hotel didn't take.
+func (s *Server) Failed(externalID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.failed[externalID]
+	return msg, ok
+}
+
+// Pending is the number of reservations that weren't confirmed or rejected yet.
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// Notify pushes reservations to the hotel's notification endpoint and returns its reply.
+func (s *Server) Notify(ctx context.Context, client *http.Client, url string, reservations ...otaxml.HotelReservation) (otaxml.HotelResNotifRS, int, error) {
+	msg := otaxml.HotelResNotifRQ{
+		Header:            otaxml.NewHeader(),
+		POS:               s.pos(),
+		HotelReservations: reservations,
+	}
+
+	body, err := xml.Marshal(msg)
+	if err != nil {
+		return otaxml.HotelResNotifRS{}, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return otaxml.HotelResNotifRS{}, 0, err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return otaxml.HotelResNotifRS{}, 0, err
+	}
+	defer resp.Body.Close()
+
+	var rs otaxml.HotelResNotifRS
+	if resp.StatusCode != http.StatusOK {
+		return rs, resp.StatusCode, nil
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&rs); err != nil {
+		return rs, resp.StatusCode, fmt.Errorf("invalid OTA_HotelResNotifRS: %w", err)
+	}
+	return rs, resp.StatusCode, nil
+}
+
+func (s *Server) pos() otaxml.POS {
+	return otaxml.POS{Source: otaxml.Source{RequestorID: otaxml.RequestorID{ID: s.hotelCode, MessagePassword: s.apiKey}}}
+}
+
+// ServeHTTP answers every OTA message on any path, dispatching on the root element.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	root, err := rootElement(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var envelope struct {
+		POS otaxml.POS `xml:"POS"`
+	}
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	requestor := envelope.POS.Source.RequestorID
+	if requestor.ID != s.hotelCode || requestor.MessagePassword != s.apiKey {
+		s.reply(w, errorResponse(root, "497", "Authorization error"))
+		return
+	}
+
+	switch root {
+	case "OTA_HotelInvCountNotifRQ":
+		var msg otaxml.HotelInvCountNotifRQ
+		if !decode(w, body, &msg) {
+			return
+		}
+		s.recordInventory(msg)
+		s.reply(w, successResponse("OTA_HotelInvCountNotifRS"))
+	case "OTA_HotelRateAmountNotifRQ":
+		var msg otaxml.HotelRateAmountNotifRQ
+		if !decode(w, body, &msg) {
+			return
+		}
+		s.recordRates(msg)
+		s.reply(w, successResponse("OTA_HotelRateAmountNotifRS"))
+	case "OTA_HotelAvailNotifRQ":
+		var msg otaxml.HotelAvailNotifRQ
+		if !decode(w, body, &msg) {
+			return
+		}
+		s.recordAvailability(msg)
+		s.reply(w, successResponse("OTA_HotelAvailNotifRS"))
+	case "OTA_ReadRQ":
+		s.reply(w, s.retrieve())
+	case "OTA_NotifReportRQ":
+		var msg otaxml.NotifReportRQ
+		if !decode(w, body, &msg) {
+			return
+		}
+		s.recordReport(msg)
+		s.reply(w, successResponse("OTA_NotifReportRS"))
+	default:
+		s.reply(w, errorResponse(root, "448", "Unsupported message"))
+	}
+}
+
+func (s *Server) recordInventory(msg otaxml.HotelInvCountNotifRQ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inv := range msg.Inventories.Inventories {
+		s.eachNight(inv.StatusApplicationControl, func(n *Night) {
+			for _, c := range inv.InvCounts {
+				switch c.CountType {
+				case otaxml.CountTypePhysical:
+					n.Inventory = c.Count
+				case otaxml.CountTypeAvailable:
+					n.Available = c.Count
+				}
+			}
+		})
+	}
+}
+
+func (s *Server) recordRates(msg otaxml.HotelRateAmountNotifRQ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range msg.RateAmountMessages.Messages {
+		s.eachNight(m.StatusApplicationControl, func(n *Night) {
+			n.RatePlanCode = m.StatusApplicationControl.RatePlanCode
+			if len(m.BaseByGuestAmts) > 0 {
+				n.Rate = m.BaseByGuestAmts[0].AmountAfterTax
+				n.Currency = m.BaseByGuestAmts[0].CurrencyCode
+			}
+		})
+	}
+}
+
+func (s *Server) recordAvailability(msg otaxml.HotelAvailNotifRQ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range msg.AvailStatusMessages.Messages {
+		s.eachNight(m.StatusApplicationControl, func(n *Night) {
+			n.StopSell = m.RestrictionStatus.Status == "Close"
+		})
+	}
+}
+
+// eachNight applies fn to every night of the control's date range. The caller holds s.mu.
+func (s *Server) eachNight(control otaxml.StatusApplicationControl, fn func(n *Night)) {
+	start, err := time.Parse(otaxml.DateLayout, control.Start)
+	if err != nil {
+		return
+	}
+	end, err := time.Parse(otaxml.DateLayout, control.End)
+	if err != nil {
+		return
+	}
+
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		key := nightKey{roomCode: control.InvTypeCode, date: d.Format(otaxml.DateLayout)}
+		n := s.nights[key]
+		fn(&n)
+		s.nights[key] = n
+	}
+}
+
+func (s *Server) retrieve() otaxml.ResRetrieveRS {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return otaxml.ResRetrieveRS{
+		Header:            otaxml.NewHeader(),
+		Response:          otaxml.Response{Success: &struct{}{}},
+		HotelReservations: append([]otaxml.HotelReservation(nil), s.pending...),
+	}
+}
+
+// recordReport marks reservations as delivered, whether the hotel took them or not.
+func (s *Server) recordReport(msg otaxml.NotifReportRQ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivered := make(map[string]bool)
+	for _, r := range msg.HotelReservations {
+		id := ""
+		if r.ResGlobalInfo != nil && len(r.ResGlobalInfo.HotelReservationIDs) > 0 {
+			id = r.ResGlobalInfo.HotelReservationIDs[0].ResIDValue
+		}
+		s.confirmed[r.UniqueID.ID] = id
+		delivered[r.UniqueID.ID] = true
+	}
+	if msg.Errors != nil {
+		for _, e := range msg.Errors.Errors {
+			s.failed[e.RecordID] = e.ShortText
+			delivered[e.RecordID] = true
+		}
+	}
+
+	pending := s.pending[:0]
+	for _, r := range s.pending {
+		if !delivered[r.UniqueID.ID] {
+			pending = append(pending, r)
+		}
+	}
+	s.pending = pending
+}
+
+func (s *Server) reply(w http.ResponseWriter, msg any) {
+	body, err := xml.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	_, _ = w.Write(append([]byte(xml.Header), body...))
+}
+
+type genericRS struct {
+	XMLName xml.Name
+	otaxml.Header
+	otaxml.Response
+}
+
+func successResponse(name string) genericRS {
+	return genericRS{
+		XMLName:  xml.Name{Local: name},
+		Header:   otaxml.NewHeader(),
+		Response: otaxml.Response{Success: &struct{}{}},
+	}
+}
+
+func errorResponse(requestName, code, text string) genericRS {
+	name := requestName
+	if len(name) > 2 && name[len(name)-2:] == "RQ" {
+		name = name[:len(name)-2] + "RS"
+	}
+	return genericRS{
+		XMLName:  xml.Name{Local: name},
+		Header:   otaxml.NewHeader(),
+		Response: otaxml.Response{Errors: &otaxml.Errors{Errors: []otaxml.Error{{Type: "1", Code: code, ShortText: text}}}},
+	}
+}
+
+func decode(w http.ResponseWriter, body []byte, msg any) bool {
+	if err := xml.Unmarshal(body, msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func rootElement(body []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", fmt.Errorf("no root element: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
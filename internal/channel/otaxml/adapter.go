@@ -0,0 +1,312 @@
+package otaxml
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/channel"
+	"github.com/google/uuid"
+)
+
+// Name is the adapter value channels using this protocol are stored with.
+const Name = "ota_xml"
+
+const (
+	contentType     = "application/xml; charset=utf-8"
+	maxResponseSize = 10 << 20
+)
+
+// Adapter posts every message to the channel's endpoint URL, the root element tells them apart.
+type Adapter struct {
+	client *http.Client
+}
+
+func New(client *http.Client) *Adapter {
+	return &Adapter{client: client}
+}
+
+func NewHeader() Header {
+	return Header{
+		Xmlns:     Namespace,
+		Version:   Version,
+		EchoToken: uuid.NewString(),
+		TimeStamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func newPOS(conn channel.Connection) POS {
+	return POS{Source: Source{RequestorID: RequestorID{ID: conn.HotelCode, MessagePassword: conn.APIKey}}}
+}
+
+func (a *Adapter) PushARI(ctx context.Context, conn channel.Connection, updates []channel.ARIUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	invCounts := HotelInvCountNotifRQ{
+		Header:      NewHeader(),
+		POS:         newPOS(conn),
+		Inventories: Inventories{HotelCode: conn.HotelCode},
+	}
+	rates := HotelRateAmountNotifRQ{
+		Header:             NewHeader(),
+		POS:                newPOS(conn),
+		RateAmountMessages: RateAmountMessages{HotelCode: conn.HotelCode},
+	}
+	avail := HotelAvailNotifRQ{
+		Header:              NewHeader(),
+		POS:                 newPOS(conn),
+		AvailStatusMessages: AvailStatusMessages{HotelCode: conn.HotelCode},
+	}
+
+	for _, u := range updates {
+		date := u.Date.Format(DateLayout)
+		control := StatusApplicationControl{Start: date, End: date, InvTypeCode: u.RoomCode}
+
+		invCounts.Inventories.Inventories = append(invCounts.Inventories.Inventories, Inventory{
+			StatusApplicationControl: control,
+			InvCounts: []InvCount{
+				{CountType: CountTypePhysical, Count: u.Inventory},
+				{CountType: CountTypeAvailable, Count: u.Available},
+			},
+		})
+
+		rateControl := control
+		rateControl.RatePlanCode = u.RatePlanCode
+		rates.RateAmountMessages.Messages = append(rates.RateAmountMessages.Messages, RateAmountMessage{
+			StatusApplicationControl: rateControl,
+			BaseByGuestAmts:          []BaseByGuestAmt{{AmountAfterTax: u.Rate, CurrencyCode: u.Currency}},
+		})
+
+		status := "Open"
+		if u.StopSell {
+			status = "Close"
+		}
+		avail.AvailStatusMessages.Messages = append(avail.AvailStatusMessages.Messages, AvailStatusMessage{
+			BookingLimit:             u.Available,
+			StatusApplicationControl: rateControl,
+			RestrictionStatus:        RestrictionStatus{Status: status},
+		})
+	}
+
+	for _, msg := range []any{invCounts, rates, avail} {
+		var res Response
+		if err := a.post(ctx, conn, msg, &res); err != nil {
+			return err
+		}
+		if err := res.err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *Adapter) PullReservations(ctx context.Context, conn channel.Connection) ([]channel.Booking, error) {
+	req := ReadRQ{
+		Header: NewHeader(),
+		POS:    newPOS(conn),
+		HotelReadRequest: HotelReadRequest{
+			HotelCode:         conn.HotelCode,
+			SelectionCriteria: SelectionCriteria{SelectionType: "Undelivered"},
+		},
+	}
+
+	var res ResRetrieveRS
+	if err := a.post(ctx, conn, req, &res); err != nil {
+		return nil, err
+	}
+	if err := res.err(); err != nil {
+		return nil, err
+	}
+
+	bookings := make([]channel.Booking, 0, len(res.HotelReservations))
+	for _, r := range res.HotelReservations {
+		bookings = append(bookings, toBooking(r))
+	}
+	return bookings, nil
+}
+
+func (a *Adapter) ConfirmReservations(ctx context.Context, conn channel.Connection, results []channel.BookingResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	req := NotifReportRQ{
+		Header: NewHeader(),
+		POS:    newPOS(conn),
+	}
+	req.Response = toResponse(results)
+	req.HotelReservations = confirmedReservations(results)
+
+	var res Response
+	if err := a.post(ctx, conn, req, &res); err != nil {
+		return err
+	}
+	return res.err()
+}
+
+func (a *Adapter) ParseNotification(body []byte) (channel.Notification, error) {
+	var req HotelResNotifRQ
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return channel.Notification{}, fmt.Errorf("invalid OTA_HotelResNotifRQ: %w", err)
+	}
+
+	notification := channel.Notification{APIKey: req.POS.Source.RequestorID.MessagePassword}
+	for _, r := range req.HotelReservations {
+		notification.Bookings = append(notification.Bookings, toBooking(r))
+	}
+	return notification, nil
+}
+
+func (a *Adapter) NotificationResponse(results []channel.BookingResult) ([]byte, string, error) {
+	res := HotelResNotifRS{
+		Header:            NewHeader(),
+		Response:          toResponse(results),
+		HotelReservations: confirmedReservations(results),
+	}
+
+	body, err := xml.Marshal(res)
+	if err != nil {
+		return nil, "", err
+	}
+	return append([]byte(xml.Header), body...), contentType, nil
+}
+
+func (a *Adapter) post(ctx context.Context, conn channel.Connection, msg any, out any) error {
+	body, err := xml.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, conn.EndpointURL, bytes.NewReader(append([]byte(xml.Header), body...)))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err := xml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+func (r Response) err() error {
+	if r.Success != nil {
+		return nil
+	}
+	if r.Errors == nil || len(r.Errors.Errors) == 0 {
+		return errors.New("channel replied without Success")
+	}
+
+	messages := make([]string, 0, len(r.Errors.Errors))
+	for _, e := range r.Errors.Errors {
+		messages = append(messages, strings.TrimSpace(e.Code+" "+e.ShortText))
+	}
+	return fmt.Errorf("channel rejected message: %s", strings.Join(messages, "; "))
+}
+
+// toResponse reports failed bookings as errors keyed by the channel's reference. Success is always set,
+// the message itself was processed.
+func toResponse(results []channel.BookingResult) Response {
+	var errs []Error
+	for _, r := range results {
+		if r.Error != "" {
+			errs = append(errs, Error{Type: "3", RecordID: r.ExternalID, ShortText: r.Error})
+		}
+	}
+
+	res := Response{Success: &struct{}{}}
+	if len(errs) > 0 {
+		res.Errors = &Errors{Errors: errs}
+	}
+	return res
+}
+
+func confirmedReservations(results []channel.BookingResult) []HotelReservation {
+	var reservations []HotelReservation
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		reservations = append(reservations, HotelReservation{
+			UniqueID: UniqueID{Type: UniqueIDTypeReservation, ID: r.ExternalID},
+			ResGlobalInfo: &ResGlobalInfo{
+				HotelReservationIDs: []HotelReservationID{{ResIDType: ResIDTypeHotel, ResIDValue: r.ReservationID.String()}},
+			},
+		})
+	}
+	return reservations
+}
+
+func toBooking(r HotelReservation) channel.Booking {
+	booking := channel.Booking{ExternalID: r.UniqueID.ID}
+
+	switch r.ResStatus {
+	case ResStatusBook, "Commit", "Modify":
+		booking.Status = channel.BookingStatusBook
+	case ResStatusCancel:
+		booking.Status = channel.BookingStatusCancel
+	default:
+		booking.Err = fmt.Errorf("unsupported ResStatus %q", r.ResStatus)
+		return booking
+	}
+
+	if len(r.ResGuests) > 0 {
+		customer := r.ResGuests[0].Customer
+		booking.Guest = channel.BookingGuest{FirstName: customer.GivenName, LastName: customer.Surname, Email: customer.Email}
+	}
+
+	// Cancellations are identified by UniqueID alone.
+	if booking.Status == channel.BookingStatusCancel {
+		return booking
+	}
+
+	if len(r.RoomStays) != 1 || len(r.RoomStays[0].RoomTypes) != 1 {
+		booking.Err = errors.New("exactly one room stay with one room type is supported")
+		return booking
+	}
+
+	stay := r.RoomStays[0]
+	booking.RoomCode = stay.RoomTypes[0].RoomTypeCode
+	if len(stay.RatePlans) > 0 {
+		booking.RatePlanCode = stay.RatePlans[0].RatePlanCode
+	}
+
+	checkIn, err := time.Parse(DateLayout, stay.TimeSpan.Start)
+	if err != nil {
+		booking.Err = fmt.Errorf("invalid TimeSpan Start: %w", err)
+		return booking
+	}
+	checkOut, err := time.Parse(DateLayout, stay.TimeSpan.End)
+	if err != nil {
+		booking.Err = fmt.Errorf("invalid TimeSpan End: %w", err)
+		return booking
+	}
+	booking.CheckIn = checkIn
+	booking.CheckOut = checkOut
+
+	return booking
+}
@@ -0,0 +1,236 @@
+// Package otaxml implements the channel adapter for OpenTravel (OTA) 2003/05 style XML messages.
+// Only the elements needed for ARI and reservation exchange are modelled.
+package otaxml
+
+import "encoding/xml"
+
+const (
+	Namespace  = "http://www.opentravel.org/OTA/2003/05"
+	Version    = "1.0"
+	DateLayout = "2006-01-02"
+)
+
+// InvCount types as defined by the OTA code list.
+const (
+	CountTypePhysical  = 1
+	CountTypeAvailable = 2
+)
+
+const (
+	ResStatusBook   = "Book"
+	ResStatusCancel = "Cancel"
+)
+
+// UniqueID type 14 identifies a reservation, ResID type 10 is the hotel's own reference.
+const (
+	UniqueIDTypeReservation = "14"
+	ResIDTypeHotel          = "10"
+)
+
+type POS struct {
+	Source Source `xml:"Source"`
+}
+
+type Source struct {
+	RequestorID RequestorID `xml:"RequestorID"`
+}
+
+type RequestorID struct {
+	ID              string `xml:"ID,attr"`
+	MessagePassword string `xml:"MessagePassword,attr,omitempty"`
+}
+
+type Header struct {
+	Xmlns     string `xml:"xmlns,attr"`
+	Version   string `xml:"Version,attr"`
+	EchoToken string `xml:"EchoToken,attr,omitempty"`
+	TimeStamp string `xml:"TimeStamp,attr,omitempty"`
+}
+
+type StatusApplicationControl struct {
+	Start        string `xml:"Start,attr"`
+	End          string `xml:"End,attr"`
+	InvTypeCode  string `xml:"InvTypeCode,attr"`
+	RatePlanCode string `xml:"RatePlanCode,attr,omitempty"`
+}
+
+type Error struct {
+	Type      string `xml:"Type,attr,omitempty"`
+	Code      string `xml:"Code,attr,omitempty"`
+	RecordID  string `xml:"RecordID,attr,omitempty"`
+	ShortText string `xml:"ShortText,attr,omitempty"`
+}
+
+// Response holds what every OTA RS message carries: either Success or Errors.
+type Response struct {
+	Success *struct{} `xml:"Success"`
+	Errors  *Errors   `xml:"Errors"`
+}
+
+type Errors struct {
+	Errors []Error `xml:"Error"`
+}
+
+type HotelInvCountNotifRQ struct {
+	XMLName xml.Name `xml:"OTA_HotelInvCountNotifRQ"`
+	Header
+	POS         POS         `xml:"POS"`
+	Inventories Inventories `xml:"Inventories"`
+}
+
+type Inventories struct {
+	HotelCode   string      `xml:"HotelCode,attr"`
+	Inventories []Inventory `xml:"Inventory"`
+}
+
+type Inventory struct {
+	StatusApplicationControl StatusApplicationControl `xml:"StatusApplicationControl"`
+	InvCounts                []InvCount               `xml:"InvCounts>InvCount"`
+}
+
+type InvCount struct {
+	CountType int   `xml:"CountType,attr"`
+	Count     int32 `xml:"Count,attr"`
+}
+
+type HotelRateAmountNotifRQ struct {
+	XMLName xml.Name `xml:"OTA_HotelRateAmountNotifRQ"`
+	Header
+	POS                POS                `xml:"POS"`
+	RateAmountMessages RateAmountMessages `xml:"RateAmountMessages"`
+}
+
+type RateAmountMessages struct {
+	HotelCode string              `xml:"HotelCode,attr"`
+	Messages  []RateAmountMessage `xml:"RateAmountMessage"`
+}
+
+type RateAmountMessage struct {
+	StatusApplicationControl StatusApplicationControl `xml:"StatusApplicationControl"`
+	BaseByGuestAmts          []BaseByGuestAmt         `xml:"Rates>Rate>BaseByGuestAmts>BaseByGuestAmt"`
+}
+
+type BaseByGuestAmt struct {
+	AmountAfterTax string `xml:"AmountAfterTax,attr"`
+	CurrencyCode   string `xml:"CurrencyCode,attr"`
+}
+
+type HotelAvailNotifRQ struct {
+	XMLName xml.Name `xml:"OTA_HotelAvailNotifRQ"`
+	Header
+	POS                 POS                 `xml:"POS"`
+	AvailStatusMessages AvailStatusMessages `xml:"AvailStatusMessages"`
+}
+
+type AvailStatusMessages struct {
+	HotelCode string               `xml:"HotelCode,attr"`
+	Messages  []AvailStatusMessage `xml:"AvailStatusMessage"`
+}
+
+type AvailStatusMessage struct {
+	BookingLimit             int32                    `xml:"BookingLimit,attr"`
+	StatusApplicationControl StatusApplicationControl `xml:"StatusApplicationControl"`
+	RestrictionStatus        RestrictionStatus        `xml:"RestrictionStatus"`
+}
+
+type RestrictionStatus struct {
+	// Open or Close
+	Status string `xml:"Status,attr"`
+}
+
+type ReadRQ struct {
+	XMLName xml.Name `xml:"OTA_ReadRQ"`
+	Header
+	POS              POS              `xml:"POS"`
+	HotelReadRequest HotelReadRequest `xml:"ReadRequests>HotelReadRequest"`
+}
+
+type HotelReadRequest struct {
+	HotelCode         string            `xml:"HotelCode,attr"`
+	SelectionCriteria SelectionCriteria `xml:"SelectionCriteria"`
+}
+
+type SelectionCriteria struct {
+	// Undelivered asks for reservations that weren't confirmed through OTA_NotifReportRQ yet.
+	SelectionType string `xml:"SelectionType,attr"`
+}
+
+type ResRetrieveRS struct {
+	XMLName xml.Name `xml:"OTA_ResRetrieveRS"`
+	Header
+	Response
+	HotelReservations []HotelReservation `xml:"ReservationsList>HotelReservation"`
+}
+
+type HotelReservation struct {
+	ResStatus     string         `xml:"ResStatus,attr,omitempty"`
+	UniqueID      UniqueID       `xml:"UniqueID"`
+	RoomStays     []RoomStay     `xml:"RoomStays>RoomStay"`
+	ResGuests     []ResGuest     `xml:"ResGuests>ResGuest"`
+	ResGlobalInfo *ResGlobalInfo `xml:"ResGlobalInfo,omitempty"`
+}
+
+type UniqueID struct {
+	Type string `xml:"Type,attr"`
+	ID   string `xml:"ID,attr"`
+}
+
+type RoomStay struct {
+	RoomTypes []RoomType `xml:"RoomTypes>RoomType"`
+	RatePlans []RatePlan `xml:"RatePlans>RatePlan"`
+	TimeSpan  TimeSpan   `xml:"TimeSpan"`
+}
+
+type RoomType struct {
+	RoomTypeCode string `xml:"RoomTypeCode,attr"`
+}
+
+type RatePlan struct {
+	RatePlanCode string `xml:"RatePlanCode,attr"`
+}
+
+type TimeSpan struct {
+	Start string `xml:"Start,attr"`
+	End   string `xml:"End,attr"`
+}
+
+type ResGuest struct {
+	Customer Customer `xml:"Profiles>ProfileInfo>Profile>Customer"`
+}
+
+type Customer struct {
+	GivenName string `xml:"PersonName>GivenName"`
+	Surname   string `xml:"PersonName>Surname"`
+	Email     string `xml:"Email"`
+}
+
+type ResGlobalInfo struct {
+	HotelReservationIDs []HotelReservationID `xml:"HotelReservationIDs>HotelReservationID"`
+}
+
+type HotelReservationID struct {
+	ResIDType  string `xml:"ResID_Type,attr"`
+	ResIDValue string `xml:"ResID_Value,attr"`
+}
+
+type NotifReportRQ struct {
+	XMLName xml.Name `xml:"OTA_NotifReportRQ"`
+	Header
+	POS POS `xml:"POS"`
+	Response
+	HotelReservations []HotelReservation `xml:"NotifDetails>HotelNotifReport>HotelReservations>HotelReservation"`
+}
+
+type HotelResNotifRQ struct {
+	XMLName xml.Name `xml:"OTA_HotelResNotifRQ"`
+	Header
+	POS               POS                `xml:"POS"`
+	HotelReservations []HotelReservation `xml:"HotelReservations>HotelReservation"`
+}
+
+type HotelResNotifRS struct {
+	XMLName xml.Name `xml:"OTA_HotelResNotifRS"`
+	Header
+	Response
+	HotelReservations []HotelReservation `xml:"HotelReservations>HotelReservation"`
+}
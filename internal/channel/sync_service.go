@@ -0,0 +1,419 @@
+package channel
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/metrics"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// HandleEvent pushes ARI of the affected nights to every channel selling the room type. Channels that
+// can't be reached are caught up by the next full sync, so failures are logged rather than retried.
+// It is meant to be subscribed to the event bus.
+func (s *ChannelService) HandleEvent(ctx context.Context, e events.Event) error {
+	if e.Type != events.InventoryChanged {
+		return nil
+	}
+
+	var payload events.InventoryChangedPayload
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return nil
+	}
+
+	channels, err := s.queries.GetActiveChannelsForRoomType(ctx, database.GetActiveChannelsForRoomTypeParams{
+		HotelID:    payload.HotelID,
+		RoomTypeID: payload.RoomTypeID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get channels for room type %q: %w", payload.RoomTypeID, err)
+	}
+
+	for _, c := range channels {
+		err := s.pushARI(ctx, c, uuid.NullUUID{UUID: payload.RoomTypeID, Valid: true}, time.Time(payload.From), time.Time(payload.To))
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to push ARI to channel", "channel_id", c.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// RunSyncWorker periodically pushes ARI to and pulls reservations from every active channel until ctx is done.
+func (s *ChannelService) RunSyncWorker(ctx context.Context) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		s.SyncAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll runs a full sync of every active channel.
+func (s *ChannelService) SyncAll(ctx context.Context) {
+	channels, err := s.queries.GetActiveChannels(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get active channels", "error", err)
+		return
+	}
+
+	for _, c := range channels {
+		if err := s.syncChannel(ctx, c); err != nil {
+			slog.WarnContext(ctx, "Channel sync failed", "channel_id", c.ID, "error", err)
+		}
+	}
+}
+
+// syncChannel imports pending reservations, pushes ARI for the whole horizon and records the outcome.
+// Importing first lets the pushed availability account for what the channel just sold.
+func (s *ChannelService) syncChannel(ctx context.Context, c database.BookingChannel) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	syncErr := errors.Join(
+		s.pullReservations(ctx, c),
+		s.pushARI(ctx, c, uuid.NullUUID{}, today, today.AddDate(0, 0, ariHorizonDays-1)),
+	)
+
+	lastError := sql.NullString{}
+	if syncErr != nil {
+		lastError = sql.NullString{String: syncErr.Error(), Valid: true}
+	}
+
+	if err := s.queries.RecordChannelSync(ctx, database.RecordChannelSyncParams{
+		LastSyncError: lastError,
+		ID:            c.ID,
+	}); err != nil {
+		return errors.Join(syncErr, fmt.Errorf("failed to record sync of channel %q: %w", c.ID, err))
+	}
+
+	return syncErr
+}
+
+// pushARI sends the nights between from and to, inclusive, of every mapped room type, or only of roomTypeID when set.
+func (s *ChannelService) pushARI(ctx context.Context, c database.BookingChannel, roomTypeID uuid.NullUUID, from, to time.Time) error {
+	adapter, err := s.adapter(c.Adapter)
+	if err != nil {
+		return err
+	}
+
+	mappings, err := s.queries.ListChannelRoomMappings(ctx, c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list room mappings for channel %q: %w", c.ID, err)
+	}
+
+	policies, err := s.overbooking.HotelPolicies(ctx, c.HotelID)
+	if err != nil {
+		return err
+	}
+
+	var updates []ARIUpdate
+	for _, m := range mappings {
+		if roomTypeID.Valid && m.RoomTypeID != roomTypeID.UUID {
+			continue
+		}
+
+		inventory, err := s.queries.GetHotelInventoryForRange(ctx, database.GetHotelInventoryForRangeParams{
+			RoomTypeID: m.RoomTypeID,
+			HotelID:    c.HotelID,
+			Date:       from,
+			Date_2:     to,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get inventory of room type %q: %w", m.RoomTypeID, err)
+		}
+
+		restrictions, err := s.queries.GetRoomTypeRestrictionsForRange(ctx, database.GetRoomTypeRestrictionsForRangeParams{
+			HotelID:    c.HotelID,
+			RoomTypeID: m.RoomTypeID,
+			DateFrom:   from,
+			DateTo:     to,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get restrictions of room type %q: %w", m.RoomTypeID, err)
+		}
+
+		stopSell := make(map[string]bool, len(restrictions))
+		for _, r := range restrictions {
+			stopSell[r.Date.Format(dateLayout)] = r.StopSell
+		}
+
+		for _, inv := range inventory {
+			updates = append(updates, ARIUpdate{
+				RoomCode:     m.ExternalRoomCode,
+				RatePlanCode: m.RatePlanCode,
+				Date:         inv.Date,
+				Inventory:    inv.TotalInventory,
+				Available:    max(policies.MaxCapacity(inv)-inv.TotalReserved, 0),
+				Rate:         m.Rate,
+				Currency:     m.Currency,
+				StopSell:     stopSell[inv.Date.Format(dateLayout)],
+			})
+		}
+	}
+
+	if err := adapter.PushARI(ctx, connection(c), updates); err != nil {
+		return fmt.Errorf("failed to push ARI: %w", err)
+	}
+	return nil
+}
+
+func (s *ChannelService) pullReservations(ctx context.Context, c database.BookingChannel) error {
+	adapter, err := s.adapter(c.Adapter)
+	if err != nil {
+		return err
+	}
+
+	bookings, err := adapter.PullReservations(ctx, connection(c))
+	if err != nil {
+		return fmt.Errorf("failed to pull reservations: %w", err)
+	}
+
+	results := make([]BookingResult, 0, len(bookings))
+	for _, b := range bookings {
+		results = append(results, s.importBooking(ctx, c, b))
+	}
+
+	if err := adapter.ConfirmReservations(ctx, connection(c), results); err != nil {
+		return fmt.Errorf("failed to confirm reservations: %w", err)
+	}
+	return nil
+}
+
+// handleNotification imports bookings a channel pushed and returns the reply with its content type.
+func (s *ChannelService) handleNotification(ctx context.Context, channelID uuid.UUID, body []byte) ([]byte, string, error) {
+	c, err := s.queries.GetChannelById(ctx, channelID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !c.Active) {
+		return nil, "", ErrChannelNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get channel %q: %w", channelID, err)
+	}
+
+	adapter, err := s.adapter(c.Adapter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	notification, err := adapter.ParseNotification(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(notification.APIKey), []byte(c.ApiKey)) != 1 {
+		return nil, "", ErrUnauthorized
+	}
+
+	results := make([]BookingResult, 0, len(notification.Bookings))
+	for _, b := range notification.Bookings {
+		results = append(results, s.importBooking(ctx, c, b))
+	}
+
+	return adapter.NotificationResponse(results)
+}
+
+// importBooking applies a booking or cancellation from the channel. Bookings are keyed by the channel's
+// reference, so a redelivered booking reports the reservation it created the first time.
+func (s *ChannelService) importBooking(ctx context.Context, c database.BookingChannel, b Booking) BookingResult {
+	result := BookingResult{ExternalID: b.ExternalID}
+	if b.Err != nil {
+		result.Error = b.Err.Error()
+		return result
+	}
+	if b.ExternalID == "" {
+		result.Error = "missing booking reference"
+		return result
+	}
+
+	existing, err := s.queries.GetChannelReservation(ctx, database.GetChannelReservationParams{
+		ChannelID:  c.ID,
+		ExternalID: b.ExternalID,
+	})
+	found := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.ErrorContext(ctx, "Failed to look up channel booking", "external_id", b.ExternalID, "channel_id", c.ID, "error", err)
+		result.Error = "temporary failure, retry later"
+		return result
+	}
+
+	switch b.Status {
+	case BookingStatusBook:
+		if found {
+			result.ReservationID = existing.ReservationID
+			return result
+		}
+		result.ReservationID, err = s.importReservation(ctx, c, b)
+	case BookingStatusCancel:
+		if !found {
+			result.Error = "unknown booking reference"
+			return result
+		}
+		result.ReservationID = existing.ReservationID
+		err = s.importCancellation(ctx, existing.ReservationID)
+	default:
+		err = fmt.Errorf("unsupported booking status %q", b.Status)
+	}
+
+	if err != nil {
+		result.Error = bookingError(c, b, err)
+	}
+	return result
+}
+
+func bookingError(c database.BookingChannel, b Booking, err error) string {
+	switch {
+	case errors.Is(err, ErrMappingNotFound),
+		errors.Is(err, ErrInvalidMessage),
+		errors.Is(err, reservation.ErrInventoryCapacityReached),
+		errors.Is(err, reservation.ErrInventoryNotFound),
+		errors.Is(err, reservation.ErrStayRestricted),
+		errors.Is(err, reservation.ErrReservationNotCancellable),
+		errors.Is(err, reservation.ErrReservationNotFound):
+		return err.Error()
+	default:
+		slog.Error("Failed to import channel booking", "external_id", b.ExternalID, "channel_id", c.ID, "error", err)
+		return "temporary failure, retry later"
+	}
+}
+
+func (s *ChannelService) importReservation(ctx context.Context, c database.BookingChannel, b Booking) (uuid.UUID, error) {
+	mappings, err := s.queries.ListChannelRoomMappings(ctx, c.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to list room mappings for channel %q: %w", c.ID, err)
+	}
+
+	var mapping *database.BookingChannelRoomMapping
+	for i := range mappings {
+		if mappings[i].ExternalRoomCode == b.RoomCode {
+			mapping = &mappings[i]
+			break
+		}
+	}
+	if mapping == nil {
+		return uuid.Nil, fmt.Errorf("%w: room code %q", ErrMappingNotFound, b.RoomCode)
+	}
+
+	if !b.CheckOut.After(b.CheckIn) {
+		return uuid.Nil, fmt.Errorf("%w: check-out must be after check-in", ErrInvalidMessage)
+	}
+	if b.Guest.Email == "" {
+		return uuid.Nil, fmt.Errorf("%w: guest email is required", ErrInvalidMessage)
+	}
+
+	reservationID := uuid.New()
+	err = retryOnLockMismatch(func() error {
+		return s.reserve(ctx, c, *mapping, b, reservationID)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return reservationID, nil
+}
+
+// retryOnLockMismatch reruns fn when a concurrent change bumped the inventory version it read.
+func retryOnLockMismatch(fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxImportAttempts; attempt++ {
+		err = fn()
+		if !errors.Is(err, reservation.ErrOptimisticLockMismatch) {
+			return err
+		}
+	}
+	return err
+}
+
+// reserve takes inventory through the same path as reservations made through the API and links the
+// reservation to the channel's reference in one transaction.
+func (s *ChannelService) reserve(ctx context.Context, c database.BookingChannel, m database.BookingChannelRoomMapping, b Booking, reservationID uuid.UUID) error {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start channel reservation transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	guest, err := qtx.UpsertGuestByEmail(ctx, database.UpsertGuestByEmailParams{
+		ID:        uuid.New(),
+		FirstName: b.Guest.FirstName,
+		LastName:  b.Guest.LastName,
+		Email:     b.Guest.Email,
+		Locale:    "en",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert guest %q: %w", b.Guest.Email, err)
+	}
+
+	err = s.reservations.ReserveExternal(ctx, qtx, database.InsertReservationParams{
+		ID:         reservationID,
+		HotelID:    c.HotelID,
+		RoomTypeID: m.RoomTypeID,
+		StartDate:  b.CheckIn,
+		// Channels send the departure day, reservations store the last night.
+		EndDate: b.CheckOut.AddDate(0, 0, -1),
+		GuestID: guest.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := qtx.InsertChannelReservation(ctx, database.InsertChannelReservationParams{
+		ChannelID:     c.ID,
+		ExternalID:    b.ExternalID,
+		ReservationID: reservationID,
+	}); err != nil {
+		return fmt.Errorf("failed to link reservation %q to channel %q: %w", reservationID, c.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	metrics.ReservationsCreated.Inc()
+	return nil
+}
+
+// importCancellation cancels the reservation, the waitlist is offered its rooms once the cancellation is relayed.
+func (s *ChannelService) importCancellation(ctx context.Context, reservationID uuid.UUID) error {
+	return retryOnLockMismatch(func() error {
+		return s.cancel(ctx, reservationID)
+	})
+}
+
+func (s *ChannelService) cancel(ctx context.Context, reservationID uuid.UUID) error {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start channel cancellation transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	cancelled, err := s.reservations.CancelExternal(ctx, s.queries.WithTx(tx), reservationID)
+	// Channels resend cancellations until confirmed, one that was already applied is a success.
+	if errors.Is(err, reservation.ErrReservationNotCancellable) && cancelled.Status == string(reservation.ReservationStatusCancelled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cancellation of reservation %q: %w", reservationID, err)
+	}
+	return nil
+}
@@ -1,51 +1,113 @@
+// Package config loads the settings shared by the API, the crons and hotelctl. Each setting is read, from
+// lowest to highest precedence, from its default, the YAML file given by -config or CONFIG_FILE, its
+// environment variable, a file named after its secret in the directory given by -secrets-dir or SECRETS_DIR,
+// and its flag.
 package config
 
 import (
-	"log"
-	"os"
-
-	"github.com/go-playground/validator/v10"
+	"time"
 )
 
 type Config struct {
-	JWTSecret          string `validate:"required"`
-	PORT               string `validate:"required,numeric"`
-	DB_HOST            string `validate:"required"`
-	DB_PORT            string `validate:"required,numeric"`
-	DB_DATABASE        string `validate:"required"`
-	DB_USERNAME        string `validate:"required"`
-	DB_PASSWORD        string `validate:"required"`
-	DB_SCHEMA          string `validate:"required"`
-	DB_SSLMODE         string `validate:"required"`
-	OVERBOOKING_FACTOR string `validate:"required,numeric,min=1"`
-}
-
-var config *Config
-
-func GetConfig(validator *validator.Validate) *Config {
-	if config == nil {
-		config = loadAndValidateConfig(validator)
-	}
-	return config
-}
-
-func loadAndValidateConfig(validator *validator.Validate) *Config {
-	cfg := &Config{
-		JWTSecret:          os.Getenv("JWT_SECRET"),
-		PORT:               os.Getenv("PORT"),
-		DB_HOST:            os.Getenv("DB_HOST"),
-		DB_PORT:            os.Getenv("DB_PORT"),
-		DB_DATABASE:        os.Getenv("DB_DATABASE"),
-		DB_USERNAME:        os.Getenv("DB_USERNAME"),
-		DB_PASSWORD:        os.Getenv("DB_PASSWORD"),
-		DB_SCHEMA:          os.Getenv("DB_SCHEMA"),
-		DB_SSLMODE:         os.Getenv("DB_SSLMODE"),
-		OVERBOOKING_FACTOR: os.Getenv("OVERBOOKING_FACTOR"),
-	}
-
-	if err := validator.Struct(cfg); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
-	}
-
-	return cfg
+	// Deployment the process runs in, logs are text by default in "development" and "local".
+	Env      string   `yaml:"env" env:"APP_ENV"`
+	Server   Server   `yaml:"server"`
+	DB       DB       `yaml:"db"`
+	Auth     Auth     `yaml:"auth"`
+	Booking  Booking  `yaml:"booking"`
+	Events   Events   `yaml:"events"`
+	Webhooks Webhooks `yaml:"webhooks"`
+	Mail     Mail     `yaml:"mail"`
+	Jobs     Jobs     `yaml:"jobs"`
+	Log      Log      `yaml:"log"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Health   Health   `yaml:"health"`
+}
+
+type Server struct {
+	Port int `yaml:"port" env:"PORT" validate:"min=1,max=65535"`
+	// Serve the API at its unversioned root paths next to /v1, with deprecation headers.
+	LegacyRoutes bool `yaml:"legacy_routes" env:"LEGACY_ROUTES" default:"true"`
+	// Date the unversioned paths start answering 410 Gone, zero while not scheduled.
+	LegacyRoutesSunset time.Time `yaml:"legacy_routes_sunset" env:"LEGACY_ROUTES_SUNSET"`
+	// How long the server keeps serving after SIGTERM with readiness failing, so probes take it out of rotation.
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY" default:"5s"`
+}
+
+type DB struct {
+	Host     string `yaml:"host" env:"DB_HOST" validate:"required"`
+	Port     int    `yaml:"port" env:"DB_PORT" validate:"min=1,max=65535"`
+	Database string `yaml:"database" env:"DB_DATABASE" validate:"required"`
+	Username string `yaml:"username" env:"DB_USERNAME" secret:"psql-username" validate:"required"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"psql-password" validate:"required"`
+	Schema   string `yaml:"schema" env:"DB_SCHEMA" validate:"required"`
+	SSLMode  string `yaml:"sslmode" env:"DB_SSLMODE" validate:"required"`
+	// Apply pending migrations on startup instead of refusing to start.
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	// Connection pool of database/sql, zero lifts the limit.
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25" validate:"gte=0"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10" validate:"gte=0"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+	// Postgres cancels statements running longer, zero disables the limit.
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// Connections of the pgx pool the inventory cron sends its batches through, the event listener takes one
+	// more of its own.
+	BatchMaxConns int `yaml:"batch_max_conns" env:"DB_BATCH_MAX_CONNS" default:"4" validate:"gte=0"`
+}
+
+type Auth struct {
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"jwt-secret" validate:"required"`
+}
+
+type Booking struct {
+	// Global fallback used when no overbooking policy is stored in the database.
+	OverbookingFactor float64 `yaml:"overbooking_factor" env:"OVERBOOKING_FACTOR" default:"1" validate:"gte=1"`
+	// Longest stay the availability calendar summarizes, every night of it is checked per room type.
+	MaxStayNights int `yaml:"max_stay_nights" env:"MAX_STAY_NIGHTS" default:"365" validate:"gte=1"`
+}
+
+type Events struct {
+	// How the outbox relay learns about new events: "poll" checks every second, "notify" is also woken by
+	// LISTEN/NOTIFY as soon as they commit. Events are published to in-process subscribers either way.
+	Wake string `yaml:"wake" env:"EVENT_WAKE" default:"poll" validate:"oneof=poll notify"`
+}
+
+type Webhooks struct {
+	// Deliver to loopback, private and link-local addresses, which otherwise reach this network instead of a
+	// subscriber. Also lets channel endpoints use them. Only meant for local development and tests.
+	AllowPrivateTargets bool `yaml:"allow_private_targets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
+}
+
+type Mail struct {
+	// Guest email transport: "file" writes .eml files into OutboxDir, "smtp" sends through SMTPHost.
+	Sender       string `yaml:"sender" env:"NOTIFICATION_SENDER" default:"file" validate:"oneof=file smtp"`
+	From         string `yaml:"from" env:"MAIL_FROM" default:"reservations@hotel-system.local" validate:"required"`
+	OutboxDir    string `yaml:"outbox_dir" env:"MAIL_OUTBOX_DIR" default:"tmp/mail" validate:"required_if=Sender file"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST" validate:"required_if=Sender smtp"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT" default:"587" validate:"min=1,max=65535"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD" secret:"smtp-password"`
+}
+
+type Jobs struct {
+	// Pushgateway the inventory cron sends its run gauges to, not pushed when empty.
+	PushgatewayURL string `yaml:"pushgateway_url" env:"PUSHGATEWAY_URL" validate:"omitempty,url"`
+}
+
+type Log struct {
+	// "json" in production, "text" by default when Env is development or local.
+	Format string `yaml:"format" env:"LOG_FORMAT" validate:"oneof=json text"`
+	Level  string `yaml:"level" env:"LOG_LEVEL" default:"info" validate:"oneof=debug info warn error"`
+}
+
+type Tracing struct {
+	// Where spans go: "none", "stdout", or "otlp" configured through the standard OTEL_EXPORTER_OTLP_* variables.
+	Exporter string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER" default:"none" validate:"oneof=none stdout otlp"`
+}
+
+type Health struct {
+	// Readiness reports the outbox and the inventory cron as failing past these ages, without failing on them.
+	OutboxMaxAge    time.Duration `yaml:"outbox_max_age" env:"HEALTH_OUTBOX_MAX_AGE" default:"5m"`
+	InventoryMaxAge time.Duration `yaml:"inventory_max_age" env:"HEALTH_INVENTORY_MAX_AGE" default:"36h"`
 }
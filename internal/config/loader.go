@@ -0,0 +1,251 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Loader reads the configuration from its sources, see the package documentation for their precedence.
+type Loader struct {
+	flags      *flag.FlagSet
+	file       string
+	secretsDir string
+}
+
+// NewLoader registers -config, -secrets-dir and a flag per setting on flags, named after its path in the
+// file such as -db.max-open-conns. Load reads them once flags is parsed. With nil flags only the file, the
+// environment and the secrets are read.
+func NewLoader(flags *flag.FlagSet) *Loader {
+	l := &Loader{flags: flags}
+	if flags == nil {
+		return l
+	}
+
+	flags.StringVar(&l.file, "config", "", "YAML configuration file, CONFIG_FILE when empty")
+	flags.StringVar(&l.secretsDir, "secrets-dir", "", "directory of mounted secret files, SECRETS_DIR when empty")
+	for _, s := range settings(&Config{}) {
+		usage := "env " + s.env
+		if s.secret != "" {
+			usage += ", secret " + s.secret
+		}
+		flags.String(s.flag(), "", usage)
+	}
+	return l
+}
+
+// Load reads the configuration and validates it. Every setting is checked, the error lists each one that's
+// invalid.
+func (l *Loader) Load(validate *validator.Validate) (*Config, error) {
+	cfg := &Config{}
+	all := settings(cfg)
+
+	var errs []error
+	for _, s := range all {
+		if s.def == "" {
+			continue
+		}
+		if err := s.set(s.def); err != nil {
+			errs = append(errs, fmt.Errorf("default of %s: %w", s.path, err))
+		}
+	}
+
+	if file := firstNonEmpty(l.file, os.Getenv("CONFIG_FILE")); file != "" {
+		if err := decodeFile(file, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range all {
+		if s.env == "" {
+			continue
+		}
+		if raw := os.Getenv(s.env); raw != "" {
+			if err := s.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+
+	if dir := firstNonEmpty(l.secretsDir, os.Getenv("SECRETS_DIR")); dir != "" {
+		for _, s := range all {
+			if s.secret == "" {
+				continue
+			}
+			raw, err := os.ReadFile(filepath.Join(dir, s.secret))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("secret %s: %w", s.secret, err))
+				continue
+			}
+			// Secret stores and editors add a trailing newline that isn't part of the value.
+			if err := s.set(strings.TrimRight(string(raw), "\r\n")); err != nil {
+				errs = append(errs, fmt.Errorf("secret %s: %w", s.secret, err))
+			}
+		}
+	}
+
+	if l.flags != nil {
+		byFlag := make(map[string]setting, len(all))
+		for _, s := range all {
+			byFlag[s.flag()] = s
+		}
+		l.flags.Visit(func(f *flag.Flag) {
+			s, ok := byFlag[f.Name]
+			if !ok {
+				return
+			}
+			if err := s.set(f.Value.String()); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", f.Name, err))
+			}
+		})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if cfg.Log.Format == "" {
+		cfg.Log.Format = "json"
+		if cfg.Env == "development" || cfg.Env == "local" {
+			cfg.Log.Format = "text"
+		}
+	}
+
+	if err := validate.Struct(cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// decodeFile reads the YAML file at path into cfg, keeping the values of the keys it doesn't set.
+func decodeFile(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	// A misspelled key would otherwise be ignored and leave its setting at the default.
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return nil
+}
+
+// setting is a leaf of the configuration and where it's read from.
+type setting struct {
+	path   string
+	env    string
+	secret string
+	def    string
+	value  reflect.Value
+}
+
+func (s setting) flag() string {
+	return strings.ReplaceAll(s.path, "_", "-")
+}
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	timeType     = reflect.TypeFor[time.Time]()
+)
+
+// set parses raw into the setting the way its environment variable is written.
+func (s setting) set(raw string) error {
+	switch s.value.Type() {
+	case durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return err
+		}
+		s.value.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		s.value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
+
+// settings lists the leaves of cfg, in declaration order.
+func settings(cfg *Config) []setting {
+	var out []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			path := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+
+			if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+				walk(v.Field(i), path)
+				continue
+			}
+			out = append(out, setting{
+				path:   path,
+				env:    field.Tag.Get("env"),
+				secret: field.Tag.Get("secret"),
+				def:    field.Tag.Get("default"),
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
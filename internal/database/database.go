@@ -5,14 +5,17 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
 	"log/slog"
+	"net"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
 )
 
 // Service represents a service that interacts with a database.
@@ -26,35 +29,90 @@ type Service interface {
 	Close() error
 
 	GetDB() *sql.DB
+
+	// Pool returns the pgx-native pool for work database/sql can't express, batches and COPY. Queries that
+	// must run in a transaction with the sqlc ones stay on GetDB.
+	Pool() *pgxpool.Pool
 }
 
 var ErrUserNotFound = errors.New("user not found")
 
 type service struct {
-	db *sql.DB
+	db   *sql.DB
+	pool *pgxpool.Pool
 }
 
 func Create(cfg *config.Config) (Service, error) {
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s&application_name=hotel-system",
-		cfg.DB_USERNAME, cfg.DB_PASSWORD, cfg.DB_HOST, cfg.DB_PORT, cfg.DB_DATABASE, cfg.DB_SSLMODE)
-
-	db, err := sql.Open("pgx", connStr)
+	query := url.Values{}
+	query.Set("sslmode", cfg.DB.SSLMode)
+	query.Set("application_name", "hotel-system")
+	// Unknown parameters are sent as runtime parameters, which sets the timeout on every connection of both pools.
+	if cfg.DB.StatementTimeout > 0 {
+		query.Set("statement_timeout", strconv.FormatInt(cfg.DB.StatementTimeout.Milliseconds(), 10))
+	}
+	// Built as a URL, so credentials and a database name with reserved characters are escaped.
+	connStr := (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.DB.Username, cfg.DB.Password),
+		Host:     net.JoinHostPort(cfg.DB.Host, strconv.Itoa(cfg.DB.Port)),
+		Path:     "/" + cfg.DB.Database,
+		RawQuery: query.Encode(),
+	}).String()
+
+	db, err := otelsql.Open("pgx", connStr, otelsql.WithDBSystem("postgresql"), otelsql.WithDBName(cfg.DB.Database))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	pool, err := newPool(ctx, cfg, connStr)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return &service{
-		db: db,
+		db:   db,
+		pool: pool,
 	}, nil
 }
 
+// newPool creates the pgx pool. It connects on first use, processes that never send a batch don't hold
+// connections.
+func newPool(ctx context.Context, cfg *config.Config, connStr string) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pool config: %w", err)
+	}
+	if cfg.DB.BatchMaxConns > 0 {
+		poolCfg.MaxConns = int32(cfg.DB.BatchMaxConns)
+	}
+	if cfg.DB.ConnMaxLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.DB.ConnMaxLifetime
+	}
+	if cfg.DB.ConnMaxIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.DB.ConnMaxIdleTime
+	}
+	poolCfg.ConnConfig.Tracer = poolTracer{dbName: cfg.DB.Database}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool: %w", err)
+	}
+	return pool, nil
+}
+
 // Health checks the health of the database connection by pinging the database.
 // It returns a map with keys indicating various health statistics.
 func (s *service) Health() map[string]string {
@@ -79,6 +137,7 @@ func (s *service) Health() map[string]string {
 	// Get database stats (like open connections, in use, idle, etc.)
 	dbStats := s.db.Stats()
 	stats["open_connections"] = strconv.Itoa(dbStats.OpenConnections)
+	stats["max_open_connections"] = strconv.Itoa(dbStats.MaxOpenConnections)
 	stats["in_use"] = strconv.Itoa(dbStats.InUse)
 	stats["idle"] = strconv.Itoa(dbStats.Idle)
 	stats["wait_count"] = strconv.FormatInt(dbStats.WaitCount, 10)
@@ -86,13 +145,13 @@ func (s *service) Health() map[string]string {
 	stats["max_idle_closed"] = strconv.FormatInt(dbStats.MaxIdleClosed, 10)
 	stats["max_lifetime_closed"] = strconv.FormatInt(dbStats.MaxLifetimeClosed, 10)
 
-	// Evaluate stats to provide a health message
-	if dbStats.OpenConnections > 40 { // Assuming 50 is the max for this example
+	// Evaluate stats against the pool limit to provide a health message
+	if dbStats.MaxOpenConnections > 0 && dbStats.OpenConnections*5 >= dbStats.MaxOpenConnections*4 {
 		stats["message"] = "The database is experiencing heavy load."
 	}
 
-	if dbStats.WaitCount > 1000 {
-		stats["message"] = "The database has a high number of wait events, indicating potential bottlenecks."
+	if dbStats.MaxOpenConnections > 0 && dbStats.InUse == dbStats.MaxOpenConnections && dbStats.WaitCount > 0 {
+		stats["message"] = "The connection pool is exhausted, queries are waiting for connections."
 	}
 
 	if dbStats.MaxIdleClosed > int64(dbStats.OpenConnections)/2 {
@@ -111,10 +170,15 @@ func (s *service) Health() map[string]string {
 // If the connection is successfully closed, it returns nil.
 // If an error occurs while closing the connection, it returns the error.
 func (s *service) Close() error {
-	log.Printf("Disconnecting from database")
+	slog.Info("Disconnecting from database")
+	s.pool.Close()
 	return s.db.Close()
 }
 
 func (s *service) GetDB() *sql.DB {
 	return s.db
 }
+
+func (s *service) Pool() *pgxpool.Pool {
+	return s.pool
+}
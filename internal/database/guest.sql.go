@@ -12,9 +12,9 @@ import (
 )
 
 const insertGuest = `-- name: InsertGuest :one
-INSERT INTO booking.guests (id, first_name, last_name, email)
-VALUES ($1, $2, $3, $4)
-RETURNING id, first_name, last_name, email
+INSERT INTO booking.guests (id, first_name, last_name, email, locale)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, first_name, last_name, email, locale
 `
 
 type InsertGuestParams struct {
@@ -22,6 +22,7 @@ type InsertGuestParams struct {
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	Email     string    `json:"email"`
+	Locale    string    `json:"locale"`
 }
 
 func (q *Queries) InsertGuest(ctx context.Context, arg InsertGuestParams) (BookingGuest, error) {
@@ -30,6 +31,7 @@ func (q *Queries) InsertGuest(ctx context.Context, arg InsertGuestParams) (Booki
 		arg.FirstName,
 		arg.LastName,
 		arg.Email,
+		arg.Locale,
 	)
 	var i BookingGuest
 	err := row.Scan(
@@ -37,6 +39,43 @@ func (q *Queries) InsertGuest(ctx context.Context, arg InsertGuestParams) (Booki
 		&i.FirstName,
 		&i.LastName,
 		&i.Email,
+		&i.Locale,
+	)
+	return i, err
+}
+
+const upsertGuestByEmail = `-- name: UpsertGuestByEmail :one
+INSERT INTO booking.guests (id, first_name, last_name, email, locale)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (email) DO UPDATE
+SET email = EXCLUDED.email
+RETURNING id, first_name, last_name, email, locale
+`
+
+type UpsertGuestByEmailParams struct {
+	ID        uuid.UUID `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	Locale    string    `json:"locale"`
+}
+
+// Guests booking through a channel are matched by email, their stored details are left untouched.
+func (q *Queries) UpsertGuestByEmail(ctx context.Context, arg UpsertGuestByEmailParams) (BookingGuest, error) {
+	row := q.db.QueryRowContext(ctx, upsertGuestByEmail,
+		arg.ID,
+		arg.FirstName,
+		arg.LastName,
+		arg.Email,
+		arg.Locale,
+	)
+	var i BookingGuest
+	err := row.Scan(
+		&i.ID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.Locale,
 	)
 	return i, err
 }
@@ -14,21 +14,27 @@ import (
 
 const createHotel = `-- name: CreateHotel :one
 INSERT INTO
-	booking.hotels (id, name, location)
+	booking.hotels (id, name, location, time_zone)
 VALUES
-	($1, $2, $3)
+	($1, $2, $3, $4)
 RETURNING
-	id, name, location, created_at, updated_at, deleted_at, is_active
+	id, name, location, created_at, updated_at, deleted_at, is_active, time_zone
 `
 
 type CreateHotelParams struct {
 	ID       uuid.UUID `json:"id"`
 	Name     string    `json:"name"`
 	Location string    `json:"location"`
+	TimeZone string    `json:"time_zone"`
 }
 
 func (q *Queries) CreateHotel(ctx context.Context, arg CreateHotelParams) (BookingHotel, error) {
-	row := q.db.QueryRowContext(ctx, createHotel, arg.ID, arg.Name, arg.Location)
+	row := q.db.QueryRowContext(ctx, createHotel,
+		arg.ID,
+		arg.Name,
+		arg.Location,
+		arg.TimeZone,
+	)
 	var i BookingHotel
 	err := row.Scan(
 		&i.ID,
@@ -38,6 +44,7 @@ func (q *Queries) CreateHotel(ctx context.Context, arg CreateHotelParams) (Booki
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.IsActive,
+		&i.TimeZone,
 	)
 	return i, err
 }
@@ -187,7 +194,7 @@ func (q *Queries) FindRoomTypesByHotelIdAndName(ctx context.Context, arg FindRoo
 
 const getActiveHotels = `-- name: GetActiveHotels :many
 SELECT
-	id, name, location, created_at, updated_at, deleted_at, is_active
+	id, name, location, created_at, updated_at, deleted_at, is_active, time_zone
 FROM
 	booking.hotels
 WHERE
@@ -211,6 +218,7 @@ func (q *Queries) GetActiveHotels(ctx context.Context) ([]BookingHotel, error) {
 			&i.UpdatedAt,
 			&i.DeletedAt,
 			&i.IsActive,
+			&i.TimeZone,
 		); err != nil {
 			return nil, err
 		}
@@ -227,7 +235,7 @@ func (q *Queries) GetActiveHotels(ctx context.Context) ([]BookingHotel, error) {
 
 const getHotelById = `-- name: GetHotelById :one
 SELECT
-	id, name, location, created_at, updated_at, deleted_at, is_active
+	id, name, location, created_at, updated_at, deleted_at, is_active, time_zone
 FROM
 	booking.hotels
 WHERE
@@ -245,13 +253,14 @@ func (q *Queries) GetHotelById(ctx context.Context, id uuid.UUID) (BookingHotel,
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.IsActive,
+		&i.TimeZone,
 	)
 	return i, err
 }
 
 const getHotelByName = `-- name: GetHotelByName :one
 SELECT
-	id, name, location, created_at, updated_at, deleted_at, is_active
+	id, name, location, created_at, updated_at, deleted_at, is_active, time_zone
 FROM
 	booking.hotels
 WHERE
@@ -269,10 +278,52 @@ func (q *Queries) GetHotelByName(ctx context.Context, name string) (BookingHotel
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.IsActive,
+		&i.TimeZone,
 	)
 	return i, err
 }
 
+const getHotelRoomTypes = `-- name: GetHotelRoomTypes :many
+SELECT
+	id, hotel_id, name, description, created_at, updated_at
+FROM
+	booking.room_types
+WHERE
+	hotel_id = $1
+ORDER BY
+	name
+`
+
+func (q *Queries) GetHotelRoomTypes(ctx context.Context, hotelID uuid.UUID) ([]BookingRoomType, error) {
+	rows, err := q.db.QueryContext(ctx, getHotelRoomTypes, hotelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingRoomType
+	for rows.Next() {
+		var i BookingRoomType
+		if err := rows.Scan(
+			&i.ID,
+			&i.HotelID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getHotelUniqueRoomTypes = `-- name: GetHotelUniqueRoomTypes :many
 SELECT DISTINCT
 	id
@@ -339,21 +390,28 @@ UPDATE booking.hotels
 SET
 	name = $2,
 	location = $3,
+	time_zone = COALESCE(NULLIF($4::text, ''), time_zone),
 	updated_at = CURRENT_TIMESTAMP
 WHERE
 	id = $1
 RETURNING
-	id, name, location, created_at, updated_at, deleted_at, is_active
+	id, name, location, created_at, updated_at, deleted_at, is_active, time_zone
 `
 
 type UpdateHotelParams struct {
 	ID       uuid.UUID `json:"id"`
 	Name     string    `json:"name"`
 	Location string    `json:"location"`
+	TimeZone string    `json:"time_zone"`
 }
 
 func (q *Queries) UpdateHotel(ctx context.Context, arg UpdateHotelParams) (BookingHotel, error) {
-	row := q.db.QueryRowContext(ctx, updateHotel, arg.ID, arg.Name, arg.Location)
+	row := q.db.QueryRowContext(ctx, updateHotel,
+		arg.ID,
+		arg.Name,
+		arg.Location,
+		arg.TimeZone,
+	)
 	var i BookingHotel
 	err := row.Scan(
 		&i.ID,
@@ -363,6 +421,7 @@ func (q *Queries) UpdateHotel(ctx context.Context, arg UpdateHotelParams) (Booki
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.IsActive,
+		&i.TimeZone,
 	)
 	return i, err
 }
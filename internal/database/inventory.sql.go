@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 )
 
 const batchUpdateRoomTypeInventory = `-- name: BatchUpdateRoomTypeInventory :execrows
@@ -49,7 +48,7 @@ func (q *Queries) BatchUpdateRoomTypeInventory(ctx context.Context, arg BatchUpd
 	result, err := q.db.ExecContext(ctx, batchUpdateRoomTypeInventory,
 		arg.HotelID,
 		arg.RoomTypeID,
-		pq.Array(arg.Dates),
+		arg.Dates,
 		arg.TotalInventory,
 	)
 	if err != nil {
@@ -58,6 +57,57 @@ func (q *Queries) BatchUpdateRoomTypeInventory(ctx context.Context, arg BatchUpd
 	return result.RowsAffected()
 }
 
+const getHotelInventoryForDates = `-- name: GetHotelInventoryForDates :many
+SELECT
+	hotel_id, room_type_id, date, updated_at, created_at, version, total_inventory, total_reserved
+FROM
+	booking.room_type_inventory
+WHERE
+	hotel_id = $1
+	AND date BETWEEN $2 AND $3
+ORDER BY
+	room_type_id,
+	date
+`
+
+type GetHotelInventoryForDatesParams struct {
+	HotelID  uuid.UUID `json:"hotel_id"`
+	DateFrom time.Time `json:"date_from"`
+	DateTo   time.Time `json:"date_to"`
+}
+
+func (q *Queries) GetHotelInventoryForDates(ctx context.Context, arg GetHotelInventoryForDatesParams) ([]BookingRoomTypeInventory, error) {
+	rows, err := q.db.QueryContext(ctx, getHotelInventoryForDates, arg.HotelID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingRoomTypeInventory
+	for rows.Next() {
+		var i BookingRoomTypeInventory
+		if err := rows.Scan(
+			&i.HotelID,
+			&i.RoomTypeID,
+			&i.Date,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Version,
+			&i.TotalInventory,
+			&i.TotalReserved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getHotelInventoryForRange = `-- name: GetHotelInventoryForRange :many
 SELECT
 	hotel_id, room_type_id, date, updated_at, created_at, version, total_inventory, total_reserved
@@ -113,26 +163,99 @@ func (q *Queries) GetHotelInventoryForRange(ctx context.Context, arg GetHotelInv
 	return items, nil
 }
 
+const getOverbookedNights = `-- name: GetOverbookedNights :many
+SELECT
+	rti.room_type_id,
+	rt.name as room_type_name,
+	rti.date,
+	rti.total_inventory,
+	rti.total_reserved,
+	(rti.total_reserved - rti.total_inventory) as overbooked
+FROM
+	booking.room_type_inventory rti
+	INNER JOIN booking.room_types rt ON rti.room_type_id = rt.id
+WHERE
+	rti.hotel_id = $1
+	AND rti.date BETWEEN $2 AND $3
+	AND rti.total_reserved > rti.total_inventory
+ORDER BY
+	rti.date,
+	rt.name
+`
+
+type GetOverbookedNightsParams struct {
+	HotelID  uuid.UUID `json:"hotel_id"`
+	DateFrom time.Time `json:"date_from"`
+	DateTo   time.Time `json:"date_to"`
+}
+
+type GetOverbookedNightsRow struct {
+	RoomTypeID     uuid.UUID `json:"room_type_id"`
+	RoomTypeName   string    `json:"room_type_name"`
+	Date           time.Time `json:"date"`
+	TotalInventory int32     `json:"total_inventory"`
+	TotalReserved  int32     `json:"total_reserved"`
+	Overbooked     int32     `json:"overbooked"`
+}
+
+func (q *Queries) GetOverbookedNights(ctx context.Context, arg GetOverbookedNightsParams) ([]GetOverbookedNightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOverbookedNights, arg.HotelID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOverbookedNightsRow
+	for rows.Next() {
+		var i GetOverbookedNightsRow
+		if err := rows.Scan(
+			&i.RoomTypeID,
+			&i.RoomTypeName,
+			&i.Date,
+			&i.TotalInventory,
+			&i.TotalReserved,
+			&i.Overbooked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRoomAvailabilityByDates = `-- name: GetRoomAvailabilityByDates :many
 SELECT 
 	rti.room_type_id,
 	rt.name as room_type_name,
 	rt.description,
 	rti.date,
-	(rti.total_inventory - rti.total_reserved) as available_capacity
+	(rti.total_inventory - rti.total_reserved) as available_capacity,
+	rti.total_inventory,
+	rti.total_reserved,
+	rtr.min_length_of_stay,
+	rtr.max_length_of_stay,
+	COALESCE(rtr.closed_to_arrival, FALSE) as closed_to_arrival,
+	COALESCE(rtr.closed_to_departure, FALSE) as closed_to_departure
 FROM booking.room_type_inventory rti
 INNER JOIN booking.room_types rt ON rti.room_type_id = rt.id
+LEFT JOIN booking.room_type_restrictions rtr ON rtr.hotel_id = rti.hotel_id
+	AND rtr.room_type_id = rti.room_type_id
+	AND rtr.date = rti.date
 WHERE rti.hotel_id = $1
 	AND rti.date BETWEEN $2 AND $3
-	AND rti.total_reserved < (($4)::float * rti.total_inventory)
+	AND NOT COALESCE(rtr.stop_sell, FALSE)
 ORDER BY rt.name, rti.date
 `
 
 type GetRoomAvailabilityByDatesParams struct {
-	HotelID     uuid.UUID `json:"hotel_id"`
-	CheckIn     time.Time `json:"check_in"`
-	CheckOut    time.Time `json:"check_out"`
-	Overbooking float64   `json:"overbooking"`
+	HotelID  uuid.UUID `json:"hotel_id"`
+	CheckIn  time.Time `json:"check_in"`
+	CheckOut time.Time `json:"check_out"`
 }
 
 type GetRoomAvailabilityByDatesRow struct {
@@ -141,15 +264,16 @@ type GetRoomAvailabilityByDatesRow struct {
 	Description       sql.NullString `json:"description"`
 	Date              time.Time      `json:"date"`
 	AvailableCapacity int32          `json:"available_capacity"`
+	TotalInventory    int32          `json:"total_inventory"`
+	TotalReserved     int32          `json:"total_reserved"`
+	MinLengthOfStay   sql.NullInt32  `json:"min_length_of_stay"`
+	MaxLengthOfStay   sql.NullInt32  `json:"max_length_of_stay"`
+	ClosedToArrival   bool           `json:"closed_to_arrival"`
+	ClosedToDeparture bool           `json:"closed_to_departure"`
 }
 
 func (q *Queries) GetRoomAvailabilityByDates(ctx context.Context, arg GetRoomAvailabilityByDatesParams) ([]GetRoomAvailabilityByDatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getRoomAvailabilityByDates,
-		arg.HotelID,
-		arg.CheckIn,
-		arg.CheckOut,
-		arg.Overbooking,
-	)
+	rows, err := q.db.QueryContext(ctx, getRoomAvailabilityByDates, arg.HotelID, arg.CheckIn, arg.CheckOut)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +287,12 @@ func (q *Queries) GetRoomAvailabilityByDates(ctx context.Context, arg GetRoomAva
 			&i.Description,
 			&i.Date,
 			&i.AvailableCapacity,
+			&i.TotalInventory,
+			&i.TotalReserved,
+			&i.MinLengthOfStay,
+			&i.MaxLengthOfStay,
+			&i.ClosedToArrival,
+			&i.ClosedToDeparture,
 		); err != nil {
 			return nil, err
 		}
@@ -177,6 +307,253 @@ func (q *Queries) GetRoomAvailabilityByDates(ctx context.Context, arg GetRoomAva
 	return items, nil
 }
 
+const getRoomTypeInventoryDrift = `-- name: GetRoomTypeInventoryDrift :many
+SELECT
+	rti.hotel_id,
+	rti.room_type_id,
+	rti.date,
+	rti.version,
+	rti.total_inventory,
+	rti.total_reserved,
+	COALESCE(r.reserved, 0)::int AS actual_reserved
+FROM
+	booking.room_type_inventory rti
+	LEFT JOIN (
+		SELECT
+			res.room_type_id,
+			d::date AS date,
+			COUNT(*) AS reserved
+		FROM
+			booking.reservations res
+			CROSS JOIN generate_series(res.start_date, res.end_date, interval '1 day') AS d
+		WHERE
+			res.hotel_id = $1
+			AND res.status = ANY ($2::text[])
+			AND res.end_date >= $3
+		GROUP BY
+			res.room_type_id,
+			d::date
+	) r ON r.room_type_id = rti.room_type_id
+	AND r.date = rti.date
+WHERE
+	rti.hotel_id = $1
+	AND rti.date >= $3
+	AND rti.total_reserved <> COALESCE(r.reserved, 0)
+ORDER BY
+	rti.room_type_id,
+	rti.date
+`
+
+type GetRoomTypeInventoryDriftParams struct {
+	HotelID  uuid.UUID `json:"hotel_id"`
+	Statuses []string  `json:"statuses"`
+	DateFrom time.Time `json:"date_from"`
+}
+
+type GetRoomTypeInventoryDriftRow struct {
+	HotelID        uuid.UUID `json:"hotel_id"`
+	RoomTypeID     uuid.UUID `json:"room_type_id"`
+	Date           time.Time `json:"date"`
+	Version        int64     `json:"version"`
+	TotalInventory int32     `json:"total_inventory"`
+	TotalReserved  int32     `json:"total_reserved"`
+	ActualReserved int32     `json:"actual_reserved"`
+}
+
+// Counts reserved rooms from the reservations and returns the dates where total_reserved disagrees.
+// Both come from the same snapshot, so the version can guard a repair against concurrent bookings.
+func (q *Queries) GetRoomTypeInventoryDrift(ctx context.Context, arg GetRoomTypeInventoryDriftParams) ([]GetRoomTypeInventoryDriftRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomTypeInventoryDrift, arg.HotelID, arg.Statuses, arg.DateFrom)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoomTypeInventoryDriftRow
+	for rows.Next() {
+		var i GetRoomTypeInventoryDriftRow
+		if err := rows.Scan(
+			&i.HotelID,
+			&i.RoomTypeID,
+			&i.Date,
+			&i.Version,
+			&i.TotalInventory,
+			&i.TotalReserved,
+			&i.ActualReserved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomTypeInventoryForDates = `-- name: GetRoomTypeInventoryForDates :many
+SELECT
+	hotel_id, room_type_id, date, updated_at, created_at, version, total_inventory, total_reserved
+FROM
+	booking.room_type_inventory
+WHERE
+	hotel_id = $1
+	AND room_type_id = $2
+	AND date = ANY ($3::date[])
+ORDER BY
+	date
+`
+
+type GetRoomTypeInventoryForDatesParams struct {
+	HotelID    uuid.UUID   `json:"hotel_id"`
+	RoomTypeID uuid.UUID   `json:"room_type_id"`
+	Dates      []time.Time `json:"dates"`
+}
+
+func (q *Queries) GetRoomTypeInventoryForDates(ctx context.Context, arg GetRoomTypeInventoryForDatesParams) ([]BookingRoomTypeInventory, error) {
+	rows, err := q.db.QueryContext(ctx, getRoomTypeInventoryForDates, arg.HotelID, arg.RoomTypeID, arg.Dates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookingRoomTypeInventory
+	for rows.Next() {
+		var i BookingRoomTypeInventory
+		if err := rows.Scan(
+			&i.HotelID,
+			&i.RoomTypeID,
+			&i.Date,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.Version,
+			&i.TotalInventory,
+			&i.TotalReserved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertRoomTypeInventoryForDate = `-- name: InsertRoomTypeInventoryForDate :execrows
+INSERT INTO
+	booking.room_type_inventory (
+		hotel_id,
+		room_type_id,
+		date,
+		total_inventory,
+		total_reserved
+	)
+VALUES
+	($1, $2, $3, $4, 0)
+ON CONFLICT (hotel_id, room_type_id, date) DO NOTHING
+`
+
+type InsertRoomTypeInventoryForDateParams struct {
+	HotelID        uuid.UUID `json:"hotel_id"`
+	RoomTypeID     uuid.UUID `json:"room_type_id"`
+	Date           time.Time `json:"date"`
+	TotalInventory int32     `json:"total_inventory"`
+}
+
+func (q *Queries) InsertRoomTypeInventoryForDate(ctx context.Context, arg InsertRoomTypeInventoryForDateParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertRoomTypeInventoryForDate,
+		arg.HotelID,
+		arg.RoomTypeID,
+		arg.Date,
+		arg.TotalInventory,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const repairRoomTypeInventoryReserved = `-- name: RepairRoomTypeInventoryReserved :execrows
+UPDATE booking.room_type_inventory
+SET
+	total_reserved = $1,
+	version = version + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	hotel_id = $2
+	AND room_type_id = $3
+	AND date = $4
+	AND version = $5
+`
+
+type RepairRoomTypeInventoryReservedParams struct {
+	TotalReserved int32     `json:"total_reserved"`
+	HotelID       uuid.UUID `json:"hotel_id"`
+	RoomTypeID    uuid.UUID `json:"room_type_id"`
+	Date          time.Time `json:"date"`
+	Version       int64     `json:"version"`
+}
+
+func (q *Queries) RepairRoomTypeInventoryReserved(ctx context.Context, arg RepairRoomTypeInventoryReservedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, repairRoomTypeInventoryReserved,
+		arg.TotalReserved,
+		arg.HotelID,
+		arg.RoomTypeID,
+		arg.Date,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setRoomTypeInventoryTotal = `-- name: SetRoomTypeInventoryTotal :execrows
+UPDATE booking.room_type_inventory
+SET
+	total_inventory = $1,
+	version = version + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	hotel_id = $2
+	AND room_type_id = $3
+	AND date = $4
+	AND version = $5
+	-- Overbooked nights may keep or raise their count, only a cut below the reservations is refused.
+	AND (
+		$1 >= total_inventory
+		OR $1 >= total_reserved
+	)
+`
+
+type SetRoomTypeInventoryTotalParams struct {
+	TotalInventory int32     `json:"total_inventory"`
+	HotelID        uuid.UUID `json:"hotel_id"`
+	RoomTypeID     uuid.UUID `json:"room_type_id"`
+	Date           time.Time `json:"date"`
+	Version        int64     `json:"version"`
+}
+
+// Bumps the version even when total_inventory stays the same, so concurrent writers notice the change.
+func (q *Queries) SetRoomTypeInventoryTotal(ctx context.Context, arg SetRoomTypeInventoryTotalParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setRoomTypeInventoryTotal,
+		arg.TotalInventory,
+		arg.HotelID,
+		arg.RoomTypeID,
+		arg.Date,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateRoomTypeInventoryForDate = `-- name: UpdateRoomTypeInventoryForDate :execrows
 UPDATE booking.room_type_inventory
 SET
@@ -7,6 +7,7 @@ package database
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -66,11 +67,66 @@ type AuthUser struct {
 	GuestID      uuid.UUID    `json:"guest_id"`
 }
 
+type AuthUserRole struct {
+	UserID    uuid.UUID     `json:"user_id"`
+	Role      string        `json:"role"`
+	HotelID   uuid.NullUUID `json:"hotel_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type BookingCalendarFeed struct {
+	ID         uuid.UUID     `json:"id"`
+	HotelID    uuid.UUID     `json:"hotel_id"`
+	RoomTypeID uuid.NullUUID `json:"room_type_id"`
+	Token      string        `json:"token"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type BookingChannel struct {
+	ID            uuid.UUID      `json:"id"`
+	HotelID       uuid.UUID      `json:"hotel_id"`
+	Name          string         `json:"name"`
+	Adapter       string         `json:"adapter"`
+	EndpointUrl   string         `json:"endpoint_url"`
+	HotelCode     string         `json:"hotel_code"`
+	ApiKey        string         `json:"api_key"`
+	Active        bool           `json:"active"`
+	LastSyncedAt  sql.NullTime   `json:"last_synced_at"`
+	LastSyncError sql.NullString `json:"last_sync_error"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type BookingChannelReservation struct {
+	ChannelID     uuid.UUID `json:"channel_id"`
+	ExternalID    string    `json:"external_id"`
+	ReservationID uuid.UUID `json:"reservation_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type BookingChannelRoomMapping struct {
+	ChannelID        uuid.UUID `json:"channel_id"`
+	RoomTypeID       uuid.UUID `json:"room_type_id"`
+	ExternalRoomCode string    `json:"external_room_code"`
+	RatePlanCode     string    `json:"rate_plan_code"`
+	Rate             string    `json:"rate"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
 type BookingGuest struct {
 	ID        uuid.UUID `json:"id"`
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	Email     string    `json:"email"`
+	Locale    string    `json:"locale"`
+}
+
+type BookingGuestCalendarFeed struct {
+	GuestID   uuid.UUID `json:"guest_id"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type BookingHotel struct {
@@ -81,6 +137,85 @@ type BookingHotel struct {
 	UpdatedAt sql.NullTime `json:"updated_at"`
 	DeletedAt sql.NullTime `json:"deleted_at"`
 	IsActive  bool         `json:"is_active"`
+	TimeZone  string       `json:"time_zone"`
+}
+
+type BookingInventoryCronCheckpoint struct {
+	RunID       uuid.UUID `json:"run_id"`
+	HotelID     uuid.UUID `json:"hotel_id"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+type BookingInventoryCronRun struct {
+	ID              uuid.UUID     `json:"id"`
+	PartitionKey    string        `json:"partition_key"`
+	WindowEnd       time.Time     `json:"window_end"`
+	Status          string        `json:"status"`
+	FailedHotels    int32         `json:"failed_hotels"`
+	StartedAt       time.Time     `json:"started_at"`
+	FinishedAt      sql.NullTime  `json:"finished_at"`
+	HotelID         uuid.NullUUID `json:"hotel_id"`
+	HotelsProcessed int32         `json:"hotels_processed"`
+	RowsInserted    int64         `json:"rows_inserted"`
+}
+
+type BookingInventoryCronRunError struct {
+	ID         int64         `json:"id"`
+	RunID      uuid.UUID     `json:"run_id"`
+	HotelID    uuid.UUID     `json:"hotel_id"`
+	RoomTypeID uuid.NullUUID `json:"room_type_id"`
+	Error      string        `json:"error"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type BookingNotification struct {
+	ID            uuid.UUID      `json:"id"`
+	ReservationID uuid.UUID      `json:"reservation_id"`
+	Kind          string         `json:"kind"`
+	Locale        string         `json:"locale"`
+	Recipient     string         `json:"recipient"`
+	Subject       string         `json:"subject"`
+	BodyText      string         `json:"body_text"`
+	BodyHtml      string         `json:"body_html"`
+	Calendar      string         `json:"calendar"`
+	DedupeKey     string         `json:"dedupe_key"`
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	SentAt        sql.NullTime   `json:"sent_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type BookingOutboxDelivery struct {
+	EventID     uuid.UUID `json:"event_id"`
+	Subscriber  string    `json:"subscriber"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+type BookingOutboxEvent struct {
+	ID            uuid.UUID       `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int32           `json:"attempts"`
+	LastError     sql.NullString  `json:"last_error"`
+	PublishedAt   sql.NullTime    `json:"published_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+}
+
+type BookingOverbookingPolicy struct {
+	ID         uuid.UUID     `json:"id"`
+	HotelID    uuid.NullUUID `json:"hotel_id"`
+	RoomTypeID uuid.NullUUID `json:"room_type_id"`
+	ValidFrom  sql.NullTime  `json:"valid_from"`
+	ValidTo    sql.NullTime  `json:"valid_to"`
+	Factor     float64       `json:"factor"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
 }
 
 type BookingReservation struct {
@@ -93,6 +228,7 @@ type BookingReservation struct {
 	GuestID    uuid.UUID `json:"guest_id"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	CreatedAt  time.Time `json:"created_at"`
+	Revision   int32     `json:"revision"`
 }
 
 type BookingRoom struct {
@@ -127,3 +263,66 @@ type BookingRoomTypeInventory struct {
 	TotalInventory int32     `json:"total_inventory"`
 	TotalReserved  int32     `json:"total_reserved"`
 }
+
+type BookingRoomTypeRestriction struct {
+	HotelID           uuid.UUID     `json:"hotel_id"`
+	RoomTypeID        uuid.UUID     `json:"room_type_id"`
+	Date              time.Time     `json:"date"`
+	MinLengthOfStay   sql.NullInt32 `json:"min_length_of_stay"`
+	MaxLengthOfStay   sql.NullInt32 `json:"max_length_of_stay"`
+	ClosedToArrival   bool          `json:"closed_to_arrival"`
+	ClosedToDeparture bool          `json:"closed_to_departure"`
+	StopSell          bool          `json:"stop_sell"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	CreatedAt         time.Time     `json:"created_at"`
+}
+
+type BookingWaitlistEntry struct {
+	ID             uuid.UUID     `json:"id"`
+	HotelID        uuid.UUID     `json:"hotel_id"`
+	RoomTypeID     uuid.UUID     `json:"room_type_id"`
+	GuestID        uuid.UUID     `json:"guest_id"`
+	StartDate      time.Time     `json:"start_date"`
+	EndDate        time.Time     `json:"end_date"`
+	Status         string        `json:"status"`
+	ReservationID  uuid.NullUUID `json:"reservation_id"`
+	OfferExpiresAt sql.NullTime  `json:"offer_expires_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type BookingWebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode sql.NullInt32   `json:"last_status_code"`
+	LastError      sql.NullString  `json:"last_error"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type BookingWebhookDeliveryAttempt struct {
+	ID          int64          `json:"id"`
+	DeliveryID  uuid.UUID      `json:"delivery_id"`
+	StatusCode  sql.NullInt32  `json:"status_code"`
+	Error       sql.NullString `json:"error"`
+	DurationMs  int32          `json:"duration_ms"`
+	AttemptedAt time.Time      `json:"attempted_at"`
+}
+
+type BookingWebhookSubscription struct {
+	ID         uuid.UUID `json:"id"`
+	HotelID    uuid.UUID `json:"hotel_id"`
+	Url        string    `json:"url"`
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BatchUpdateRoomTypeInventories runs BatchUpdateRoomTypeInventory for every params in a single round trip
+// and returns the rows each one inserted. The batch runs in one implicit transaction: when a statement fails
+// nothing is inserted and the error names the index of the params that failed.
+func BatchUpdateRoomTypeInventories(ctx context.Context, pool *pgxpool.Pool, params []BatchUpdateRoomTypeInventoryParams) ([]int64, error) {
+	batch := &pgx.Batch{}
+	for _, arg := range params {
+		batch.Queue(batchUpdateRoomTypeInventory,
+			arg.HotelID,
+			arg.RoomTypeID,
+			arg.Dates,
+			arg.TotalInventory,
+		)
+	}
+
+	results := pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	rows := make([]int64, len(params))
+	for i := range params {
+		tag, err := results.Exec()
+		if err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
+		rows[i] = tag.RowsAffected()
+	}
+	if err := results.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close batch: %w", err)
+	}
+	return rows, nil
+}
+
+// BatchError is the failure of the statement at Index of a batch.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("statement %d of batch: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// poolTracer gives the queries, batches and copies of the pgx pool the spans otelsql gives database/sql.
+type poolTracer struct {
+	dbName string
+}
+
+func (t poolTracer) start(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	attrs = append(attrs,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.name", t.dbName))
+	ctx, _ = tracing.Start(ctx, name, attrs...)
+	return ctx
+}
+
+func (t poolTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, "db.query", attribute.String("db.statement", data.SQL))
+}
+
+func (t poolTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	tracing.End(trace.SpanFromContext(ctx), data.Err)
+}
+
+func (t poolTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx, "db.batch", attribute.Int("db.batch.size", data.Batch.Len()))
+}
+
+func (t poolTracer) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+func (t poolTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	tracing.End(trace.SpanFromContext(ctx), data.Err)
+}
+
+func (t poolTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return t.start(ctx, "db.copy", attribute.String("db.sql.table", data.TableName.Sanitize()))
+}
+
+func (t poolTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	tracing.End(trace.SpanFromContext(ctx), data.Err)
+}
@@ -14,7 +14,7 @@ import (
 
 const getReservationById = `-- name: GetReservationById :one
 SELECT
-	id, hotel_id, room_type_id, start_date, end_date, status, guest_id, updated_at, created_at
+	id, hotel_id, room_type_id, start_date, end_date, status, guest_id, updated_at, created_at, revision
 FROM
 	booking.reservations
 WHERE
@@ -34,6 +34,35 @@ func (q *Queries) GetReservationById(ctx context.Context, id uuid.UUID) (Booking
 		&i.GuestID,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.Revision,
+	)
+	return i, err
+}
+
+const getReservationByIdForUpdate = `-- name: GetReservationByIdForUpdate :one
+SELECT
+	id, hotel_id, room_type_id, start_date, end_date, status, guest_id, updated_at, created_at, revision
+FROM
+	booking.reservations
+WHERE
+	id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetReservationByIdForUpdate(ctx context.Context, id uuid.UUID) (BookingReservation, error) {
+	row := q.db.QueryRowContext(ctx, getReservationByIdForUpdate, id)
+	var i BookingReservation
+	err := row.Scan(
+		&i.ID,
+		&i.HotelID,
+		&i.RoomTypeID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Status,
+		&i.GuestID,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.Revision,
 	)
 	return i, err
 }
@@ -91,3 +120,45 @@ func (q *Queries) InsertReservation(ctx context.Context, arg InsertReservationPa
 	err := row.Scan(&id)
 	return id, err
 }
+
+const updateReservationDates = `-- name: UpdateReservationDates :exec
+UPDATE booking.reservations
+SET
+	start_date = $1,
+	end_date = $2,
+	revision = revision + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = $3
+`
+
+type UpdateReservationDatesParams struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateReservationDates(ctx context.Context, arg UpdateReservationDatesParams) error {
+	_, err := q.db.ExecContext(ctx, updateReservationDates, arg.StartDate, arg.EndDate, arg.ID)
+	return err
+}
+
+const updateReservationStatus = `-- name: UpdateReservationStatus :exec
+UPDATE booking.reservations
+SET
+	status = $1,
+	revision = revision + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = $2
+`
+
+type UpdateReservationStatusParams struct {
+	Status string    `json:"status"`
+	ID     uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateReservationStatus(ctx context.Context, arg UpdateReservationStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateReservationStatus, arg.Status, arg.ID)
+	return err
+}
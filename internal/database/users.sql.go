@@ -61,6 +61,62 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (AuthUser, erro
 	return i, err
 }
 
+const getUserRoles = `-- name: GetUserRoles :many
+SELECT
+	user_id, role, hotel_id, created_at
+FROM
+	auth.user_roles
+WHERE
+	user_id = $1
+`
+
+func (q *Queries) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]AuthUserRole, error) {
+	rows, err := q.db.QueryContext(ctx, getUserRoles, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuthUserRole
+	for rows.Next() {
+		var i AuthUserRole
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Role,
+			&i.HotelID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const grantUserRole = `-- name: GrantUserRole :exec
+INSERT INTO
+	auth.user_roles (user_id, role, hotel_id)
+VALUES
+	($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type GrantUserRoleParams struct {
+	UserID  uuid.UUID     `json:"user_id"`
+	Role    string        `json:"role"`
+	HotelID uuid.NullUUID `json:"hotel_id"`
+}
+
+func (q *Queries) GrantUserRole(ctx context.Context, arg GrantUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, grantUserRole, arg.UserID, arg.Role, arg.HotelID)
+	return err
+}
+
 const insertUser = `-- name: InsertUser :one
 INSERT INTO
 	auth.users (id, email, password_hash, guest_id)
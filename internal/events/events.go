@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	ReservationCreated   Type = "ReservationCreated"
+	ReservationConfirmed Type = "ReservationConfirmed"
+	ReservationCancelled Type = "ReservationCancelled"
+	// ReservationModified is emitted when the guest moves the dates of a reservation.
+	ReservationModified Type = "ReservationModified"
+	InventoryChanged    Type = "InventoryChanged"
+)
+
+const (
+	AggregateReservation = "reservation"
+	// Inventory events are keyed by room type, the unit capacity is managed in.
+	AggregateRoomType = "room_type"
+)
+
+// Event is the envelope handed to publishers and subscribers. Delivery is at-least-once,
+// so consumers should deduplicate by ID.
+type Event struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          Type            `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+type ReservationPayload struct {
+	ReservationID uuid.UUID   `json:"reservation_id"`
+	HotelID       uuid.UUID   `json:"hotel_id"`
+	RoomTypeID    uuid.UUID   `json:"room_type_id"`
+	GuestID       uuid.UUID   `json:"guest_id"`
+	StartDate     shared.Date `json:"start_date"`
+	EndDate       shared.Date `json:"end_date"`
+	Status        string      `json:"status"`
+	// PreviousStatus is set on cancellations, a cancelled hold was never confirmed by the guest.
+	PreviousStatus string `json:"previous_status,omitempty"`
+}
+
+type InventoryChangedPayload struct {
+	HotelID    uuid.UUID   `json:"hotel_id"`
+	RoomTypeID uuid.UUID   `json:"room_type_id"`
+	From       shared.Date `json:"from"`
+	To         shared.Date `json:"to"`
+	// ReservedDelta is added to total_reserved of every night in the range.
+	ReservedDelta int32 `json:"reserved_delta"`
+}
+
+// Record writes an event to the outbox. Pass queries bound to the transaction that makes the change,
+// so the event is stored if and only if the change commits.
+func Record(ctx context.Context, qtx *database.Queries, eventType Type, aggregateType string, aggregateID uuid.UUID, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event payload: %w", eventType, err)
+	}
+
+	err = qtx.InsertOutboxEvent(ctx, database.InsertOutboxEventParams{
+		ID:            uuid.New(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     string(eventType),
+		Payload:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// RecordReservation records a reservation lifecycle event.
+func RecordReservation(ctx context.Context, qtx *database.Queries, eventType Type, reservation database.BookingReservation) error {
+	return Record(ctx, qtx, eventType, AggregateReservation, reservation.ID, reservationPayload(reservation))
+}
+
+// RecordReservationCancelled records the cancellation of a reservation that was in previousStatus.
+func RecordReservationCancelled(ctx context.Context, qtx *database.Queries, reservation database.BookingReservation, previousStatus string) error {
+	payload := reservationPayload(reservation)
+	payload.PreviousStatus = previousStatus
+	return Record(ctx, qtx, ReservationCancelled, AggregateReservation, reservation.ID, payload)
+}
+
+func reservationPayload(reservation database.BookingReservation) ReservationPayload {
+	return ReservationPayload{
+		ReservationID: reservation.ID,
+		HotelID:       reservation.HotelID,
+		RoomTypeID:    reservation.RoomTypeID,
+		GuestID:       reservation.GuestID,
+		StartDate:     shared.Date(reservation.StartDate),
+		EndDate:       shared.Date(reservation.EndDate),
+		Status:        reservation.Status,
+	}
+}
+
+func fromOutbox(e database.BookingOutboxEvent) Event {
+	return Event{
+		ID:            e.ID,
+		Type:          Type(e.EventType),
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		Payload:       e.Payload,
+		OccurredAt:    e.CreatedAt,
+	}
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotifyChannel is the Postgres channel the outbox trigger notifies once events commit.
+const NotifyChannel = "hotel_events"
+
+const (
+	listenMinBackoff = 100 * time.Millisecond
+	listenMaxBackoff = 30 * time.Second
+)
+
+// Listener wakes the relay as soon as outbox events commit instead of at its next poll. Notifications only
+// carry the wake-up: the relay still claims the rows, so each event is handled by a single instance and
+// marked published once its handlers succeeded. A lost notification only delays events until the next poll.
+type Listener struct {
+	pool *pgxpool.Pool
+	wake chan struct{}
+}
+
+func NewListener(pool *pgxpool.Pool) *Listener {
+	return &Listener{
+		pool: pool,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Wake receives a value when events were committed since the last receive.
+func (l *Listener) Wake() <-chan struct{} {
+	return l.wake
+}
+
+// Run listens on NotifyChannel until ctx is done, reconnecting with backoff when the connection is lost.
+func (l *Listener) Run(ctx context.Context) {
+	backoff := listenMinBackoff
+	for {
+		listening, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if listening {
+			backoff = listenMinBackoff
+		}
+		slog.WarnContext(ctx, "Event listener disconnected, reconnecting", "error", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, listenMaxBackoff)
+	}
+}
+
+// listen holds a connection listening on NotifyChannel until it fails, and reports whether it got to listen.
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire listen connection: %w", err)
+	}
+	// The connection stays subscribed and may be broken, it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+		return false, fmt.Errorf("failed to listen on %s: %w", NotifyChannel, err)
+	}
+	// Events committed while the connection was down were not notified.
+	l.signal()
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return true, fmt.Errorf("failed to wait for notification: %w", err)
+		}
+		l.signal()
+	}
+}
+
+func (l *Listener) signal() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Publisher delivers relayed events to the outside world.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Handler consumes published events. A returned error makes the relay retry the event.
+type Handler func(ctx context.Context, e Event) error
+
+// Bus is a Publisher that in-process consumers can subscribe to. Subscribers are named, so the relay can
+// remember which of them handled an event and retry only the ones that failed.
+type Bus interface {
+	Publisher
+	Subscribe(name string, h Handler)
+	// PublishExcept calls the subscribers not named in handled and returns the names of those that succeeded.
+	PublishExcept(ctx context.Context, e Event, handled []string) ([]string, error)
+}
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// InMemoryBus hands events straight to subscribers of the same process. Suitable for a single
+// instance and for local development.
+type InMemoryBus struct {
+	mu          sync.RWMutex
+	subscribers []subscriber
+}
+
+func NewInMemoryBus() *InMemoryBus {
+	return &InMemoryBus{}
+}
+
+// Subscribe adds a handler under a name that stays the same across restarts.
+func (b *InMemoryBus) Subscribe(name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber{name: name, handler: h})
+}
+
+// Publish calls every subscriber. If any of them fails the event is reported as failed.
+func (b *InMemoryBus) Publish(ctx context.Context, e Event) error {
+	_, err := b.PublishExcept(ctx, e, nil)
+	return err
+}
+
+func (b *InMemoryBus) PublishExcept(ctx context.Context, e Event, handled []string) ([]string, error) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	var succeeded []string
+	var errs []error
+	for _, s := range subscribers {
+		if slices.Contains(handled, s.name) {
+			continue
+		}
+		if err := s.handler(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		succeeded = append(succeeded, s.name)
+	}
+
+	return succeeded, errors.Join(errs...)
+}
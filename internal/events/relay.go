@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+)
+
+const (
+	relayInterval  = time.Second
+	relayBatchSize = 100
+	// A failed event waits relayMinBackoff before its next attempt, doubling per attempt up to relayMaxBackoff,
+	// so retries outlast an outage of several hours.
+	relayMinBackoff = time.Second
+	relayMaxBackoff = time.Hour
+	// Events failing this many times stay in the outbox with their last error, until `hotelctl outbox replay`
+	// gives them another round.
+	relayMaxAttempts = 20
+)
+
+// Relay moves committed outbox events to a Publisher. An event is marked published only after the
+// publisher accepted it, so a crash in between publishes it again: delivery is at-least-once. Relays of
+// several instances claim distinct events, each event is published by one of them. A Bus only sees a
+// failed event again on the subscribers that didn't handle it yet.
+type Relay struct {
+	queries   *database.Queries
+	db        database.Service
+	publisher Publisher
+}
+
+func NewRelay(queries *database.Queries, db database.Service, publisher Publisher) *Relay {
+	return &Relay{
+		queries:   queries,
+		db:        db,
+		publisher: publisher,
+	}
+}
+
+// Backlog returns how many events wait to be published and how long the oldest of them has been waiting.
+// Events that ran out of attempts don't count, they wait for an operator rather than the relay.
+func (r *Relay) Backlog(ctx context.Context) (int64, time.Duration, error) {
+	backlog, err := r.queries.GetOutboxBacklog(ctx, relayMaxAttempts)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get outbox backlog: %w", err)
+	}
+	return backlog.Pending, time.Duration(backlog.OldestAgeSeconds * float64(time.Second)), nil
+}
+
+// Run polls the outbox until ctx is done, and flushes it early when wake receives. A nil wake only polls.
+func (r *Relay) Run(ctx context.Context, wake <-chan struct{}) {
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil {
+			slog.ErrorContext(ctx, "Outbox relay failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// Flush publishes pending events until the outbox is drained and returns how many were published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, claimed, err := r.relayBatch(ctx)
+		total += published
+		if err != nil || claimed < relayBatchSize {
+			return total, err
+		}
+	}
+}
+
+func (r *Relay) relayBatch(ctx context.Context) (int, int, error) {
+	tx, err := r.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to start relay transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	pending, err := qtx.ClaimUnpublishedOutboxEvents(ctx, database.ClaimUnpublishedOutboxEventsParams{
+		MaxAttempts: relayMaxAttempts,
+		BatchSize:   relayBatchSize,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	published := 0
+	for _, outboxEvent := range pending {
+		if err := r.publish(ctx, qtx, fromOutbox(outboxEvent)); err != nil {
+			retryAfter := retryBackoff(outboxEvent.Attempts + 1)
+			slog.WarnContext(ctx, "Failed to publish event", "event_id", outboxEvent.ID, "retry_after", retryAfter, "error", err)
+			if err := qtx.RecordOutboxEventFailure(ctx, database.RecordOutboxEventFailureParams{
+				LastError:         sql.NullString{String: err.Error(), Valid: true},
+				RetryAfterSeconds: retryAfter.Seconds(),
+				ID:                outboxEvent.ID,
+			}); err != nil {
+				return 0, 0, fmt.Errorf("failed to record outbox event %q failure: %w", outboxEvent.ID, err)
+			}
+			continue
+		}
+
+		if err := qtx.MarkOutboxEventPublished(ctx, outboxEvent.ID); err != nil {
+			return 0, 0, fmt.Errorf("failed to mark outbox event %q published: %w", outboxEvent.ID, err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return published, len(pending), nil
+}
+
+// publish hands e to the publisher. A Bus is only asked for the subscribers that haven't handled e in an
+// earlier attempt, and the ones handling it now are recorded.
+func (r *Relay) publish(ctx context.Context, qtx *database.Queries, e Event) error {
+	bus, ok := r.publisher.(Bus)
+	if !ok {
+		return r.publisher.Publish(ctx, e)
+	}
+
+	handled, err := qtx.ListOutboxEventSubscribers(ctx, e.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get subscribers that handled event %q: %w", e.ID, err)
+	}
+
+	succeeded, publishErr := bus.PublishExcept(ctx, e, handled)
+	for _, name := range succeeded {
+		if err := qtx.RecordOutboxDelivery(ctx, database.RecordOutboxDeliveryParams{
+			EventID:    e.ID,
+			Subscriber: name,
+		}); err != nil {
+			return fmt.Errorf("failed to record delivery of event %q to %s: %w", e.ID, name, err)
+		}
+	}
+	return publishErr
+}
+
+// retryBackoff is how long an event waits after its attempt-th failed attempt.
+func retryBackoff(attempt int32) time.Duration {
+	backoff := relayMinBackoff
+	for i := int32(1); i < attempt && backoff < relayMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, relayMaxBackoff)
+}
@@ -0,0 +1,122 @@
+// Package health answers the liveness and readiness probes. Liveness only tells the process is serving,
+// readiness runs the checks of the dependencies the API needs and fails while the server drains.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+)
+
+const checkTimeout = 2 * time.Second
+
+const (
+	StatusOK       = "ok"
+	StatusFailed   = "failed"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+)
+
+// Check returns an error when the dependency it watches is unusable.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a check in the readiness response.
+type Result struct {
+	Status string `json:"status"`
+	// Critical checks take the instance out of rotation when they fail.
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	fn       Check
+}
+
+type Checker struct {
+	checks   []check
+	draining atomic.Bool
+}
+
+func New() *Checker {
+	return &Checker{}
+}
+
+// Require adds a check the instance can't serve without, such as its database.
+func (c *Checker) Require(name string, fn Check) {
+	c.checks = append(c.checks, check{name: name, critical: true, fn: fn})
+}
+
+// Monitor adds a check that is reported without failing readiness. A stuck outbox or a missed cron run is
+// shared by every instance, taking them all out of rotation would only add an outage.
+func (c *Checker) Monitor(name string, fn Check) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// Drain fails readiness from now on, so the load balancer stops sending requests before the server closes.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Run runs every check concurrently and reports whether the critical ones passed.
+func (c *Checker) Run(ctx context.Context) (map[string]Result, bool) {
+	results := make(map[string]Result, len(c.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			result := Result{Status: StatusOK, Critical: chk.critical}
+			if err := chk.fn(ctx); err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			results[chk.name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	ready := true
+	for _, result := range results {
+		if result.Critical && result.Status != StatusOK {
+			ready = false
+		}
+	}
+	return results, ready
+}
+
+// LiveHandler answers 200 as long as the process serves requests. It checks no dependency: restarting the
+// container doesn't bring a database back.
+func (c *Checker) LiveHandler(w http.ResponseWriter, r *http.Request) {
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"status": StatusOK})
+}
+
+// ReadyHandler answers 200 when the critical checks pass and 503 when one fails or the server is draining.
+func (c *Checker) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	if c.draining.Load() {
+		shared.WriteJSON(w, http.StatusServiceUnavailable, shared.Envelope{"status": StatusDraining})
+		return
+	}
+
+	results, ready := c.Run(r.Context())
+	status, code := StatusReady, http.StatusOK
+	if !ready {
+		status, code = StatusNotReady, http.StatusServiceUnavailable
+	}
+	shared.WriteJSON(w, code, shared.Envelope{"status": status, "checks": results})
+}
@@ -2,16 +2,31 @@ package hotel
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
 	"github.com/go-playground/validator/v10"
 )
 
 var (
 	ErrDuplicateHotelByName = errors.New("hotelService: Hotel with such name already exists")
 	ErrDuplicateRoomByName  = errors.New("hotelService: Room type with such name already exists")
+	ErrRoomTypeNotFound     = errors.New("hotelService: Room type not found")
+	ErrInvalidDateRange     = errors.New("hotelService: Invalid date range")
+	ErrInvalidLengthOfStay  = errors.New("hotelService: Minimum length of stay exceeds maximum")
 )
 
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrDuplicateHotelByName: {Status: http.StatusBadRequest, Code: "hotel.duplicate_name"},
+		ErrDuplicateRoomByName:  {Status: http.StatusConflict, Code: "room_type.duplicate_name", Message: "Room type with such name already exists"},
+		ErrRoomTypeNotFound:     {Status: http.StatusNotFound, Code: "room_type.not_found", Message: "Room type not found"},
+		ErrInvalidDateRange:     {Status: http.StatusBadRequest, Code: "request.invalid_date_range"},
+		ErrInvalidLengthOfStay:  {Status: http.StatusBadRequest, Code: "restriction.invalid_length_of_stay"},
+	})
+}
+
 type HotelService struct {
 	queries   *database.Queries
 	validator *validator.Validate
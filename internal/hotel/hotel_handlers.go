@@ -2,7 +2,6 @@ package hotel
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
@@ -19,18 +18,13 @@ func (s *HotelService) CreateHotelHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := s.validator.Struct(&body); err != nil {
-		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err.Error()))
+		shared.WriteValidationError(w, err)
 		return
 	}
 
 	hotel, err := s.createHotel(r.Context(), body)
 	if err != nil {
-		if errors.Is(err, ErrDuplicateHotelByName) {
-			shared.WriteError(w, http.StatusBadRequest, ErrDuplicateHotelByName.Error())
-			return
-		}
-
-		shared.WriteError(w, http.StatusInternalServerError, "Sorry, something went wrong")
+		shared.WriteDomainError(w, err)
 		return
 	}
 
@@ -69,7 +63,7 @@ func (s *HotelService) UpdateHotelHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := s.validator.Struct(&body); err != nil {
-		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err.Error()))
+		shared.WriteValidationError(w, err)
 		return
 	}
 
@@ -13,11 +13,15 @@ import (
 type CreateHotelBody struct {
 	Name     string `json:"name" validate:"required,min=1,max=255"`
 	Location string `json:"location" validate:"required,min=1,max=255"`
+	// IANA time zone, UTC when empty.
+	TimeZone string `json:"timeZone" validate:"omitempty,timezone"`
 }
 
 type UpdateHotelBody struct {
 	Name     string `json:"name" validate:"required,min=1,max=255"`
 	Location string `json:"location" validate:"required,min=1,max=255"`
+	// IANA time zone, UTC when empty.
+	TimeZone string `json:"timeZone" validate:"omitempty,timezone"`
 }
 
 func (s *HotelService) GetActiveHotels(ctx context.Context) ([]database.BookingHotel, error) {
@@ -41,6 +45,7 @@ func (s *HotelService) createHotel(ctx context.Context, body CreateHotelBody) (d
 		ID:       uuid.New(),
 		Name:     body.Name,
 		Location: body.Location,
+		TimeZone: timeZoneOrDefault(body.TimeZone),
 	})
 
 }
@@ -49,14 +54,23 @@ func (s *HotelService) getHotelById(ctx context.Context, id uuid.UUID) (database
 	return s.queries.GetHotelById(ctx, id)
 }
 
+// updateHotel keeps the stored time zone when the body omits it.
 func (s *HotelService) updateHotel(ctx context.Context, id uuid.UUID, body UpdateHotelBody) (database.BookingHotel, error) {
 	return s.queries.UpdateHotel(ctx, database.UpdateHotelParams{
 		ID:       id,
 		Name:     body.Name,
 		Location: body.Location,
+		TimeZone: body.TimeZone,
 	})
 }
 
 func (s *HotelService) deleteHotel(ctx context.Context, id uuid.UUID) error {
 	return s.queries.DeleteHotel(ctx, id)
 }
+
+func timeZoneOrDefault(timeZone string) string {
+	if timeZone == "" {
+		return "UTC"
+	}
+	return timeZone
+}
@@ -0,0 +1,129 @@
+package hotel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (s *HotelService) SetRoomTypeRestrictionsHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, roomTypeID, ok := parseRoomTypePath(w, r)
+	if !ok {
+		return
+	}
+
+	var body SetRestrictionsBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	updated, err := s.setRoomTypeRestrictions(r.Context(), hotelID, roomTypeID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"updated_dates": updated})
+}
+
+func (s *HotelService) GetRoomTypeRestrictionsHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, roomTypeID, ok := parseRoomTypePath(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, _, ok := parseRestrictionRangeQuery(w, r)
+	if !ok {
+		return
+	}
+
+	restrictions, err := s.getRoomTypeRestrictions(r.Context(), hotelID, roomTypeID, from, to)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"restrictions": restrictions})
+}
+
+func (s *HotelService) ClearRoomTypeRestrictionsHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, roomTypeID, ok := parseRoomTypePath(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, weekdays, ok := parseRestrictionRangeQuery(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := s.clearRoomTypeRestrictions(r.Context(), hotelID, roomTypeID, ClearRestrictionsQuery{
+		From:     from,
+		To:       to,
+		Weekdays: weekdays,
+	})
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"cleared_dates": deleted})
+}
+
+func parseRoomTypePath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	roomTypeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid room type ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return hotelID, roomTypeID, true
+}
+
+// parseRestrictionRangeQuery reads from, to and an optional comma separated weekdays list (0 = Sunday).
+func parseRestrictionRangeQuery(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, []time.Weekday, bool) {
+	from, err := time.Parse(time.DateOnly, r.URL.Query().Get("from"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid from")
+		return time.Time{}, time.Time{}, nil, false
+	}
+
+	to, err := time.Parse(time.DateOnly, r.URL.Query().Get("to"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid to")
+		return time.Time{}, time.Time{}, nil, false
+	}
+
+	var weekdays []time.Weekday
+	if raw := r.URL.Query().Get("weekdays"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			day, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || day < 0 || day > 6 {
+				shared.WriteError(w, http.StatusBadRequest, "Invalid weekdays, expected comma separated numbers 0-6")
+				return time.Time{}, time.Time{}, nil, false
+			}
+			weekdays = append(weekdays, time.Weekday(day))
+		}
+	}
+
+	return from, to, weekdays, true
+}
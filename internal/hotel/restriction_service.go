@@ -0,0 +1,134 @@
+package hotel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+// Matches the window the inventory cron generates ahead.
+const maxRestrictionRangeDays = 2 * 366
+
+type SetRestrictionsBody struct {
+	From              shared.Date    `json:"from"`
+	To                shared.Date    `json:"to"`
+	Weekdays          []time.Weekday `json:"weekdays" validate:"omitempty,dive,min=0,max=6"`
+	MinLengthOfStay   *int32         `json:"minLengthOfStay" validate:"omitempty,min=1"`
+	MaxLengthOfStay   *int32         `json:"maxLengthOfStay" validate:"omitempty,min=1"`
+	ClosedToArrival   bool           `json:"closedToArrival"`
+	ClosedToDeparture bool           `json:"closedToDeparture"`
+	StopSell          bool           `json:"stopSell"`
+}
+
+type ClearRestrictionsQuery struct {
+	From     time.Time
+	To       time.Time
+	Weekdays []time.Weekday
+}
+
+// setRoomTypeRestrictions replaces the restrictions of every matching date in the range.
+func (s *HotelService) setRoomTypeRestrictions(ctx context.Context, hotelID, roomTypeID uuid.UUID, body SetRestrictionsBody) (int64, error) {
+	from, to := time.Time(body.From), time.Time(body.To)
+	if err := validateDateRange(from, to); err != nil {
+		return 0, err
+	}
+
+	if body.MinLengthOfStay != nil && body.MaxLengthOfStay != nil && *body.MinLengthOfStay > *body.MaxLengthOfStay {
+		return 0, ErrInvalidLengthOfStay
+	}
+
+	if err := s.ensureRoomTypeExists(ctx, hotelID, roomTypeID); err != nil {
+		return 0, err
+	}
+
+	params := database.UpsertRoomTypeRestrictionsParams{
+		HotelID:           hotelID,
+		RoomTypeID:        roomTypeID,
+		Dates:             shared.DatesInRange(from, to, body.Weekdays),
+		ClosedToArrival:   body.ClosedToArrival,
+		ClosedToDeparture: body.ClosedToDeparture,
+		StopSell:          body.StopSell,
+	}
+	if body.MinLengthOfStay != nil {
+		params.MinLengthOfStay = sql.NullInt32{Int32: *body.MinLengthOfStay, Valid: true}
+	}
+	if body.MaxLengthOfStay != nil {
+		params.MaxLengthOfStay = sql.NullInt32{Int32: *body.MaxLengthOfStay, Valid: true}
+	}
+
+	if len(params.Dates) == 0 {
+		return 0, nil
+	}
+
+	updated, err := s.queries.UpsertRoomTypeRestrictions(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert restrictions for room type %q: %w", roomTypeID, err)
+	}
+
+	return updated, nil
+}
+
+func (s *HotelService) getRoomTypeRestrictions(ctx context.Context, hotelID, roomTypeID uuid.UUID, from, to time.Time) ([]database.BookingRoomTypeRestriction, error) {
+	if err := validateDateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureRoomTypeExists(ctx, hotelID, roomTypeID); err != nil {
+		return nil, err
+	}
+
+	return s.queries.GetRoomTypeRestrictionsForRange(ctx, database.GetRoomTypeRestrictionsForRangeParams{
+		HotelID:    hotelID,
+		RoomTypeID: roomTypeID,
+		DateFrom:   from,
+		DateTo:     to,
+	})
+}
+
+func (s *HotelService) clearRoomTypeRestrictions(ctx context.Context, hotelID, roomTypeID uuid.UUID, query ClearRestrictionsQuery) (int64, error) {
+	if err := validateDateRange(query.From, query.To); err != nil {
+		return 0, err
+	}
+
+	if err := s.ensureRoomTypeExists(ctx, hotelID, roomTypeID); err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.queries.DeleteRoomTypeRestrictions(ctx, database.DeleteRoomTypeRestrictionsParams{
+		HotelID:    hotelID,
+		RoomTypeID: roomTypeID,
+		Dates:      shared.DatesInRange(query.From, query.To, query.Weekdays),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete restrictions for room type %q: %w", roomTypeID, err)
+	}
+
+	return deleted, nil
+}
+
+func (s *HotelService) ensureRoomTypeExists(ctx context.Context, hotelID, roomTypeID uuid.UUID) error {
+	_, err := s.getRoomTypeById(ctx, hotelID, roomTypeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check room type existence: %w", err)
+	}
+	return nil
+}
+
+func validateDateRange(from, to time.Time) error {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return ErrInvalidDateRange
+	}
+	if to.Sub(from) > maxRestrictionRangeDays*24*time.Hour {
+		return fmt.Errorf("%w: range can't exceed %d days", ErrInvalidDateRange, maxRestrictionRangeDays)
+	}
+	return nil
+}
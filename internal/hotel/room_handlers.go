@@ -2,7 +2,6 @@ package hotel
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 
@@ -28,17 +27,13 @@ func (s *HotelService) AddRoomTypeHandler(w http.ResponseWriter, r *http.Request
 	body.HotelID = hotelID.String()
 
 	if err := s.validator.Struct(&body); err != nil {
-		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err.Error()))
+		shared.WriteValidationError(w, err)
 		return
 	}
 
 	roomType, err := s.addRoomType(r.Context(), body)
 	if err != nil {
-		if errors.Is(err, ErrDuplicateRoomByName) {
-			shared.WriteError(w, http.StatusConflict, "Room type with such name already exists")
-			return
-		}
-		shared.WriteError(w, http.StatusInternalServerError, "Failed to add room type")
+		shared.WriteDomainError(w, err)
 		return
 	}
 
@@ -91,17 +86,13 @@ func (s *HotelService) UpdateRoomTypeHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	if err := s.validator.Struct(&body); err != nil {
-		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err.Error()))
+		shared.WriteValidationError(w, err)
 		return
 	}
 
 	roomType, err := s.updateRoomType(r.Context(), hotelID, roomID, body)
 	if err != nil {
-		if errors.Is(err, ErrDuplicateRoomByName) {
-			shared.WriteError(w, http.StatusConflict, "Room type with such name already exists")
-			return
-		}
-		shared.WriteError(w, http.StatusNotFound, "Room type not found")
+		shared.WriteDomainError(w, err)
 		return
 	}
 
@@ -89,6 +89,9 @@ func (s *HotelService) updateRoomType(ctx context.Context, hotelID, roomTypeID u
 		},
 	})
 
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingRoomType{}, ErrRoomTypeNotFound
+	}
 	if err != nil {
 		return database.BookingRoomType{}, err
 	}
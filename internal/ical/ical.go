@@ -0,0 +1,139 @@
+// Package ical writes the subset of RFC 5545 needed to put stays into guests' calendars.
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Method string
+
+const (
+	// MethodPublish is used for feeds, MethodRequest and MethodCancel for email attachments.
+	MethodPublish Method = "PUBLISH"
+	MethodRequest Method = "REQUEST"
+	MethodCancel  Method = "CANCEL"
+)
+
+type Status string
+
+const (
+	StatusConfirmed Status = "CONFIRMED"
+	// StatusTentative marks stays that may still fall through, such as unconfirmed waitlist holds.
+	StatusTentative Status = "TENTATIVE"
+	StatusCancelled Status = "CANCELLED"
+)
+
+const (
+	prodID        = "-//hotel-system//Reservations//EN"
+	uidDomain     = "hotel-system"
+	maxLineOctets = 75
+	dateLayout    = "20060102"
+	stampLayout   = "20060102T150405Z"
+)
+
+// Event is an all-day event. End is exclusive, for a stay it is the check-out date.
+type Event struct {
+	UID         string
+	Sequence    int
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	// Defaults to StatusConfirmed.
+	Status Status
+	Stamp  time.Time
+}
+
+// ReservationUID keeps the UID stable across confirmation, modification and cancellation,
+// so calendar clients update the same entry.
+func ReservationUID(reservationID uuid.UUID) string {
+	return fmt.Sprintf("%s@%s", reservationID, uidDomain)
+}
+
+// ReservationPartUID identifies one of several events derived from a reservation, such as its arrival.
+func ReservationPartUID(reservationID uuid.UUID, part string) string {
+	return fmt.Sprintf("%s-%s@%s", reservationID, part, uidDomain)
+}
+
+// Calendar renders a VCALENDAR with the given events.
+func Calendar(method Method, name string, events ...Event) string {
+	var b strings.Builder
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+prodID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:"+string(method))
+	if name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escape(name))
+	}
+
+	for _, e := range events {
+		writeEvent(&b, e)
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func writeEvent(b *strings.Builder, e Event) {
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	status := e.Status
+	if status == "" {
+		status = StatusConfirmed
+	}
+
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, "UID:"+e.UID)
+	writeLine(b, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+	writeLine(b, "DTSTAMP:"+stamp.UTC().Format(stampLayout))
+	writeLine(b, "DTSTART;VALUE=DATE:"+e.Start.Format(dateLayout))
+	writeLine(b, "DTEND;VALUE=DATE:"+e.End.Format(dateLayout))
+	writeLine(b, "SUMMARY:"+escape(e.Summary))
+	if e.Description != "" {
+		writeLine(b, "DESCRIPTION:"+escape(e.Description))
+	}
+	if e.Location != "" {
+		writeLine(b, "LOCATION:"+escape(e.Location))
+	}
+	writeLine(b, "STATUS:"+string(status))
+	writeLine(b, "TRANSP:OPAQUE")
+	writeLine(b, "END:VEVENT")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeLine terminates the line with CRLF and folds it at 75 octets without splitting UTF-8 sequences.
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,354 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type BulkUpdateBody struct {
+	RoomTypeID uuid.UUID      `json:"roomTypeId" validate:"required"`
+	From       shared.Date    `json:"from"`
+	To         shared.Date    `json:"to"`
+	Weekdays   []time.Weekday `json:"weekdays" validate:"omitempty,dive,min=0,max=6"`
+	Operation  Operation      `json:"operation" validate:"required,oneof=set adjust close open"`
+	// TotalInventory is the new room count for set.
+	TotalInventory *int32 `json:"totalInventory" validate:"omitempty,min=0"`
+	// Delta is added to the room count for adjust, negative values remove rooms.
+	Delta *int32 `json:"delta"`
+}
+
+// DateResult describes the change of one date. The New* fields hold the state after applying.
+type DateResult struct {
+	Date              shared.Date `json:"date"`
+	Status            DateStatus  `json:"status"`
+	Reason            string      `json:"reason,omitempty"`
+	TotalInventory    *int32      `json:"total_inventory"`
+	NewTotalInventory *int32      `json:"new_total_inventory"`
+	TotalReserved     int32       `json:"total_reserved"`
+	StopSell          bool        `json:"stop_sell"`
+	NewStopSell       bool        `json:"new_stop_sell"`
+	// Version of the date's inventory, after the change when applied.
+	Version int64 `json:"version"`
+}
+
+type BulkUpdateResult struct {
+	Applied   bool         `json:"applied"`
+	Dates     []DateResult `json:"dates"`
+	Conflicts []DateResult `json:"conflicts"`
+}
+
+var errVersionChanged = errors.New("inventory version changed")
+
+// previewBulkUpdate reports what bulkUpdate would do without changing anything.
+func (s *InventoryService) previewBulkUpdate(ctx context.Context, hotelID uuid.UUID, body BulkUpdateBody) (BulkUpdateResult, error) {
+	dates, err := s.validateBulkUpdate(ctx, hotelID, body)
+	if err != nil {
+		return BulkUpdateResult{}, err
+	}
+
+	changes, err := s.plan(ctx, s.queries, hotelID, body, dates)
+	if err != nil {
+		return BulkUpdateResult{}, err
+	}
+
+	return newBulkUpdateResult(changes, false), nil
+}
+
+// bulkUpdate applies the operation to every matching date without a conflict. Dates that would drop below
+// their reservations, or have no inventory to change, are skipped and listed as conflicts.
+func (s *InventoryService) bulkUpdate(ctx context.Context, hotelID uuid.UUID, body BulkUpdateBody) (BulkUpdateResult, error) {
+	dates, err := s.validateBulkUpdate(ctx, hotelID, body)
+	if err != nil {
+		return BulkUpdateResult{}, err
+	}
+
+	for attempt := 0; attempt < maxApplyAttempts; attempt++ {
+		changes, err := s.apply(ctx, hotelID, body, dates)
+		if errors.Is(err, errVersionChanged) {
+			continue
+		}
+		if err != nil {
+			return BulkUpdateResult{}, err
+		}
+		return newBulkUpdateResult(changes, true), nil
+	}
+
+	return BulkUpdateResult{}, ErrConcurrentUpdates
+}
+
+func (s *InventoryService) validateBulkUpdate(ctx context.Context, hotelID uuid.UUID, body BulkUpdateBody) ([]time.Time, error) {
+	from, to := time.Time(body.From), time.Time(body.To)
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return nil, ErrInvalidDateRange
+	}
+	if to.Sub(from) > maxRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range can't exceed %d days", ErrInvalidDateRange, maxRangeDays)
+	}
+
+	switch body.Operation {
+	case OperationSet:
+		if body.TotalInventory == nil {
+			return nil, fmt.Errorf("%w: set requires totalInventory", ErrInvalidOperation)
+		}
+	case OperationAdjust:
+		if body.Delta == nil || *body.Delta == 0 {
+			return nil, fmt.Errorf("%w: adjust requires a non-zero delta", ErrInvalidOperation)
+		}
+	}
+
+	_, err := s.queries.GetRoomTypeByIdAndHotelId(ctx, database.GetRoomTypeByIdAndHotelIdParams{
+		ID:      body.RoomTypeID,
+		HotelID: hotelID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room type %q: %w", body.RoomTypeID, err)
+	}
+
+	return shared.DatesInRange(from, to, body.Weekdays), nil
+}
+
+// change is a planned DateResult along with what is needed to write it.
+type change struct {
+	DateResult
+	date    time.Time
+	version int64
+}
+
+func (s *InventoryService) plan(ctx context.Context, q *database.Queries, hotelID uuid.UUID, body BulkUpdateBody, dates []time.Time) ([]change, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	inventory, err := q.GetRoomTypeInventoryForDates(ctx, database.GetRoomTypeInventoryForDatesParams{
+		HotelID:    hotelID,
+		RoomTypeID: body.RoomTypeID,
+		Dates:      dates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory of room type %q: %w", body.RoomTypeID, err)
+	}
+
+	restrictions, err := q.GetRoomTypeRestrictionsForRange(ctx, database.GetRoomTypeRestrictionsForRangeParams{
+		HotelID:    hotelID,
+		RoomTypeID: body.RoomTypeID,
+		DateFrom:   dates[0],
+		DateTo:     dates[len(dates)-1],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restrictions of room type %q: %w", body.RoomTypeID, err)
+	}
+
+	byDate := make(map[string]database.BookingRoomTypeInventory, len(inventory))
+	for _, inv := range inventory {
+		byDate[inv.Date.Format(time.DateOnly)] = inv
+	}
+	stopSell := make(map[string]bool, len(restrictions))
+	for _, r := range restrictions {
+		stopSell[r.Date.Format(time.DateOnly)] = r.StopSell
+	}
+
+	changes := make([]change, 0, len(dates))
+	for _, date := range dates {
+		key := date.Format(time.DateOnly)
+		inv, ok := byDate[key]
+		changes = append(changes, planDate(body, date, inv, ok, stopSell[key]))
+	}
+	return changes, nil
+}
+
+func planDate(body BulkUpdateBody, date time.Time, inv database.BookingRoomTypeInventory, exists bool, stopSell bool) change {
+	c := change{
+		DateResult: DateResult{
+			Date:        shared.Date(date),
+			StopSell:    stopSell,
+			NewStopSell: stopSell,
+		},
+		date: date,
+	}
+
+	if !exists {
+		if body.Operation != OperationSet {
+			c.Status = DateStatusConflict
+			c.Reason = "no inventory for this date"
+			return c
+		}
+		c.NewTotalInventory = body.TotalInventory
+		c.Status = DateStatusCreated
+		return c
+	}
+
+	current := inv.TotalInventory
+	c.TotalInventory = &current
+	c.TotalReserved = inv.TotalReserved
+	c.Version = inv.Version
+	c.version = inv.Version
+
+	next := current
+	switch body.Operation {
+	case OperationSet:
+		next = *body.TotalInventory
+	case OperationAdjust:
+		next = current + *body.Delta
+	case OperationClose:
+		c.NewStopSell = true
+	case OperationOpen:
+		c.NewStopSell = false
+	}
+	c.NewTotalInventory = &next
+
+	switch {
+	case next < 0:
+		c.Status = DateStatusConflict
+		c.Reason = "inventory can't be negative"
+	// Overbooking lets reservations exceed the count, such nights can still be closed, opened or raised.
+	case next < current && next < inv.TotalReserved:
+		c.Status = DateStatusConflict
+		c.Reason = fmt.Sprintf("%d rooms are reserved", inv.TotalReserved)
+	case next == current && c.NewStopSell == stopSell:
+		c.Status = DateStatusUnchanged
+	default:
+		c.Status = DateStatusUpdated
+	}
+
+	if c.Status == DateStatusConflict {
+		c.NewTotalInventory = &current
+	}
+	return c
+}
+
+// apply plans and writes the changes in one transaction. It returns errVersionChanged when a date
+// changed after it was read, the caller starts over with fresh data.
+func (s *InventoryService) apply(ctx context.Context, hotelID uuid.UUID, body BulkUpdateBody, dates []time.Time) ([]change, error) {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start bulk inventory transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	changes, err := s.plan(ctx, qtx, hotelID, body, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed, stopSellChanged []time.Time
+	for _, c := range changes {
+		var affected int64
+		switch c.Status {
+		case DateStatusCreated:
+			affected, err = qtx.InsertRoomTypeInventoryForDate(ctx, database.InsertRoomTypeInventoryForDateParams{
+				HotelID:        hotelID,
+				RoomTypeID:     body.RoomTypeID,
+				Date:           c.date,
+				TotalInventory: *c.NewTotalInventory,
+			})
+		case DateStatusUpdated:
+			// Runs for close and open too, so they are versioned like count changes.
+			affected, err = qtx.SetRoomTypeInventoryTotal(ctx, database.SetRoomTypeInventoryTotalParams{
+				TotalInventory: *c.NewTotalInventory,
+				HotelID:        hotelID,
+				RoomTypeID:     body.RoomTypeID,
+				Date:           c.date,
+				Version:        c.version,
+			})
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update inventory of %s: %w", c.date.Format(time.DateOnly), err)
+		}
+		if affected == 0 {
+			return nil, errVersionChanged
+		}
+
+		changed = append(changed, c.date)
+		if c.NewStopSell != c.StopSell {
+			stopSellChanged = append(stopSellChanged, c.date)
+		}
+	}
+
+	if len(changed) == 0 {
+		return changes, nil
+	}
+
+	if len(stopSellChanged) > 0 {
+		if _, err := qtx.SetRoomTypeStopSell(ctx, database.SetRoomTypeStopSellParams{
+			HotelID:    hotelID,
+			RoomTypeID: body.RoomTypeID,
+			Dates:      stopSellChanged,
+			StopSell:   body.Operation == OperationClose,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update stop-sell of room type %q: %w", body.RoomTypeID, err)
+		}
+	}
+
+	if err := s.fillVersions(ctx, qtx, hotelID, body.RoomTypeID, changes, changed); err != nil {
+		return nil, err
+	}
+
+	// Channels and webhook subscribers re-read the range, so one event covers every changed date.
+	err = events.Record(ctx, qtx, events.InventoryChanged, events.AggregateRoomType, body.RoomTypeID, events.InventoryChangedPayload{
+		HotelID:    hotelID,
+		RoomTypeID: body.RoomTypeID,
+		From:       shared.Date(changed[0]),
+		To:         shared.Date(changed[len(changed)-1]),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk inventory update: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (s *InventoryService) fillVersions(ctx context.Context, qtx *database.Queries, hotelID, roomTypeID uuid.UUID, changes []change, changed []time.Time) error {
+	inventory, err := qtx.GetRoomTypeInventoryForDates(ctx, database.GetRoomTypeInventoryForDatesParams{
+		HotelID:    hotelID,
+		RoomTypeID: roomTypeID,
+		Dates:      changed,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get updated inventory of room type %q: %w", roomTypeID, err)
+	}
+
+	versions := make(map[string]int64, len(inventory))
+	for _, inv := range inventory {
+		versions[inv.Date.Format(time.DateOnly)] = inv.Version
+	}
+	for i := range changes {
+		if v, ok := versions[changes[i].date.Format(time.DateOnly)]; ok {
+			changes[i].Version = v
+		}
+	}
+	return nil
+}
+
+func newBulkUpdateResult(changes []change, applied bool) BulkUpdateResult {
+	result := BulkUpdateResult{
+		Applied:   applied,
+		Dates:     make([]DateResult, 0, len(changes)),
+		Conflicts: []DateResult{},
+	}
+	for _, c := range changes {
+		result.Dates = append(result.Dates, c.DateResult)
+		if c.Status == DateStatusConflict {
+			result.Conflicts = append(result.Conflicts, c.DateResult)
+		}
+	}
+	return result
+}
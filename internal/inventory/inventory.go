@@ -0,0 +1,67 @@
+// Package inventory lets revenue managers change room type inventory and sales status over date ranges.
+package inventory
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrRoomTypeNotFound  = errors.New("inventoryService: Room type not found")
+	ErrInvalidDateRange  = errors.New("inventoryService: Invalid date range")
+	ErrInvalidOperation  = errors.New("inventoryService: Invalid operation")
+	ErrConcurrentUpdates = errors.New("inventoryService: Inventory keeps changing, retry later")
+)
+
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrRoomTypeNotFound:  {Status: http.StatusNotFound, Code: "room_type.not_found", Message: "Room type not found"},
+		ErrInvalidDateRange:  {Status: http.StatusBadRequest, Code: "request.invalid_date_range"},
+		ErrInvalidOperation:  {Status: http.StatusBadRequest, Code: "inventory.invalid_operation"},
+		ErrConcurrentUpdates: {Status: http.StatusConflict, Code: "inventory.concurrent_update", Message: "Inventory keeps changing, retry later"},
+	})
+}
+
+type Operation string
+
+const (
+	OperationSet    Operation = "set"
+	OperationAdjust Operation = "adjust"
+	// Close and open toggle stop-sell, inventory counts stay as they are.
+	OperationClose Operation = "close"
+	OperationOpen  Operation = "open"
+)
+
+type DateStatus string
+
+const (
+	DateStatusUpdated   DateStatus = "updated"
+	DateStatusCreated   DateStatus = "created"
+	DateStatusUnchanged DateStatus = "unchanged"
+	DateStatusConflict  DateStatus = "conflict"
+)
+
+const (
+	// Matches the window the inventory cron generates ahead.
+	maxRangeDays = 2 * 366
+	// Applying is retried when a reservation bumps the version of a date between reading and writing it.
+	maxApplyAttempts = 3
+)
+
+type InventoryService struct {
+	queries   *database.Queries
+	validator *validator.Validate
+	db        database.Service
+}
+
+func New(queries *database.Queries, validator *validator.Validate, db database.Service) *InventoryService {
+	return &InventoryService{
+		queries:   queries,
+		validator: validator,
+		db:        db,
+	}
+}
@@ -0,0 +1,53 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RegisterHotelHandlers mounts bulk inventory management. The router is expected to carry a {hotelId} URL parameter.
+func (s *InventoryService) RegisterHotelHandlers(r chi.Router) {
+	r.Post("/bulk/preview", s.PreviewBulkUpdateHandler)
+	r.Post("/bulk", s.BulkUpdateHandler)
+}
+
+func (s *InventoryService) PreviewBulkUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	s.handleBulkUpdate(w, r, s.previewBulkUpdate)
+}
+
+func (s *InventoryService) BulkUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	s.handleBulkUpdate(w, r, s.bulkUpdate)
+}
+
+func (s *InventoryService) handleBulkUpdate(w http.ResponseWriter, r *http.Request, run func(context.Context, uuid.UUID, BulkUpdateBody) (BulkUpdateResult, error)) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	var body BulkUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	result, err := run(r.Context(), hotelID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"result": result})
+}
@@ -0,0 +1,176 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+// Discrepancy is a date whose total_reserved disagrees with the reservations holding it.
+type Discrepancy struct {
+	HotelID        uuid.UUID   `json:"hotel_id"`
+	RoomTypeID     uuid.UUID   `json:"room_type_id"`
+	Date           shared.Date `json:"date"`
+	TotalInventory int32       `json:"total_inventory"`
+	TotalReserved  int32       `json:"total_reserved"`
+	ActualReserved int32       `json:"actual_reserved"`
+	Repaired       bool        `json:"repaired"`
+}
+
+type ReconcileReport struct {
+	HotelID       uuid.UUID     `json:"hotel_id"`
+	From          shared.Date   `json:"from"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+	// Unrepaired counts discrepancies left in place, either because repair was off or bookings kept changing them.
+	Unrepaired int `json:"unrepaired"`
+}
+
+// Reconcile recounts reserved rooms of a hotel's inventory from the given date on. With repair, drifted dates
+// are overwritten with the recount. Each write is guarded by the version read along with the recount,
+// so a booking landing in between makes the date be recounted in the next pass instead of being lost.
+func (s *InventoryService) Reconcile(ctx context.Context, hotelID uuid.UUID, from time.Time, repair bool) (ReconcileReport, error) {
+	report := ReconcileReport{
+		HotelID:       hotelID,
+		From:          shared.Date(from),
+		Discrepancies: []Discrepancy{},
+	}
+	index := make(map[string]int)
+
+	for attempt := 0; attempt < maxApplyAttempts; attempt++ {
+		drift, err := s.queries.GetRoomTypeInventoryDrift(ctx, database.GetRoomTypeInventoryDriftParams{
+			HotelID:  hotelID,
+			Statuses: holdingStatuses(),
+			DateFrom: from,
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to recount inventory of hotel %q: %w", hotelID, err)
+		}
+
+		for _, row := range drift {
+			key := driftKey(row.RoomTypeID, row.Date)
+			if i, ok := index[key]; ok {
+				report.Discrepancies[i].ActualReserved = row.ActualReserved
+				continue
+			}
+			index[key] = len(report.Discrepancies)
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				HotelID:        row.HotelID,
+				RoomTypeID:     row.RoomTypeID,
+				Date:           shared.Date(row.Date),
+				TotalInventory: row.TotalInventory,
+				TotalReserved:  row.TotalReserved,
+				ActualReserved: row.ActualReserved,
+			})
+		}
+
+		if !repair || len(drift) == 0 {
+			break
+		}
+
+		repaired, err := s.repair(ctx, hotelID, drift)
+		if err != nil {
+			return report, err
+		}
+		for _, row := range repaired {
+			report.Discrepancies[index[driftKey(row.RoomTypeID, row.Date)]].Repaired = true
+		}
+		if len(repaired) == len(drift) {
+			break
+		}
+	}
+
+	for _, d := range report.Discrepancies {
+		if !d.Repaired {
+			report.Unrepaired++
+		}
+	}
+	return report, nil
+}
+
+// repair overwrites total_reserved of the drifted dates and returns the ones it could write.
+func (s *InventoryService) repair(ctx context.Context, hotelID uuid.UUID, drift []database.GetRoomTypeInventoryDriftRow) ([]database.GetRoomTypeInventoryDriftRow, error) {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start inventory repair transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	repaired := make([]database.GetRoomTypeInventoryDriftRow, 0, len(drift))
+	for _, row := range drift {
+		affected, err := qtx.RepairRoomTypeInventoryReserved(ctx, database.RepairRoomTypeInventoryReservedParams{
+			TotalReserved: row.ActualReserved,
+			HotelID:       hotelID,
+			RoomTypeID:    row.RoomTypeID,
+			Date:          row.Date,
+			Version:       row.Version,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to repair inventory of %s: %w", row.Date.Format(time.DateOnly), err)
+		}
+		if affected == 1 {
+			repaired = append(repaired, row)
+		}
+	}
+
+	if err := recordRepairs(ctx, qtx, hotelID, repaired); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit inventory repair: %w", err)
+	}
+
+	return repaired, nil
+}
+
+// recordRepairs records an InventoryChanged event per run of consecutive dates repaired by the same delta.
+// Rows are ordered by room type and date.
+func recordRepairs(ctx context.Context, qtx *database.Queries, hotelID uuid.UUID, repaired []database.GetRoomTypeInventoryDriftRow) error {
+	for start := 0; start < len(repaired); {
+		first := repaired[start]
+		delta := first.ActualReserved - first.TotalReserved
+
+		end := start + 1
+		for end < len(repaired) {
+			next, prev := repaired[end], repaired[end-1]
+			if next.RoomTypeID != first.RoomTypeID || !next.Date.Equal(prev.Date.AddDate(0, 0, 1)) || next.ActualReserved-next.TotalReserved != delta {
+				break
+			}
+			end++
+		}
+
+		err := events.Record(ctx, qtx, events.InventoryChanged, events.AggregateRoomType, first.RoomTypeID, events.InventoryChangedPayload{
+			HotelID:       hotelID,
+			RoomTypeID:    first.RoomTypeID,
+			From:          shared.Date(first.Date),
+			To:            shared.Date(repaired[end-1].Date),
+			ReservedDelta: delta,
+		})
+		if err != nil {
+			return err
+		}
+
+		start = end
+	}
+	return nil
+}
+
+func holdingStatuses() []string {
+	statuses := make([]string, 0, len(reservation.HoldingStatuses))
+	for _, s := range reservation.HoldingStatuses {
+		statuses = append(statuses, string(s))
+	}
+	return statuses
+}
+
+func driftKey(roomTypeID uuid.UUID, date time.Time) string {
+	return roomTypeID.String() + "/" + date.Format(time.DateOnly)
+}
@@ -0,0 +1,428 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/lock"
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+)
+
+// Partition selects the hotels a run covers. An empty time zone matches every zone.
+type Partition struct {
+	TimeZone string
+	Shard    int
+	Shards   int
+}
+
+func (p Partition) Key() string {
+	timeZone := p.TimeZone
+	if timeZone == "" {
+		timeZone = "*"
+	}
+	return fmt.Sprintf("tz=%s,shard=%d/%d", timeZone, p.Shard, p.Shards)
+}
+
+// hotelError is a failure populating a hotel, roomTypeID is empty when it's not specific to a room type.
+type hotelError struct {
+	roomTypeID uuid.NullUUID
+	err        error
+}
+
+// RunInventory extends the inventory of every hotel in the partition up to the end of the window. Each
+// populated hotel is checkpointed, so a run that crashed or was stopped is picked up by the next run of the
+// same partition, which skips the hotels already done. Hotels that fail leave the run failed, the next run
+// starts over and only fills what is still missing.
+func (s *JobService) RunInventory(ctx context.Context, partition Partition, workers, windowDays int) (_ database.BookingInventoryCronRun, err error) {
+	ctx, span := tracing.Start(ctx, "jobs.inventory_run", attribute.String("job.partition", partition.Key()))
+	defer func() { tracing.End(span, err) }()
+
+	run, done, err := s.startOrResumeRun(ctx, partition.Key(), windowDays)
+	if err != nil {
+		return database.BookingInventoryCronRun{}, err
+	}
+
+	hotels, err := s.queries.GetActiveHotelsInPartition(ctx, database.GetActiveHotelsInPartitionParams{
+		TimeZone:   partition.TimeZone,
+		ShardCount: int32(partition.Shards),
+		ShardID:    int32(partition.Shard),
+	})
+	if err != nil {
+		return run, fmt.Errorf("failed to retrieve active hotels: %w", err)
+	}
+
+	pending := make([]database.BookingHotel, 0, len(hotels))
+	for _, hotel := range hotels {
+		if !done[hotel.ID] {
+			pending = append(pending, hotel)
+		}
+	}
+
+	slog.InfoContext(ctx, "Starting inventory population",
+		"run_id", run.ID,
+		"partition", run.PartitionKey,
+		"hotel_count", len(hotels),
+		"already_done", len(hotels)-len(pending),
+		"window_end", run.WindowEnd.Format(time.DateOnly))
+
+	return s.execute(ctx, run, pending, workers)
+}
+
+// triggeredRun is a run triggered for a hotel, handed to RunTriggeredWorker along with the lease it holds.
+type triggeredRun struct {
+	run   database.BookingInventoryCronRun
+	hotel database.BookingHotel
+	lease *lock.Lease
+}
+
+// triggerHotelInventory starts a run for a single hotel on RunTriggeredWorker and returns it right away.
+func (s *JobService) triggerHotelInventory(ctx context.Context, hotelID uuid.UUID) (database.BookingInventoryCronRun, error) {
+	hotel, err := s.queries.GetHotelById(ctx, hotelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingInventoryCronRun{}, ErrHotelNotFound
+	}
+	if err != nil {
+		return database.BookingInventoryCronRun{}, fmt.Errorf("failed to get hotel %q: %w", hotelID, err)
+	}
+
+	// The lease is taken before the run is stored, a running run whose lock is free was abandoned.
+	partitionKey := "hotel=" + hotelID.String()
+	lease, err := s.locker.TryLease(ctx, LockName(partitionKey), lockHeartbeat)
+	if errors.Is(err, lock.ErrLocked) {
+		return database.BookingInventoryCronRun{}, ErrRunInProgress
+	}
+	if err != nil {
+		return database.BookingInventoryCronRun{}, fmt.Errorf("failed to take lock: %w", err)
+	}
+
+	run, err := s.queries.CreateInventoryCronRun(ctx, database.CreateInventoryCronRunParams{
+		ID:           uuid.New(),
+		PartitionKey: partitionKey,
+		WindowEnd:    windowEnd(DefaultWindowDays),
+		HotelID:      uuid.NullUUID{UUID: hotelID, Valid: true},
+	})
+	if err != nil {
+		s.releaseLease(ctx, lease)
+		return database.BookingInventoryCronRun{}, fmt.Errorf("failed to start run: %w", err)
+	}
+
+	select {
+	case s.triggered <- triggeredRun{run: run, hotel: hotel, lease: lease}:
+		return run, nil
+	case <-s.stopped:
+		err = ErrStopped
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.interrupt(context.WithoutCancel(ctx), run.ID)
+	s.releaseLease(ctx, lease)
+	return database.BookingInventoryCronRun{}, err
+}
+
+// RunTriggeredWorker executes the runs triggered through the API until ctx is done, then waits for the runs
+// in progress to stop and records them as interrupted. Runs a crashed process left running are recorded as
+// interrupted when it starts.
+func (s *JobService) RunTriggeredWorker(ctx context.Context) {
+	s.interruptAbandoned(ctx)
+
+	var wg sync.WaitGroup
+	for {
+		select {
+		case <-ctx.Done():
+			close(s.stopped)
+			wg.Wait()
+			return
+		case t := <-s.triggered:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.executeTriggered(ctx, t)
+			}()
+		}
+	}
+}
+
+func (s *JobService) executeTriggered(ctx context.Context, t triggeredRun) {
+	defer s.releaseLease(ctx, t.lease)
+
+	// Losing the lease stops the run like a shutdown does.
+	runCtx, cancel := t.lease.Context(ctx)
+	_, err := s.execute(runCtx, t.run, []database.BookingHotel{t.hotel}, 1)
+	cancel()
+	if err != nil {
+		slog.ErrorContext(ctx, "Triggered inventory run failed", "run_id", t.run.ID, "hotel_id", t.hotel.ID, "error", err)
+	}
+
+	// A run stopped before finishing would otherwise stay running, nothing resumes triggered runs.
+	s.interrupt(context.WithoutCancel(ctx), t.run.ID)
+}
+
+// interruptAbandoned records the triggered runs left running by a process that's gone as interrupted.
+func (s *JobService) interruptAbandoned(ctx context.Context) {
+	runs, err := s.queries.ListRunningTriggeredInventoryCronRuns(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list running triggered runs", "error", err)
+		return
+	}
+
+	for _, run := range runs {
+		held, err := s.locker.TryLock(ctx, LockName(run.PartitionKey))
+		if errors.Is(err, lock.ErrLocked) {
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to check run lock", "run_id", run.ID, "error", err)
+			continue
+		}
+
+		s.interrupt(ctx, run.ID)
+		if err := held.Unlock(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to release lock", "lock", held.Name(), "error", err)
+		}
+	}
+}
+
+func (s *JobService) interrupt(ctx context.Context, runID uuid.UUID) {
+	if err := s.queries.InterruptInventoryCronRun(ctx, runID); err != nil {
+		slog.ErrorContext(ctx, "Failed to record run as interrupted", "run_id", runID, "error", err)
+	}
+}
+
+func (s *JobService) releaseLease(ctx context.Context, lease *lock.Lease) {
+	if err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+		slog.ErrorContext(ctx, "Failed to release lock", "lock", lease.Name(), "error", err)
+	}
+}
+
+func (s *JobService) execute(ctx context.Context, run database.BookingInventoryCronRun, hotels []database.BookingHotel, workers int) (database.BookingInventoryCronRun, error) {
+	var failed atomic.Int32
+	g := new(errgroup.Group)
+	g.SetLimit(workers)
+
+	for _, hotel := range hotels {
+		if ctx.Err() != nil {
+			break
+		}
+
+		g.Go(func() error {
+			ctx, span := tracing.Start(ctx, "jobs.populate_hotel", attribute.String("hotel.id", hotel.ID.String()))
+
+			rows, errs := s.populateHotel(ctx, hotel, run.WindowEnd)
+			if err := s.recordHotel(ctx, run.ID, hotel.ID, rows, errs); err != nil {
+				errs = append(errs, hotelError{err: err})
+			}
+			span.SetAttributes(attribute.Int64("inventory.rows_inserted", rows))
+			if len(errs) > 0 {
+				failed.Add(1)
+				slog.ErrorContext(ctx, "Failed to update inventory for hotel!",
+					"hotel_id", hotel.ID,
+					"error", errs[0].err,
+					"error_count", len(errs))
+				tracing.End(span, errs[0].err)
+				return nil
+			}
+
+			slog.InfoContext(ctx, "Successfully updated hotel", "hotel_id", hotel.ID, "rows_inserted", rows)
+			tracing.End(span, nil)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	// Stopped runs stay running so the next run resumes them.
+	if err := ctx.Err(); err != nil {
+		return run, fmt.Errorf("run %s interrupted: %w", run.ID, err)
+	}
+
+	status := RunStatusCompleted
+	if failed.Load() > 0 {
+		status = RunStatusFailed
+	}
+
+	if err := s.queries.FinishInventoryCronRun(ctx, database.FinishInventoryCronRunParams{
+		ID:           run.ID,
+		Status:       string(status),
+		FailedHotels: failed.Load(),
+	}); err != nil {
+		return run, fmt.Errorf("failed to finish run %s: %w", run.ID, err)
+	}
+
+	finished, err := s.queries.GetInventoryCronRun(ctx, run.ID)
+	if err != nil {
+		return run, fmt.Errorf("failed to get run %s: %w", run.ID, err)
+	}
+	run = finished
+
+	if status == RunStatusFailed {
+		return run, fmt.Errorf("%w: %d of %d", ErrRunFailed, failed.Load(), len(hotels))
+	}
+
+	slog.InfoContext(ctx, "Inventory population completed", "run_id", run.ID, "rows_inserted", run.RowsInserted)
+	return run, nil
+}
+
+// recordHotel adds the hotel's rows to the run and stores its errors. Only hotels without errors are
+// checkpointed, the others are retried when the run is resumed.
+func (s *JobService) recordHotel(ctx context.Context, runID, hotelID uuid.UUID, rows int64, errs []hotelError) error {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start run progress transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	processed := int32(0)
+	if len(errs) == 0 {
+		processed = 1
+		if err := qtx.CreateInventoryCronCheckpoint(ctx, database.CreateInventoryCronCheckpointParams{
+			RunID:   runID,
+			HotelID: hotelID,
+		}); err != nil {
+			return fmt.Errorf("failed to checkpoint hotel: %w", err)
+		}
+	}
+
+	if err := qtx.AddInventoryCronRunProgress(ctx, database.AddInventoryCronRunProgressParams{
+		ID:              runID,
+		HotelsProcessed: processed,
+		RowsInserted:    rows,
+	}); err != nil {
+		return fmt.Errorf("failed to record run progress: %w", err)
+	}
+
+	for _, e := range errs {
+		if err := qtx.CreateInventoryCronRunError(ctx, database.CreateInventoryCronRunErrorParams{
+			RunID:      runID,
+			HotelID:    hotelID,
+			RoomTypeID: e.roomTypeID,
+			Error:      e.err.Error(),
+		}); err != nil {
+			return fmt.Errorf("failed to record run error: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// startOrResumeRun returns the run of the partition left running by a previous process, along with its
+// checkpointed hotels, or starts a new one.
+func (s *JobService) startOrResumeRun(ctx context.Context, partitionKey string, windowDays int) (database.BookingInventoryCronRun, map[uuid.UUID]bool, error) {
+	done := make(map[uuid.UUID]bool)
+
+	run, err := s.queries.GetUnfinishedInventoryCronRun(ctx, partitionKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		run, err = s.queries.CreateInventoryCronRun(ctx, database.CreateInventoryCronRunParams{
+			ID:           uuid.New(),
+			PartitionKey: partitionKey,
+			WindowEnd:    windowEnd(windowDays),
+		})
+		if err != nil {
+			return database.BookingInventoryCronRun{}, nil, fmt.Errorf("failed to start run: %w", err)
+		}
+		return run, done, nil
+	}
+	if err != nil {
+		return database.BookingInventoryCronRun{}, nil, fmt.Errorf("failed to get unfinished run: %w", err)
+	}
+
+	hotelIDs, err := s.queries.GetInventoryCronCheckpoints(ctx, run.ID)
+	if err != nil {
+		return database.BookingInventoryCronRun{}, nil, fmt.Errorf("failed to get checkpoints of run %s: %w", run.ID, err)
+	}
+	for _, id := range hotelIDs {
+		done[id] = true
+	}
+
+	slog.Info("Resuming unfinished run", "run_id", run.ID, "started_at", run.StartedAt)
+	return run, done, nil
+}
+
+// populateHotel fills the dates between the last inventory of each room type and the window end, and
+// returns the number of rows inserted. The room types are inserted in one batch, so a failing one leaves the
+// whole hotel to the next run.
+func (s *JobService) populateHotel(ctx context.Context, hotel database.BookingHotel, windowEnd time.Time) (int64, []hotelError) {
+	today, err := hotelToday(hotel)
+	if err != nil {
+		return 0, []hotelError{{err: err}}
+	}
+
+	ends, err := s.queries.GetHotelRoomTypeInventoryEnds(ctx, database.GetHotelRoomTypeInventoryEndsParams{
+		FromDate: today,
+		HotelID:  hotel.ID,
+	})
+	if err != nil {
+		return 0, []hotelError{{err: fmt.Errorf("failed to get inventory ends: %w", err)}}
+	}
+
+	var params []database.BatchUpdateRoomTypeInventoryParams
+	for _, end := range ends {
+		var dates []time.Time
+		for d := end.LastDate.AddDate(0, 0, 1); !d.After(windowEnd); d = d.AddDate(0, 0, 1) {
+			dates = append(dates, d)
+		}
+		if len(dates) == 0 {
+			continue
+		}
+
+		params = append(params, database.BatchUpdateRoomTypeInventoryParams{
+			Dates:          dates,
+			HotelID:        hotel.ID,
+			RoomTypeID:     end.RoomTypeID,
+			TotalInventory: plannedCapacity,
+		})
+	}
+	if len(params) == 0 {
+		return 0, nil
+	}
+
+	rows, err := database.BatchUpdateRoomTypeInventories(ctx, s.db.Pool(), params)
+	if err != nil {
+		var batchErr *database.BatchError
+		if errors.As(err, &batchErr) {
+			return 0, []hotelError{{
+				roomTypeID: uuid.NullUUID{UUID: params[batchErr.Index].RoomTypeID, Valid: true},
+				err:        fmt.Errorf("failed to insert inventory: %w", batchErr.Err),
+			}}
+		}
+		return 0, []hotelError{{err: fmt.Errorf("failed to insert inventory: %w", err)}}
+	}
+
+	var inserted int64
+	for i, arg := range params {
+		inserted += rows[i]
+		slog.InfoContext(ctx, "successfully updated room type inventory",
+			"hotel_id", hotel.ID,
+			"room_type_id", arg.RoomTypeID,
+			"from", arg.Dates[0].Format(time.DateOnly),
+			"rows_inserted", rows[i])
+	}
+
+	return inserted, nil
+}
+
+// hotelToday returns the current date at the hotel as a UTC midnight, the way inventory dates are stored.
+func hotelToday(hotel database.BookingHotel) (time.Time, error) {
+	loc, err := time.LoadLocation(hotel.TimeZone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time zone %q: %w", hotel.TimeZone, err)
+	}
+	y, m, d := time.Now().In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+}
+
+func windowEnd(windowDays int) time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, windowDays)
+}
@@ -0,0 +1,71 @@
+// Package jobs runs the inventory population job and keeps a record of its runs for operators.
+package jobs
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/lock"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+)
+
+var (
+	ErrJobNotFound   = errors.New("jobService: Job not found")
+	ErrHotelNotFound = errors.New("jobService: Hotel not found")
+	ErrRunFailed     = errors.New("jobService: Some hotels failed")
+	ErrRunInProgress = errors.New("jobService: Run in progress")
+	ErrStopped       = errors.New("jobService: Stopped")
+)
+
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrJobNotFound:   {Status: http.StatusNotFound, Code: "job.not_found", Message: "Job not found"},
+		ErrHotelNotFound: {Status: http.StatusNotFound, Code: "hotel.not_found", Message: "Hotel not found"},
+		ErrRunInProgress: {Status: http.StatusConflict, Code: "job.run_in_progress", Message: "An inventory run of the hotel is already in progress"},
+		ErrStopped:       {Status: http.StatusServiceUnavailable, Code: "job.stopped", Message: "Jobs are not accepted while the server shuts down"},
+	})
+}
+
+type RunState string
+
+const (
+	RunStatusRunning   RunState = "running"
+	RunStatusCompleted RunState = "completed"
+	RunStatusFailed    RunState = "failed"
+	// Interrupted runs were triggered for a hotel and stopped before finishing, they aren't resumed.
+	RunStatusInterrupted RunState = "interrupted"
+)
+
+const (
+	// DefaultWindowDays is how far ahead inventory is kept.
+	DefaultWindowDays = 2 * 365
+	// Mocked approach for inventory management. Usually calculated and provided by business
+	plannedCapacity = 30
+	// How often a triggered run checks it still holds the lock of its partition.
+	lockHeartbeat = 10 * time.Second
+)
+
+type JobService struct {
+	queries   *database.Queries
+	db        database.Service
+	locker    *lock.Locker
+	triggered chan triggeredRun
+	stopped   chan struct{}
+}
+
+func New(queries *database.Queries, db database.Service) *JobService {
+	return &JobService{
+		queries:   queries,
+		db:        db,
+		locker:    lock.New(db.GetDB()),
+		triggered: make(chan triggeredRun),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// LockName is the lock a run of the partition holds, so runs of the same partition don't overlap.
+func LockName(partitionKey string) string {
+	return "inventory-cron:" + partitionKey
+}
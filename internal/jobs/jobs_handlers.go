@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultJobsLimit = 50
+	maxJobsLimit     = 500
+)
+
+func (s *JobService) RegisterAdminHandlers(r chi.Router) {
+	r.Get("/", s.ListJobsHandler)
+	r.Get("/{jobId}", s.GetJobHandler)
+	r.Post("/inventory/hotels/{hotelId}", s.TriggerHotelInventoryHandler)
+}
+
+func (s *JobService) ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	query := ListJobsQuery{Limit: defaultJobsLimit}
+
+	if hotelIDStr := r.URL.Query().Get("hotelId"); hotelIDStr != "" {
+		hotelID, err := uuid.Parse(hotelIDStr)
+		if err != nil {
+			shared.WriteError(w, http.StatusBadRequest, "Invalid hotelId")
+			return
+		}
+		query.HotelID = &hotelID
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxJobsLimit {
+			shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit, expected 1-%d", maxJobsLimit))
+			return
+		}
+		query.Limit = int32(limit)
+	}
+
+	jobs, err := s.listJobs(r.Context(), query)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to list jobs")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"jobs": jobs})
+}
+
+func (s *JobService) GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := s.getJob(r.Context(), jobID)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"job": job})
+}
+
+// TriggerHotelInventoryHandler starts populating a single hotel's inventory. The run continues in the
+// background, poll the returned job for its outcome.
+func (s *JobService) TriggerHotelInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	run, err := s.triggerHotelInventory(r.Context(), hotelID)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusAccepted, shared.Envelope{"job": newJob(run)})
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type Job struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	PartitionKey string    `json:"partition_key"`
+	// HotelID is set for runs triggered for a single hotel.
+	HotelID         *uuid.UUID  `json:"hotel_id"`
+	Status          RunState    `json:"status"`
+	WindowEnd       shared.Date `json:"window_end"`
+	HotelsProcessed int32       `json:"hotels_processed"`
+	FailedHotels    int32       `json:"failed_hotels"`
+	RowsInserted    int64       `json:"rows_inserted"`
+	StartedAt       time.Time   `json:"started_at"`
+	FinishedAt      *time.Time  `json:"finished_at"`
+	Errors          []JobError  `json:"errors,omitempty"`
+}
+
+type JobError struct {
+	HotelID    uuid.UUID  `json:"hotel_id"`
+	RoomTypeID *uuid.UUID `json:"room_type_id"`
+	Error      string     `json:"error"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// inventoryJobName names inventory runs in the API, the only job tracked so far.
+const inventoryJobName = "inventory"
+
+func newJob(r database.BookingInventoryCronRun) Job {
+	job := Job{
+		ID:              r.ID,
+		Name:            inventoryJobName,
+		PartitionKey:    r.PartitionKey,
+		Status:          RunState(r.Status),
+		WindowEnd:       shared.Date(r.WindowEnd),
+		HotelsProcessed: r.HotelsProcessed,
+		FailedHotels:    r.FailedHotels,
+		RowsInserted:    r.RowsInserted,
+		StartedAt:       r.StartedAt,
+	}
+	if r.HotelID.Valid {
+		job.HotelID = &r.HotelID.UUID
+	}
+	if r.FinishedAt.Valid {
+		job.FinishedAt = &r.FinishedAt.Time
+	}
+	return job
+}
+
+type ListJobsQuery struct {
+	HotelID *uuid.UUID
+	Limit   int32
+}
+
+func (s *JobService) listJobs(ctx context.Context, query ListJobsQuery) ([]Job, error) {
+	params := database.ListInventoryCronRunsParams{RowLimit: query.Limit}
+	if query.HotelID != nil {
+		params.HotelID = uuid.NullUUID{UUID: *query.HotelID, Valid: true}
+	}
+
+	runs, err := s.queries.ListInventoryCronRuns(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	jobs := make([]Job, 0, len(runs))
+	for _, r := range runs {
+		jobs = append(jobs, newJob(r))
+	}
+	return jobs, nil
+}
+
+func (s *JobService) getJob(ctx context.Context, id uuid.UUID) (Job, error) {
+	run, err := s.queries.GetInventoryCronRun(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, ErrJobNotFound
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to get run %q: %w", id, err)
+	}
+
+	runErrors, err := s.queries.GetInventoryCronRunErrors(ctx, id)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to get errors of run %q: %w", id, err)
+	}
+
+	job := newJob(run)
+	job.Errors = make([]JobError, 0, len(runErrors))
+	for _, e := range runErrors {
+		jobError := JobError{
+			HotelID:   e.HotelID,
+			Error:     e.Error,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.RoomTypeID.Valid {
+			jobError.RoomTypeID = &e.RoomTypeID.UUID
+		}
+		job.Errors = append(job.Errors, jobError)
+	}
+	return job, nil
+}
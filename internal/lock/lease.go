@@ -0,0 +1,105 @@
+package lock
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Lease is a lock that checks it's still held every heartbeat. Postgres drops a session's locks when its
+// connection breaks, after which another process may take the lock, so a holder doing long work should
+// stop once Lost is closed.
+type Lease struct {
+	*Lock
+	lost      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeLost sync.Once
+}
+
+// TryLease takes the lock like TryLock and starts checking it every heartbeat.
+func (l *Locker) TryLease(ctx context.Context, name string, heartbeat time.Duration) (*Lease, error) {
+	lock, err := l.TryLock(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return newLease(lock, heartbeat), nil
+}
+
+// WaitLease takes the lock like WaitLock and starts checking it every heartbeat.
+func (l *Locker) WaitLease(ctx context.Context, name string, retry, heartbeat time.Duration) (*Lease, error) {
+	lock, err := l.WaitLock(ctx, name, retry)
+	if err != nil {
+		return nil, err
+	}
+	return newLease(lock, heartbeat), nil
+}
+
+func newLease(lock *Lock, heartbeat time.Duration) *Lease {
+	lease := &Lease{
+		Lock: lock,
+		lost: make(chan struct{}),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go lease.run(heartbeat)
+	return lease
+}
+
+func (l *Lease) run(heartbeat time.Duration) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), heartbeat)
+		held, err := l.held(ctx)
+		cancel()
+
+		if err != nil || !held {
+			slog.Error("Lost lock lease", "lock", l.name, "error", err)
+			l.closeLost.Do(func() { close(l.lost) })
+			return
+		}
+	}
+}
+
+// Lost is closed once a heartbeat finds the lock is no longer held.
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Context returns a copy of parent that is cancelled with ErrLost once the lease is lost.
+func (l *Lease) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		select {
+		case <-l.lost:
+			cancel(ErrLost)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// Release stops the heartbeat and unlocks. A lost lease has nothing left to unlock, its connection is discarded.
+func (l *Lease) Release(ctx context.Context) error {
+	close(l.stop)
+	<-l.done
+
+	select {
+	case <-l.lost:
+		l.discard()
+		return ErrLost
+	default:
+		return l.Unlock(ctx)
+	}
+}
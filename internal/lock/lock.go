@@ -0,0 +1,114 @@
+// Package lock provides named locks on Postgres session advisory locks, so only one process at a time
+// runs a cron job or background worker. A lock lives on a dedicated connection and is released when it's
+// unlocked or that connection closes, crashed holders never keep a lock.
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+)
+
+var (
+	ErrLocked = errors.New("lock: Held by another process")
+	ErrLost   = errors.New("lock: Lease lost")
+)
+
+type Locker struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Locker {
+	return &Locker{db: db}
+}
+
+// Key maps a lock name to the advisory lock key it's taken under.
+func Key(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+type Lock struct {
+	name    string
+	key     int64
+	conn    *sql.Conn
+	queries *database.Queries
+}
+
+func (l *Lock) Name() string {
+	return l.name
+}
+
+// TryLock takes the lock or returns ErrLocked right away when another session holds it.
+func (l *Locker) TryLock(ctx context.Context, name string) (*Lock, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock connection: %w", err)
+	}
+
+	lock := &Lock{
+		name:    name,
+		key:     Key(name),
+		conn:    conn,
+		queries: database.New(conn),
+	}
+
+	locked, err := lock.queries.TryAdvisoryLock(ctx, lock.key)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take lock %q: %w", name, err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrLocked
+	}
+
+	return lock, nil
+}
+
+// WaitLock retries TryLock every retry interval until it takes the lock or ctx is done.
+func (l *Locker) WaitLock(ctx context.Context, name string, retry time.Duration) (*Lock, error) {
+	ticker := time.NewTicker(retry)
+	defer ticker.Stop()
+
+	for {
+		lock, err := l.TryLock(ctx, name)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unlock releases the lock and returns its connection to the pool. When unlocking fails the connection
+// is discarded instead, ending the session releases the lock as well.
+func (l *Lock) Unlock(ctx context.Context) error {
+	if _, err := l.queries.AdvisoryUnlock(ctx, l.key); err != nil {
+		l.discard()
+		return fmt.Errorf("failed to release lock %q: %w", l.name, err)
+	}
+	return l.conn.Close()
+}
+
+// discard closes the lock's connection for good rather than returning it to the pool.
+func (l *Lock) discard() {
+	_ = l.conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = l.conn.Close()
+}
+
+// held reports whether the lock's session still holds it.
+func (l *Lock) held(ctx context.Context) (bool, error) {
+	return l.queries.HoldsAdvisoryLock(ctx, l.key)
+}
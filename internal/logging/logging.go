@@ -0,0 +1,211 @@
+// Package logging configures the slog handler shared by the API and the crons. Lines are JSON in production
+// and text locally, secrets and email addresses are redacted, and lines logged while serving a request carry
+// its request id, route, user and hotel.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New builds a logger writing lines of format at level and above to w. Levels are debug, info, warn and error.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redact}
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+
+	return slog.New(tracing.LogHandler(requestHandler{handler})), nil
+}
+
+// FromContext returns the default logger bound to ctx, lines it writes carry the fields of the request in ctx
+// even when logged without the *Context variants of slog.
+func FromContext(ctx context.Context) *slog.Logger {
+	return slog.New(boundHandler{Handler: slog.Default().Handler(), ctx: ctx})
+}
+
+// Add attaches attributes to every line logged for the request in ctx from now on, including the request line
+// written once it has been served. It does nothing outside a request.
+func Add(ctx context.Context, args ...any) {
+	if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+		f.add(args...)
+	}
+}
+
+// Middleware logs every request once it has been served, at error level for server errors. Middlewares and
+// handlers further down add to its line with Add.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := context.WithValue(r.Context(), fieldsKey{}, &fields{})
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Duration("duration", time.Since(start)),
+		}
+		// Paths of public routes hold feed tokens, so only the ones that matched nothing are logged.
+		if rctx := chi.RouteContext(ctx); rctx == nil || rctx.RoutePattern() == "" || status == http.StatusNotFound {
+			attrs = append(attrs, slog.String("path", r.URL.Path))
+		}
+		slog.LogAttrs(ctx, level, "Request served", attrs...)
+	})
+}
+
+type fieldsKey struct{}
+
+type fields struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+func (f *fields) add(args ...any) {
+	record := slog.NewRecord(time.Time{}, 0, "", 0)
+	record.Add(args...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record.Attrs(func(a slog.Attr) bool {
+		f.attrs = append(f.attrs, a)
+		return true
+	})
+}
+
+func (f *fields) snapshot() []slog.Attr {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]slog.Attr(nil), f.attrs...)
+}
+
+// requestHandler adds the fields of the request being served to the records.
+type requestHandler struct {
+	slog.Handler
+}
+
+func (h requestHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := middleware.GetReqID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+		record.AddAttrs(slog.String("route", rctx.RoutePattern()))
+		if hotelID := routeHotelID(rctx); hotelID != "" {
+			record.AddAttrs(slog.String("hotel_id", hotelID))
+		}
+	}
+	if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+		record.AddAttrs(f.snapshot()...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestHandler) WithGroup(name string) slog.Handler {
+	return requestHandler{h.Handler.WithGroup(name)}
+}
+
+// routeHotelID returns the hotel addressed by the route, hotel routes name it hotelId except for the hotel
+// itself.
+func routeHotelID(rctx *chi.Context) string {
+	if id := rctx.URLParam("hotelId"); id != "" {
+		return id
+	}
+	if strings.Contains(rctx.RoutePattern(), "/hotel/{id}") {
+		return rctx.URLParam("id")
+	}
+	return ""
+}
+
+// boundHandler logs every record with the context it was bound to.
+type boundHandler struct {
+	slog.Handler
+	ctx context.Context
+}
+
+func (h boundHandler) Handle(_ context.Context, record slog.Record) error {
+	return h.Handler.Handle(h.ctx, record)
+}
+
+func (h boundHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return boundHandler{Handler: h.Handler.WithAttrs(attrs), ctx: h.ctx}
+}
+
+func (h boundHandler) WithGroup(name string) slog.Handler {
+	return boundHandler{Handler: h.Handler.WithGroup(name), ctx: h.ctx}
+}
+
+const redacted = "[REDACTED]"
+
+var (
+	secretKeys = []string{"password", "secret", "token", "authorization", "cookie"}
+	// Keeps the domain, which is enough to tell guests of a partner apart without identifying them.
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+)
+
+// redact hides the values of secret attributes and the local part of email addresses, errors included.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	switch v := a.Value.Resolve(); {
+	case v.Kind() == slog.KindString:
+		return slog.String(a.Key, redactEmails(v.String()))
+	case v.Kind() == slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, redactEmails(err.Error()))
+		}
+	}
+	return a
+}
+
+func redactEmails(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	return emailPattern.ReplaceAllString(s, "***@$1")
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// InventoryRun is the outcome of an inventory cron run.
+type InventoryRun struct {
+	Partition       string
+	HotelsProcessed int32
+	RowsWritten     int64
+	Duration        time.Duration
+}
+
+// PushInventoryRun sends the gauges of a run to a Pushgateway, the cron exits before Prometheus could scrape
+// it. Runs of each partition replace the previous values of that partition.
+func PushInventoryRun(ctx context.Context, gatewayURL string, run InventoryRun) error {
+	gauge := func(name, help string, value float64) prometheus.Gauge {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "inventory_cron",
+			Name:      name,
+			Help:      help,
+		})
+		g.Set(value)
+		return g
+	}
+
+	return push.New(gatewayURL, "inventory_cron").
+		Grouping("partition", run.Partition).
+		Collector(gauge("hotels_processed", "Hotels whose inventory the last run populated.", float64(run.HotelsProcessed))).
+		Collector(gauge("rows_written", "Inventory rows the last run inserted.", float64(run.RowsWritten))).
+		Collector(gauge("duration_seconds", "Duration of the last run.", run.Duration.Seconds())).
+		Collector(gauge("last_run_timestamp_seconds", "When the last run finished.", float64(time.Now().Unix()))).
+		PushContext(ctx)
+}
@@ -0,0 +1,98 @@
+// Package metrics exposes the Prometheus metrics of the API: request latency per route, database pool stats
+// and the booking counters on-call alerts on.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "hotel"
+
+// Registry holds the metrics of the process. It is separate from the default registry so packages pulled in
+// as dependencies can't add to /metrics behind our back.
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	ReservationsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "reservation",
+		Name:      "created_total",
+		Help:      "Reservations booked by guests, confirmed from the waitlist and imported from channels.",
+	})
+	CapacityRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "reservation",
+		Name:      "capacity_rejections_total",
+		Help:      "Reservations rejected because a night was at its overbooking ceiling.",
+	})
+	DuplicateReservations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "reservation",
+		Name:      "duplicate_ids_total",
+		Help:      "Reservations rejected because their ID already exists, usually client retries.",
+	})
+	LockConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "inventory",
+		Name:      "lock_conflicts_total",
+		Help:      "Inventory updates that lost the optimistic lock to a concurrent transaction.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestDuration,
+		ReservationsCreated,
+		CapacityRejections,
+		DuplicateReservations,
+		LockConflicts,
+	)
+}
+
+// Handler serves the registry together with collectors owned by the caller, such as the stats of its
+// database pool, in the Prometheus exposition format.
+func Handler(collectors ...prometheus.Collector) http.Handler {
+	local := prometheus.NewRegistry()
+	local.MustRegister(collectors...)
+
+	return promhttp.HandlerFor(prometheus.Gatherers{Registry, local}, promhttp.HandlerOpts{})
+}
+
+// Instrument observes the latency of every request. Requests are labelled by the route pattern chi matched
+// rather than the path so IDs don't blow up the number of series, unmatched ones are grouped together.
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		requestDuration.WithLabelValues(r.Method, route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,198 @@
+// Package migrate applies the goose migrations embedded from sql/schema. Migrations run under an advisory
+// lock, so replicas starting at the same time apply them once while the others wait.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/lock"
+	"github.com/AlexKhomenko00/hotel-system/sql/schema"
+	"github.com/pressly/goose/v3"
+)
+
+var (
+	ErrSchemaBehind = errors.New("migrate: Database schema is missing migrations")
+	ErrSchemaAhead  = errors.New("migrate: Database schema is newer than this build")
+	ErrNoMigrations = errors.New("migrate: No applied migration to roll back")
+)
+
+const (
+	lockName  = "migrations"
+	lockRetry = time.Second
+)
+
+type Migration struct {
+	Version   int64       `json:"version"`
+	Name      string      `json:"name"`
+	State     goose.State `json:"state"`
+	AppliedAt *time.Time  `json:"applied_at"`
+}
+
+type Migrator struct {
+	provider *goose.Provider
+	locker   *lock.Locker
+}
+
+func New(db *sql.DB) (*Migrator, error) {
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, schema.FS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{provider: provider, locker: lock.New(db)}, nil
+}
+
+// Status lists every embedded migration, oldest first.
+func (m *Migrator) Status(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(statuses))
+	for _, s := range statuses {
+		migration := Migration{
+			Version: s.Source.Version,
+			Name:    filepath.Base(s.Source.Path),
+			State:   s.State,
+		}
+		if s.State == goose.StateApplied {
+			migration.AppliedAt = &s.AppliedAt
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+// Pending lists the migrations Up would apply.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		if migration.State == goose.StatePending {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Last returns the most recently applied migration, the one Down rolls back.
+func (m *Migrator) Last(ctx context.Context) (Migration, error) {
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].State == goose.StateApplied {
+			return migrations[i], nil
+		}
+	}
+	return Migration{}, ErrNoMigrations
+}
+
+// Up applies every pending migration and returns the applied ones. It waits for a concurrent run to
+// finish first, which usually leaves nothing to apply.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func() error {
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		if _, err := m.provider.Up(ctx); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		applied = pending
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	var rolledBack Migration
+	err := m.locked(ctx, func() error {
+		last, err := m.Last(ctx)
+		if err != nil {
+			return err
+		}
+
+		if _, err := m.provider.Down(ctx); err != nil {
+			return fmt.Errorf("failed to roll back migration %s: %w", last.Name, err)
+		}
+		rolledBack = last
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Check compares the database schema with the embedded migrations. It returns ErrSchemaBehind when
+// migrations are pending and ErrSchemaAhead when the database has migrations this build doesn't know.
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending, first %s", ErrSchemaBehind, len(pending), pending[0].Name)
+	}
+
+	current, target, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if current > target {
+		return fmt.Errorf("%w: database is at %d, build expects %d", ErrSchemaAhead, current, target)
+	}
+	return nil
+}
+
+// Ensure checks the schema on startup. With autoMigrate a schema that is behind is migrated instead of
+// rejected. A schema that is ahead is only logged: migrations run before a rollout, and the pods of the
+// previous build must keep starting until the new ones replace them.
+func (m *Migrator) Ensure(ctx context.Context, autoMigrate bool) error {
+	err := m.Check(ctx)
+	if autoMigrate && errors.Is(err, ErrSchemaBehind) {
+		applied, upErr := m.Up(ctx)
+		if upErr != nil {
+			return upErr
+		}
+		for _, migration := range applied {
+			slog.Info("Applied migration", "migration", migration.Name)
+		}
+		err = m.Check(ctx)
+	}
+
+	if errors.Is(err, ErrSchemaAhead) {
+		slog.Warn("Starting on a database schema newer than this build", "error", err)
+		return nil
+	}
+	return err
+}
+
+func (m *Migrator) locked(ctx context.Context, fn func() error) error {
+	l, err := m.locker.WaitLock(ctx, lockName, lockRetry)
+	if err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+
+	fnErr := fn()
+	if err := l.Unlock(context.WithoutCancel(ctx)); err != nil && fnErr == nil {
+		return err
+	}
+	return fnErr
+}
@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+)
+
+var (
+	ErrReservationNotFound = errors.New("notificationService: Reservation not found")
+	ErrTemplateNotFound    = errors.New("notificationService: Template not found")
+)
+
+type Kind string
+
+const (
+	KindBookingConfirmation Kind = "booking_confirmation"
+	KindPreArrivalReminder  Kind = "pre_arrival_reminder"
+	KindModification        Kind = "modification"
+	KindCancellation        Kind = "cancellation"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSent    Status = "sent"
+	// Failed notifications exhausted their retries.
+	StatusFailed Status = "failed"
+)
+
+const defaultLocale = "en"
+
+const (
+	sendInterval          = 10 * time.Second
+	sendBatchSize         = 20
+	sendLease             = 2 * time.Minute
+	maxSendAttempts       = 5
+	baseRetryDelay        = time.Minute
+	reminderSweepInterval = time.Hour
+	// Guests arriving within this many days get a pre-arrival reminder.
+	reminderLeadDays = 2
+)
+
+type NotificationService struct {
+	queries   *database.Queries
+	sender    Sender
+	templates *templates
+}
+
+func New(queries *database.Queries, sender Sender) *NotificationService {
+	return &NotificationService{
+		queries:   queries,
+		sender:    sender,
+		templates: loadTemplates(),
+	}
+}
+
+// NewSender builds the sender selected by NOTIFICATION_SENDER.
+func NewSender(cfg *config.Config) Sender {
+	if cfg.Mail.Sender == "smtp" {
+		return NewSMTPSender(cfg.Mail.SMTPHost, strconv.Itoa(cfg.Mail.SMTPPort), cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From)
+	}
+	return NewFileSender(cfg.Mail.OutboxDir, cfg.Mail.From)
+}
@@ -0,0 +1,30 @@
+package notification
+
+import (
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RegisterAdminHandlers mounts the send log.
+func (s *NotificationService) RegisterAdminHandlers(r chi.Router) {
+	r.Get("/reservations/{reservationId}", s.ListReservationNotificationsHandler)
+}
+
+func (s *NotificationService) ListReservationNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := uuid.Parse(chi.URLParam(r, "reservationId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	notifications, err := s.listReservationNotifications(r.Context(), reservationID)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to list notifications")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"notifications": notifications})
+}
@@ -0,0 +1,243 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/ical"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// HandleEvent queues the email matching a reservation event. Rendering happens here, so the
+// send worker only delivers what was recorded. It is meant to be subscribed to the event bus.
+func (s *NotificationService) HandleEvent(ctx context.Context, e events.Event) error {
+	var kind Kind
+	switch e.Type {
+	case events.ReservationCreated:
+		// Waitlist holds are announced once the guest confirms them.
+		if reservationPayload(e).Status == "held" {
+			return nil
+		}
+		kind = KindBookingConfirmation
+	case events.ReservationConfirmed:
+		kind = KindBookingConfirmation
+	case events.ReservationModified:
+		kind = KindModification
+	case events.ReservationCancelled:
+		// An expired or abandoned hold was never a booking of the guest.
+		if reservationPayload(e).PreviousStatus == "held" {
+			return nil
+		}
+		kind = KindCancellation
+	default:
+		return nil
+	}
+
+	err := s.enqueue(ctx, e.AggregateID, kind, e.ID.String())
+	if errors.Is(err, ErrReservationNotFound) {
+		return nil
+	}
+	return err
+}
+
+func reservationPayload(e events.Event) events.ReservationPayload {
+	var payload events.ReservationPayload
+	_ = json.Unmarshal(e.Payload, &payload)
+	return payload
+}
+
+// enqueue renders and stores a pending notification. dedupeKey makes repeated calls for the same trigger a no-op.
+func (s *NotificationService) enqueue(ctx context.Context, reservationID uuid.UUID, kind Kind, dedupeKey string) error {
+	details, err := s.queries.GetReservationNotificationDetails(ctx, reservationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrReservationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reservation %q: %w", reservationID, err)
+	}
+
+	data := TemplateData{
+		GuestName:     details.GuestFirstName + " " + details.GuestLastName,
+		HotelName:     details.HotelName,
+		HotelLocation: details.HotelLocation,
+		RoomTypeName:  details.RoomTypeName,
+		ReservationID: details.ID.String(),
+		CheckIn:       details.StartDate.Format(dateLayout),
+		CheckOut:      shared.CheckOut(details.EndDate).Format(dateLayout),
+		Nights:        nights(details.StartDate, details.EndDate),
+	}
+
+	content, err := s.templates.render(kind, details.GuestLocale, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.queries.InsertNotification(ctx, database.InsertNotificationParams{
+		ID:            uuid.New(),
+		ReservationID: details.ID,
+		Kind:          string(kind),
+		Locale:        content.Locale,
+		Recipient:     details.GuestEmail,
+		Subject:       content.Subject,
+		BodyText:      content.Text,
+		BodyHtml:      content.HTML,
+		Calendar:      ical.Calendar(calendarMethod(kind), "", reservationEvent(details, kind)),
+		DedupeKey:     dedupeKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue %s notification for reservation %q: %w", kind, reservationID, err)
+	}
+
+	return nil
+}
+
+func reservationEvent(details database.GetReservationNotificationDetailsRow, kind Kind) ical.Event {
+	return ical.Event{
+		UID:         ical.ReservationUID(details.ID),
+		Sequence:    int(details.Revision),
+		Summary:     fmt.Sprintf("%s - %s", details.HotelName, details.RoomTypeName),
+		Description: fmt.Sprintf("Reservation %s", details.ID),
+		Location:    details.HotelLocation,
+		Start:       details.StartDate,
+		End:         shared.CheckOut(details.EndDate),
+		Status:      calendarStatus(kind),
+		Stamp:       time.Now().UTC(),
+	}
+}
+
+func calendarStatus(kind Kind) ical.Status {
+	if kind == KindCancellation {
+		return ical.StatusCancelled
+	}
+	return ical.StatusConfirmed
+}
+
+func calendarMethod(kind Kind) ical.Method {
+	if kind == KindCancellation {
+		return ical.MethodCancel
+	}
+	return ical.MethodRequest
+}
+
+func nights(startDate, endDate time.Time) int {
+	return int(shared.CheckOut(endDate).Sub(startDate).Hours() / 24)
+}
+
+// RunWorker sends queued notifications and schedules pre-arrival reminders until ctx is done.
+func (s *NotificationService) RunWorker(ctx context.Context) {
+	sendTicker := time.NewTicker(sendInterval)
+	defer sendTicker.Stop()
+	reminderTicker := time.NewTicker(reminderSweepInterval)
+	defer reminderTicker.Stop()
+
+	s.QueueReminders(ctx)
+
+	for {
+		if _, err := s.SendDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "Notification send run failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-reminderTicker.C:
+			s.QueueReminders(ctx)
+		case <-sendTicker.C:
+		}
+	}
+}
+
+// QueueReminders queues a reminder for every reservation arriving within the lead window.
+// Each reservation gets at most one, however often the sweep runs.
+func (s *NotificationService) QueueReminders(ctx context.Context) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	reservationIDs, err := s.queries.GetReservationsArrivingBetween(ctx, database.GetReservationsArrivingBetweenParams{
+		FromDate: today,
+		ToDate:   today.AddDate(0, 0, reminderLeadDays),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get upcoming arrivals", "error", err)
+		return
+	}
+
+	for _, id := range reservationIDs {
+		if err := s.enqueue(ctx, id, KindPreArrivalReminder, "reminder:"+id.String()); err != nil {
+			slog.ErrorContext(ctx, "Failed to queue reminder", "reservation_id", id, "error", err)
+		}
+	}
+}
+
+// SendDue sends one batch of due notifications and returns how many were sent.
+func (s *NotificationService) SendDue(ctx context.Context) (int, error) {
+	notifications, err := s.queries.ClaimDueNotifications(ctx, database.ClaimDueNotificationsParams{
+		LeaseSeconds: int32(sendLease.Seconds()),
+		BatchSize:    sendBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim notifications: %w", err)
+	}
+
+	sent := 0
+	for _, n := range notifications {
+		ok, err := s.send(ctx, n)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to record notification send", "notification_id", n.ID, "error", err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+func (s *NotificationService) send(ctx context.Context, n database.BookingNotification) (bool, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, sendLease/2)
+	defer cancel()
+
+	sendErr := s.sender.Send(sendCtx, Message{
+		ID:             n.ID,
+		To:             n.Recipient,
+		Subject:        n.Subject,
+		Text:           n.BodyText,
+		HTML:           n.BodyHtml,
+		Calendar:       n.Calendar,
+		CalendarMethod: string(calendarMethod(Kind(n.Kind))),
+	})
+	if sendErr == nil {
+		return true, s.queries.MarkNotificationSent(ctx, n.ID)
+	}
+
+	attempts := n.Attempts + 1
+	status := StatusPending
+	if attempts >= maxSendAttempts {
+		status = StatusFailed
+	}
+
+	return false, s.queries.MarkNotificationFailed(ctx, database.MarkNotificationFailedParams{
+		Status:        string(status),
+		NextAttemptAt: time.Now().UTC().Add(baseRetryDelay * time.Duration(1<<(attempts-1))),
+		LastError:     sql.NullString{String: sendErr.Error(), Valid: true},
+		ID:            n.ID,
+	})
+}
+
+func (s *NotificationService) listReservationNotifications(ctx context.Context, reservationID uuid.UUID) ([]database.BookingNotification, error) {
+	notifications, err := s.queries.ListReservationNotifications(ctx, reservationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications for reservation %q: %w", reservationID, err)
+	}
+	return notifications, nil
+}
@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Every locale directory holds two files per kind: "<kind>.txt.tmpl" defining the "subject" and "text"
+// templates, and "<kind>.html.tmpl" with the HTML body.
+type localisedTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+type templates struct {
+	byLocale map[string]map[Kind]localisedTemplate
+}
+
+// TemplateData is what every template is rendered with.
+type TemplateData struct {
+	GuestName     string
+	HotelName     string
+	HotelLocation string
+	RoomTypeName  string
+	ReservationID string
+	CheckIn       string
+	CheckOut      string
+	Nights        int
+}
+
+type rendered struct {
+	Locale  string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// loadTemplates parses the embedded templates. They ship with the binary, so a broken one is a programming error.
+func loadTemplates() *templates {
+	t := &templates{byLocale: map[string]map[Kind]localisedTemplate{}}
+
+	locales, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		panic(fmt.Sprintf("notification: failed to read templates: %v", err))
+	}
+
+	for _, locale := range locales {
+		if !locale.IsDir() {
+			continue
+		}
+		dir := path.Join("templates", locale.Name())
+		kinds := map[Kind]localisedTemplate{}
+
+		files, err := fs.Glob(templateFS, path.Join(dir, "*.txt.tmpl"))
+		if err != nil {
+			panic(fmt.Sprintf("notification: failed to list %s templates: %v", locale.Name(), err))
+		}
+
+		for _, file := range files {
+			kind := Kind(strings.TrimSuffix(path.Base(file), ".txt.tmpl"))
+			kinds[kind] = localisedTemplate{
+				text: texttemplate.Must(texttemplate.ParseFS(templateFS, file)),
+				html: htmltemplate.Must(htmltemplate.ParseFS(templateFS, path.Join(dir, string(kind)+".html.tmpl"))),
+			}
+		}
+
+		t.byLocale[locale.Name()] = kinds
+	}
+
+	return t
+}
+
+// render falls back to the default locale when the guest's locale has no template for kind.
+func (t *templates) render(kind Kind, locale string, data TemplateData) (rendered, error) {
+	tmpl, ok := t.byLocale[locale][kind]
+	if !ok {
+		locale = defaultLocale
+		tmpl, ok = t.byLocale[locale][kind]
+	}
+	if !ok {
+		return rendered{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, kind)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return rendered{}, fmt.Errorf("failed to render %s subject: %w", kind, err)
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return rendered{}, fmt.Errorf("failed to render %s text: %w", kind, err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return rendered{}, fmt.Errorf("failed to render %s html: %w", kind, err)
+	}
+
+	return rendered{
+		Locale:  locale,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
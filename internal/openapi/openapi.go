@@ -0,0 +1,281 @@
+// Package openapi builds the OpenAPI 3 document of the API from the handlers' request and response types,
+// serves it and validates incoming requests against it. Schemas are generated by reflection: json tags name
+// the fields and validate tags become constraints, so the document can't drift from the structs handlers
+// decode into.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+)
+
+const (
+	contentTypeJSON    = "application/json"
+	bearerSecurityName = "bearerAuth"
+)
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Security   []map[string][]string `json:"security"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+
+	gen     *generator
+	routes  []*route
+	problem *Schema
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Server is a base URL the paths are relative to.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps lower case HTTP methods to the operations of a path.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string                    `json:"operationId"`
+	Summary     string                    `json:"summary,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Parameters  []Parameter               `json:"parameters,omitempty"`
+	RequestBody *RequestBody              `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject `json:"responses"`
+	Security    []map[string][]string     `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Operation declares a route of the API.
+type Operation struct {
+	// ID names the operation in generated SDKs, keep it stable.
+	ID      string
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	// Public operations don't require a bearer token.
+	Public bool
+	// Query parameters. Path parameters are taken from the {name} segments of Path, the ones named id or
+	// ending in Id are UUIDs.
+	Query []Param
+	// Body is a value of the type the handler decodes the JSON body into.
+	Body any
+	// BodyContentType documents a non JSON body, such bodies aren't validated.
+	BodyContentType string
+	Responses       []Response
+}
+
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+	Schema      *Schema
+}
+
+type Response struct {
+	Status      int
+	Description string
+	// Body is a value of the response type, a shared.Envelope describes each of its keys with a value.
+	Body any
+	// ContentType defaults to JSON.
+	ContentType string
+}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+func New(title, version string) *Document {
+	doc := &Document{
+		OpenAPI:  "3.0.3",
+		Info:     Info{Title: title, Version: version},
+		Security: []map[string][]string{{bearerSecurityName: {}}},
+		Paths:    make(map[string]PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerSecurityName: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	doc.gen = newGenerator(doc.Components.Schemas)
+	doc.problem = doc.gen.response(shared.Problem{})
+	return doc
+}
+
+// Add documents an operation. It panics on a malformed declaration, those are programming errors caught
+// when the document is built on startup.
+func (d *Document) Add(op Operation) {
+	path := normalizePath(op.Path)
+	method := strings.ToLower(op.Method)
+	if op.ID == "" {
+		panic(fmt.Sprintf("openapi: operation %s %s has no ID", op.Method, path))
+	}
+	if _, ok := d.Paths[path][method]; ok {
+		panic(fmt.Sprintf("openapi: operation %s %s declared twice", op.Method, path))
+	}
+
+	obj := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Responses:   make(map[string]ResponseObject),
+	}
+	if op.Tag != "" {
+		obj.Tags = []string{op.Tag}
+	}
+	if op.Public {
+		obj.Security = []map[string][]string{}
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		obj.Parameters = append(obj.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   pathParamSchema(match[1]),
+		})
+	}
+	for _, p := range op.Query {
+		obj.Parameters = append(obj.Parameters, Parameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      p.Schema,
+		})
+	}
+
+	var body *Schema
+	switch {
+	case op.BodyContentType != "":
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{op.BodyContentType: {Schema: &Schema{Type: "string"}}},
+		}
+	case op.Body != nil:
+		body = d.gen.request(op.Body)
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{contentTypeJSON: {Schema: body}},
+		}
+	}
+
+	for _, resp := range op.Responses {
+		obj.Responses[strconv.Itoa(resp.Status)] = d.response(resp)
+	}
+	obj.Responses["default"] = ResponseObject{
+		Description: "Problem details, code identifies the error",
+		Content:     map[string]MediaType{shared.ProblemContentType: {Schema: d.problem}},
+	}
+
+	if d.Paths[path] == nil {
+		d.Paths[path] = make(PathItem)
+	}
+	d.Paths[path][method] = obj
+	d.routes = append(d.routes, newRoute(strings.ToUpper(method), path, obj, body))
+}
+
+func (d *Document) response(resp Response) ResponseObject {
+	obj := ResponseObject{Description: resp.Description}
+	if obj.Description == "" {
+		obj.Description = http.StatusText(resp.Status)
+	}
+	if resp.Body == nil {
+		return obj
+	}
+
+	contentType := resp.ContentType
+	if contentType == "" {
+		contentType = contentTypeJSON
+	}
+
+	var schema *Schema
+	switch body := resp.Body.(type) {
+	case shared.Envelope:
+		schema = &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for key, value := range body {
+			schema.Properties[key] = d.gen.response(value)
+			schema.Required = append(schema.Required, key)
+		}
+		slices.Sort(schema.Required)
+	case string:
+		schema = &Schema{Type: "string"}
+	default:
+		schema = d.gen.response(body)
+	}
+
+	obj.Content = map[string]MediaType{contentType: {Schema: schema}}
+	return obj
+}
+
+// Handler serves the document as JSON.
+func (d *Document) Handler() http.HandlerFunc {
+	js, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		panic(fmt.Sprintf("openapi: failed to encode document: %v", err))
+	}
+	js = append(js, '\n')
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Write(js)
+	}
+}
+
+func pathParamSchema(name string) *Schema {
+	if name == "id" || strings.HasSuffix(name, "Id") {
+		return UUID()
+	}
+	return String()
+}
+
+// normalizePath drops the trailing slash chi leaves on the root route of a sub router.
+func normalizePath(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
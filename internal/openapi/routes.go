@@ -0,0 +1,54 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CheckRoutes compares the document with the routes registered on the router. It returns an error listing
+// routes the document is missing and documented operations nothing serves.
+func (d *Document) CheckRoutes(routes chi.Routes) error {
+	served := make(map[string]bool)
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		served[method+" "+normalizePath(route)] = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	documented := make(map[string]bool)
+	for _, rt := range d.routes {
+		documented[rt.method+" "+rt.path] = true
+	}
+
+	var undocumented, unserved []string
+	for route := range served {
+		if !documented[route] {
+			undocumented = append(undocumented, route)
+		}
+	}
+	for route := range documented {
+		if !served[route] {
+			unserved = append(unserved, route)
+		}
+	}
+	if len(undocumented) == 0 && len(unserved) == 0 {
+		return nil
+	}
+
+	slices.Sort(undocumented)
+	slices.Sort(unserved)
+	var problems []string
+	if len(undocumented) > 0 {
+		problems = append(problems, "undocumented routes: "+strings.Join(undocumented, ", "))
+	}
+	if len(unserved) > 0 {
+		problems = append(problems, "documented but not served: "+strings.Join(unserved, ", "))
+	}
+	return fmt.Errorf("openapi: document doesn't match routes, %s", strings.Join(problems, "; "))
+}
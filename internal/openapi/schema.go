@@ -0,0 +1,339 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+func UUID() *Schema {
+	return &Schema{Type: "string", Format: "uuid"}
+}
+
+func Date() *Schema {
+	return &Schema{Type: "string", Format: "date"}
+}
+
+// Integer returns an integer schema with the given lower bound.
+func Integer(min int) *Schema {
+	return &Schema{Type: "integer", Format: "int32", Minimum: ptr(float64(min))}
+}
+
+func Enum[T ~string](values ...T) *Schema {
+	schema := &Schema{Type: "string"}
+	for _, v := range values {
+		schema.Enum = append(schema.Enum, string(v))
+	}
+	return schema
+}
+
+// Pattern returns a string schema matching the regular expression.
+func Pattern(pattern string) *Schema {
+	return &Schema{Type: "string", Pattern: pattern}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	dateType     = reflect.TypeFor[shared.Date]()
+	uuidType     = reflect.TypeFor[uuid.UUID]()
+	nullUUIDType = reflect.TypeFor[uuid.NullUUID]()
+	rawJSONType  = reflect.TypeFor[json.RawMessage]()
+	weekdayType  = reflect.TypeFor[time.Weekday]()
+)
+
+// generator turns Go types into schemas, named struct types become components. Request types take required
+// fields from validate tags, response types from the absence of omitempty, so the same type may be
+// generated once for each.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[genKey]string
+}
+
+type genKey struct {
+	t       reflect.Type
+	request bool
+}
+
+func newGenerator(schemas map[string]*Schema) *generator {
+	return &generator{schemas: schemas, names: make(map[genKey]string)}
+}
+
+func (g *generator) request(v any) *Schema {
+	return g.schema(reflect.TypeOf(v), true)
+}
+
+func (g *generator) response(v any) *Schema {
+	return g.schema(reflect.TypeOf(v), false)
+}
+
+func (g *generator) schema(t reflect.Type, request bool) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case dateType:
+		return Date()
+	case uuidType:
+		return UUID()
+	case nullUUIDType:
+		return &Schema{Type: "string", Format: "uuid", Nullable: true}
+	case rawJSONType:
+		return &Schema{}
+	case weekdayType:
+		return &Schema{Type: "integer", Minimum: ptr(0.0), Maximum: ptr(6.0)}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem(), request))
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		// Nil slices are encoded as null.
+		return &Schema{Type: "array", Items: g.schema(t.Elem(), request), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), request)}
+	case reflect.Struct:
+		return g.component(t, request)
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *generator) component(t reflect.Type, request bool) *Schema {
+	if t.Name() == "" {
+		return g.object(t, request)
+	}
+
+	key := genKey{t: t, request: request}
+	if name, ok := g.names[key]; ok {
+		return ref(name)
+	}
+
+	name := g.componentName(t, request)
+	g.names[key] = name
+	// Registered before the fields are generated so recursive types refer to themselves.
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.object(t, request)
+	return ref(name)
+}
+
+// componentName is the type name, prefixed with its package when another package has a type of that name
+// and suffixed with Input for a request type also used in responses.
+func (g *generator) componentName(t reflect.Type, request bool) string {
+	name := t.Name()
+	if other, ok := g.typeNamed(name); ok && other != t {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	if _, ok := g.names[genKey{t: t, request: !request}]; ok && request {
+		name += "Input"
+	}
+	return name
+}
+
+func (g *generator) typeNamed(name string) (reflect.Type, bool) {
+	for key, n := range g.names {
+		if n == name {
+			return key.t, true
+		}
+	}
+	return nil, false
+}
+
+func (g *generator) object(t reflect.Type, request bool) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.fields(schema, t, request)
+	slices.Sort(schema.Required)
+	return schema
+}
+
+func (g *generator) fields(schema *Schema, t reflect.Type, request bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(schema, embedded, request)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := g.schema(field.Type, request)
+		required := applyValidateTag(fieldSchema, field.Tag.Get("validate"))
+		if !request {
+			required = !strings.Contains(opts, "omitempty")
+		}
+
+		schema.Properties[name] = fieldSchema
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// applyValidateTag maps the go-playground validator rules the API uses onto the schema and reports whether
+// the field is required. Rules after dive apply to the items of a slice.
+func applyValidateTag(schema *Schema, tag string) bool {
+	if tag == "" || schema.Ref != "" || len(schema.AllOf) > 0 {
+		return strings.HasPrefix(tag, "required")
+	}
+
+	required := false
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = target == schema
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "min", "max", "len":
+			applyLength(target, name, param)
+		case "gte", "gt":
+			target.Minimum = parseFloat(param)
+			target.ExclusiveMinimum = name == "gt"
+		case "lte", "lt":
+			target.Maximum = parseFloat(param)
+			target.ExclusiveMaximum = name == "lt"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				if target.Type == "integer" {
+					n, _ := strconv.Atoi(value)
+					target.Enum = append(target.Enum, n)
+					continue
+				}
+				target.Enum = append(target.Enum, value)
+			}
+		case "email":
+			target.Format = "email"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "url", "http_url":
+			target.Format = "uri"
+		case "numeric":
+			target.Pattern = `^-?[0-9]+(\.[0-9]+)?$`
+		case "uppercase":
+			target.Pattern = `^[^a-z]*$`
+		}
+	}
+	return required
+}
+
+func applyLength(schema *Schema, rule, param string) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+
+	lower, upper := rule == "min" || rule == "len", rule == "max" || rule == "len"
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = ptr(n)
+		}
+		if upper {
+			schema.MaxLength = ptr(n)
+		}
+	case "array":
+		if lower {
+			schema.MinItems = ptr(n)
+		}
+		if upper {
+			schema.MaxItems = ptr(n)
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = ptr(float64(n))
+		}
+		if upper {
+			schema.Maximum = ptr(float64(n))
+		}
+	}
+}
+
+// nullable marks a schema as accepting null. References can't carry siblings in OpenAPI 3.0, so they are
+// wrapped in allOf.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+	schema.Nullable = true
+	return schema
+}
+
+func parseFloat(s string) *float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
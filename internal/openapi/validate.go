@@ -0,0 +1,393 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxBodySize bounds the JSON bodies buffered for validation, larger ones are rejected.
+const maxBodySize = 1 << 20
+
+type route struct {
+	method   string
+	path     string
+	segments []segment
+	literals int
+	op       *OperationObject
+	body     *Schema
+}
+
+// segment is a path segment, either literal or a {name} parameter with optional literal text around it
+// such as {token}.ics.
+type segment struct {
+	literal string
+	param   string
+	prefix  string
+	suffix  string
+}
+
+func newRoute(method, path string, op *OperationObject, body *Schema) *route {
+	rt := &route{method: method, path: path, op: op, body: body}
+	for _, part := range splitPath(path) {
+		match := pathParamPattern.FindStringSubmatchIndex(part)
+		if match == nil {
+			rt.segments = append(rt.segments, segment{literal: part})
+			rt.literals++
+			continue
+		}
+		rt.segments = append(rt.segments, segment{
+			param:  part[match[2]:match[3]],
+			prefix: part[:match[0]],
+			suffix: part[match[1]:],
+		})
+	}
+	return rt
+}
+
+func (rt *route) match(parts []string) (map[string]string, bool) {
+	if len(parts) != len(rt.segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, seg := range rt.segments {
+		part := parts[i]
+		if seg.param == "" {
+			if part != seg.literal {
+				return nil, false
+			}
+			continue
+		}
+		if !strings.HasPrefix(part, seg.prefix) || !strings.HasSuffix(part, seg.suffix) || len(part) <= len(seg.prefix)+len(seg.suffix) {
+			return nil, false
+		}
+		params[seg.param] = part[len(seg.prefix) : len(part)-len(seg.suffix)]
+	}
+	return params, true
+}
+
+// find returns the operation serving the request, preferring the route with the most literal segments
+// like the router does.
+func (d *Document) find(method, path string) (*route, map[string]string) {
+	parts := splitPath(normalizePath(path))
+
+	var (
+		best       *route
+		bestParams map[string]string
+	)
+	for _, rt := range d.routes {
+		if rt.method != method {
+			continue
+		}
+		params, ok := rt.match(parts)
+		if ok && (best == nil || rt.literals > best.literals) {
+			best, bestParams = rt, params
+		}
+	}
+	return best, bestParams
+}
+
+// ValidateRequests rejects requests whose path parameters, query parameters or JSON body don't match the
+// documented operation with 400. Requests to undocumented routes are passed through for the router to
+// answer. Paths are matched relative to the router the middleware is used on.
+func (d *Document) ValidateRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rt, pathParams := d.find(r.Method, routePath(r))
+		if rt == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		for _, param := range rt.op.Parameters {
+			var (
+				value   string
+				present bool
+			)
+			if param.In == "path" {
+				value, present = pathParams[param.Name], true
+			} else {
+				value, present = query.Get(param.Name), query.Has(param.Name)
+			}
+
+			if !present || value == "" {
+				if param.Required {
+					shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Missing %s parameter %s", param.In, param.Name))
+					return
+				}
+				continue
+			}
+			if err := d.validateParam(param.Schema, value); err != nil {
+				shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter %s: %v", param.In, param.Name, err))
+				return
+			}
+		}
+
+		if rt.body != nil {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+			if err != nil {
+				shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+				return
+			}
+			if len(body) > maxBodySize {
+				shared.WriteError(w, http.StatusRequestEntityTooLarge, "Body too large")
+				return
+			}
+
+			value, err := decodeBody(body)
+			if err != nil {
+				shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+				return
+			}
+			if err := d.validate(rt.body, value, ""); err != nil {
+				writeBodyError(w, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeBodyError reports the invalid field like shared.WriteValidationError does for the handlers' checks.
+func writeBodyError(w http.ResponseWriter, err error) {
+	var fe *fieldError
+	if !errors.As(err, &fe) {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err))
+		return
+	}
+	shared.WriteProblem(w, shared.Problem{
+		Status: http.StatusBadRequest,
+		Code:   shared.CodeValidationFailed,
+		Detail: "Invalid body",
+		Errors: []shared.FieldError{{Field: fe.path, Code: fe.code, Message: fe.message}},
+	})
+}
+
+// routePath is the path relative to the router the middleware is used on, so the document applies to the
+// API wherever it is mounted.
+func routePath(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+		return rctx.RoutePath
+	}
+	return r.URL.Path
+}
+
+// decodeBody decodes a JSON body keeping numbers as json.Number, so integers can be told from floats.
+func decodeBody(body []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// validateParam checks a path or query parameter, numbers and booleans are parsed from their text.
+func (d *Document) validateParam(schema *Schema, raw string) error {
+	var value any = raw
+	switch schema.Type {
+	case "integer", "number":
+		value = json.Number(raw)
+	case "boolean":
+		switch raw {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		}
+	}
+	return d.validate(schema, value, "")
+}
+
+func (d *Document) validate(schema *Schema, value any, path string) error {
+	if schema.Ref != "" {
+		return d.validate(d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], value, path)
+	}
+	if value == nil {
+		if schema.Nullable || schema.Type == "" && len(schema.AllOf) == 0 {
+			return nil
+		}
+		return newFieldError(path, "type", "must not be null")
+	}
+	for _, sub := range schema.AllOf {
+		if err := d.validate(sub, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return newFieldError(path, "type", "must be a string")
+		}
+		return validateString(schema, s, path)
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			return newFieldError(path, "type", "must be a number")
+		}
+		return validateNumber(schema, n, path)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return newFieldError(path, "type", "must be a boolean")
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return newFieldError(path, "type", "must be an array")
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			return newFieldError(path, "min", fmt.Sprintf("must have at least %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			return newFieldError(path, "max", fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+		}
+		for i, item := range items {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return newFieldError(path, "type", "must be an object")
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				return newFieldError(joinPath(path, name), "required", "is required")
+			}
+		}
+		for name, v := range obj {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				prop = schema.AdditionalProperties
+			}
+			// Unknown fields are ignored like the handlers' decoders do.
+			if prop == nil {
+				continue
+			}
+			if err := d.validate(prop, v, joinPath(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateString(schema *Schema, s, path string) error {
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, s) {
+		return newFieldError(path, "oneof", fmt.Sprintf("must be one of %v", schema.Enum))
+	}
+	if schema.MinLength != nil && utf8.RuneCountInString(s) < *schema.MinLength {
+		return newFieldError(path, "min", fmt.Sprintf("must be at least %d characters", *schema.MinLength))
+	}
+	if schema.MaxLength != nil && utf8.RuneCountInString(s) > *schema.MaxLength {
+		return newFieldError(path, "max", fmt.Sprintf("must be at most %d characters", *schema.MaxLength))
+	}
+	if schema.Pattern != "" {
+		if matched, _ := regexp.MatchString(schema.Pattern, s); !matched {
+			return newFieldError(path, "pattern", fmt.Sprintf("must match %s", schema.Pattern))
+		}
+	}
+
+	var err error
+	switch schema.Format {
+	case "uuid":
+		_, err = uuid.Parse(s)
+	case "date":
+		_, err = time.Parse(time.DateOnly, s)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "email":
+		_, err = mail.ParseAddress(s)
+	case "uri":
+		var u *url.URL
+		if u, err = url.ParseRequestURI(s); err == nil && u.Host == "" {
+			err = errors.New("missing host")
+		}
+	}
+	if err != nil {
+		return newFieldError(path, "format", fmt.Sprintf("must be a valid %s", schema.Format))
+	}
+	return nil
+}
+
+func validateNumber(schema *Schema, n json.Number, path string) error {
+	f, err := n.Float64()
+	if err != nil {
+		return newFieldError(path, "type", "must be a number")
+	}
+	if schema.Type == "integer" {
+		if _, err := n.Int64(); err != nil {
+			return newFieldError(path, "type", "must be an integer")
+		}
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, n.String()) {
+		return newFieldError(path, "oneof", fmt.Sprintf("must be one of %v", schema.Enum))
+	}
+	if schema.Minimum != nil && (f < *schema.Minimum || schema.ExclusiveMinimum && f == *schema.Minimum) {
+		return newFieldError(path, "min", fmt.Sprintf("must not be below %v", *schema.Minimum))
+	}
+	if schema.Maximum != nil && (f > *schema.Maximum || schema.ExclusiveMaximum && f == *schema.Maximum) {
+		return newFieldError(path, "max", fmt.Sprintf("must not be above %v", *schema.Maximum))
+	}
+	return nil
+}
+
+func enumContains(enum []any, value string) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldError is a value failing its schema, code names the failed rule like the validator tags handlers use.
+type fieldError struct {
+	path    string
+	code    string
+	message string
+}
+
+func newFieldError(path, code, message string) error {
+	return &fieldError{path: path, code: code, message: message}
+}
+
+func (e *fieldError) Error() string {
+	if e.path == "" {
+		return "value " + e.message
+	}
+	return e.path + " " + e.message
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
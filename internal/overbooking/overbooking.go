@@ -0,0 +1,55 @@
+package overbooking
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPolicyNotFound   = errors.New("overbookingService: Policy not found")
+	ErrRoomTypeNotFound = errors.New("overbookingService: Room type not found")
+	ErrInvalidDateRange = errors.New("overbookingService: Invalid date range")
+)
+
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrPolicyNotFound:   {Status: http.StatusNotFound, Code: "overbooking.policy_not_found", Message: "Overbooking policy not found"},
+		ErrRoomTypeNotFound: {Status: http.StatusNotFound, Code: "room_type.not_found", Message: "Room type not found"},
+		ErrInvalidDateRange: {Status: http.StatusBadRequest, Code: "request.invalid_date_range", Message: "Invalid date range"},
+	})
+}
+
+// Policies are changed by revenue managers a few times a day at most, while every booking and
+// availability request needs them. Other replicas pick up changes once their cache entry expires.
+const policyCacheTTL = time.Minute
+
+type cachedPolicies struct {
+	policies []database.BookingOverbookingPolicy
+	loadedAt time.Time
+}
+
+type OverbookingService struct {
+	queries       *database.Queries
+	validator     *validator.Validate
+	defaultFactor float64
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]cachedPolicies
+}
+
+func New(queries *database.Queries, validator *validator.Validate, cfg *config.Config) *OverbookingService {
+	return &OverbookingService{
+		queries:       queries,
+		validator:     validator,
+		defaultFactor: cfg.Booking.OverbookingFactor,
+		cache:         make(map[uuid.UUID]cachedPolicies),
+	}
+}
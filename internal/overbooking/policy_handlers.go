@@ -0,0 +1,184 @@
+package overbooking
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RegisterHotelHandlers mounts the per-hotel policy and report endpoints. The router is expected
+// to carry a {hotelId} URL parameter.
+func (s *OverbookingService) RegisterHotelHandlers(r chi.Router) {
+	r.Get("/policies", s.ListPoliciesHandler)
+	r.Post("/policies", s.CreatePolicyHandler)
+	r.Put("/policies/{policyId}", s.UpdatePolicyHandler)
+	r.Delete("/policies/{policyId}", s.DeletePolicyHandler)
+	r.Get("/report", s.OverbookedNightsReportHandler)
+}
+
+// RegisterAdminHandlers mounts the global default endpoints.
+func (s *OverbookingService) RegisterAdminHandlers(r chi.Router) {
+	r.Get("/default", s.GetDefaultPolicyHandler)
+	r.Put("/default", s.SetDefaultPolicyHandler)
+}
+
+func (s *OverbookingService) ListPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	policies, err := s.listHotelPolicies(r.Context(), hotelID)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to list overbooking policies")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"policies": policies})
+}
+
+func (s *OverbookingService) CreatePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	var body PolicyBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	policy, err := s.createPolicy(r.Context(), hotelID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"policy": policy})
+}
+
+func (s *OverbookingService) UpdatePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	policyID, err := uuid.Parse(chi.URLParam(r, "policyId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid policy ID")
+		return
+	}
+
+	var body PolicyBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	policy, err := s.updatePolicy(r.Context(), hotelID, policyID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"policy": policy})
+}
+
+func (s *OverbookingService) DeletePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	policyID, err := uuid.Parse(chi.URLParam(r, "policyId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid policy ID")
+		return
+	}
+
+	if err := s.deletePolicy(r.Context(), hotelID, policyID); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *OverbookingService) OverbookedNightsReportHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	from, err := time.Parse(time.DateOnly, r.URL.Query().Get("from"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid from")
+		return
+	}
+
+	to, err := time.Parse(time.DateOnly, r.URL.Query().Get("to"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid to")
+		return
+	}
+
+	report, err := s.overbookedNightsReport(r.Context(), hotelID, from, to)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"report": report})
+}
+
+func (s *OverbookingService) GetDefaultPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	policy, err := s.getDefaultPolicy(r.Context())
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to get default overbooking policy")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"policy": policy})
+}
+
+func (s *OverbookingService) SetDefaultPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var body DefaultPolicyBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	policy, err := s.setDefaultPolicy(r.Context(), body)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to set default overbooking policy")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"policy": policy})
+}
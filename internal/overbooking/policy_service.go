@@ -0,0 +1,195 @@
+package overbooking
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type PolicyBody struct {
+	// Leave empty to apply the policy to every room type of the hotel.
+	RoomTypeID *uuid.UUID `json:"roomTypeId"`
+	// Optional, open ended when omitted.
+	ValidFrom *shared.Date `json:"validFrom"`
+	ValidTo   *shared.Date `json:"validTo"`
+	// Upper bound guards against typos such as 11 instead of 1.1.
+	Factor float64 `json:"factor" validate:"required,gte=1,lte=2"`
+}
+
+type DefaultPolicyBody struct {
+	Factor float64 `json:"factor" validate:"required,gte=1,lte=2"`
+}
+
+type DefaultPolicy struct {
+	Factor float64 `json:"factor"`
+	// Source is "database" when a global policy is stored, "config" when OVERBOOKING_FACTOR is used.
+	Source string `json:"source"`
+}
+
+type OverbookedNightsReport struct {
+	HotelID         uuid.UUID                         `json:"hotel_id"`
+	From            shared.Date                       `json:"from"`
+	To              shared.Date                       `json:"to"`
+	TotalOverbooked int32                             `json:"total_overbooked"`
+	Nights          []database.GetOverbookedNightsRow `json:"nights"`
+}
+
+func (s *OverbookingService) listHotelPolicies(ctx context.Context, hotelID uuid.UUID) ([]database.BookingOverbookingPolicy, error) {
+	return s.queries.ListHotelOverbookingPolicies(ctx, hotelID)
+}
+
+func (s *OverbookingService) createPolicy(ctx context.Context, hotelID uuid.UUID, body PolicyBody) (database.BookingOverbookingPolicy, error) {
+	roomTypeID, validFrom, validTo, err := s.policyScope(ctx, hotelID, body)
+	if err != nil {
+		return database.BookingOverbookingPolicy{}, err
+	}
+
+	policy, err := s.queries.CreateOverbookingPolicy(ctx, database.CreateOverbookingPolicyParams{
+		ID:         uuid.New(),
+		HotelID:    uuid.NullUUID{UUID: hotelID, Valid: true},
+		RoomTypeID: roomTypeID,
+		ValidFrom:  validFrom,
+		ValidTo:    validTo,
+		Factor:     body.Factor,
+	})
+	if err != nil {
+		return database.BookingOverbookingPolicy{}, fmt.Errorf("failed to create overbooking policy: %w", err)
+	}
+
+	s.invalidate(hotelID)
+	return policy, nil
+}
+
+func (s *OverbookingService) updatePolicy(ctx context.Context, hotelID, policyID uuid.UUID, body PolicyBody) (database.BookingOverbookingPolicy, error) {
+	roomTypeID, validFrom, validTo, err := s.policyScope(ctx, hotelID, body)
+	if err != nil {
+		return database.BookingOverbookingPolicy{}, err
+	}
+
+	policy, err := s.queries.UpdateOverbookingPolicy(ctx, database.UpdateOverbookingPolicyParams{
+		RoomTypeID: roomTypeID,
+		ValidFrom:  validFrom,
+		ValidTo:    validTo,
+		Factor:     body.Factor,
+		ID:         policyID,
+		HotelID:    hotelID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingOverbookingPolicy{}, ErrPolicyNotFound
+	}
+	if err != nil {
+		return database.BookingOverbookingPolicy{}, fmt.Errorf("failed to update overbooking policy %q: %w", policyID, err)
+	}
+
+	s.invalidate(hotelID)
+	return policy, nil
+}
+
+func (s *OverbookingService) deletePolicy(ctx context.Context, hotelID, policyID uuid.UUID) error {
+	deleted, err := s.queries.DeleteOverbookingPolicy(ctx, database.DeleteOverbookingPolicyParams{
+		ID:      policyID,
+		HotelID: hotelID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete overbooking policy %q: %w", policyID, err)
+	}
+	if deleted == 0 {
+		return ErrPolicyNotFound
+	}
+
+	s.invalidate(hotelID)
+	return nil
+}
+
+func (s *OverbookingService) getDefaultPolicy(ctx context.Context) (DefaultPolicy, error) {
+	policy, err := s.queries.GetGlobalOverbookingPolicy(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultPolicy{Factor: s.defaultFactor, Source: "config"}, nil
+	}
+	if err != nil {
+		return DefaultPolicy{}, fmt.Errorf("failed to get global overbooking policy: %w", err)
+	}
+
+	return DefaultPolicy{Factor: policy.Factor, Source: "database"}, nil
+}
+
+func (s *OverbookingService) setDefaultPolicy(ctx context.Context, body DefaultPolicyBody) (DefaultPolicy, error) {
+	policy, err := s.queries.UpsertGlobalOverbookingPolicy(ctx, database.UpsertGlobalOverbookingPolicyParams{
+		ID:     uuid.New(),
+		Factor: body.Factor,
+	})
+	if err != nil {
+		return DefaultPolicy{}, fmt.Errorf("failed to set global overbooking policy: %w", err)
+	}
+
+	s.invalidate(uuid.Nil)
+	return DefaultPolicy{Factor: policy.Factor, Source: "database"}, nil
+}
+
+// overbookedNightsReport lists nights where more rooms were sold than physically exist, so staff can plan relocations.
+func (s *OverbookingService) overbookedNightsReport(ctx context.Context, hotelID uuid.UUID, from, to time.Time) (OverbookedNightsReport, error) {
+	if to.Before(from) {
+		return OverbookedNightsReport{}, ErrInvalidDateRange
+	}
+
+	nights, err := s.queries.GetOverbookedNights(ctx, database.GetOverbookedNightsParams{
+		HotelID:  hotelID,
+		DateFrom: from,
+		DateTo:   to,
+	})
+	if err != nil {
+		return OverbookedNightsReport{}, fmt.Errorf("failed to get overbooked nights for hotel %q: %w", hotelID, err)
+	}
+
+	report := OverbookedNightsReport{
+		HotelID: hotelID,
+		From:    shared.Date(from),
+		To:      shared.Date(to),
+		Nights:  nights,
+	}
+	for _, night := range nights {
+		report.TotalOverbooked += night.Overbooked
+	}
+
+	return report, nil
+}
+
+func (s *OverbookingService) policyScope(ctx context.Context, hotelID uuid.UUID, body PolicyBody) (uuid.NullUUID, sql.NullTime, sql.NullTime, error) {
+	var (
+		roomTypeID uuid.NullUUID
+		validFrom  sql.NullTime
+		validTo    sql.NullTime
+	)
+
+	if body.RoomTypeID != nil {
+		_, err := s.queries.GetRoomTypeByIdAndHotelId(ctx, database.GetRoomTypeByIdAndHotelIdParams{
+			ID:      *body.RoomTypeID,
+			HotelID: hotelID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return roomTypeID, validFrom, validTo, ErrRoomTypeNotFound
+		}
+		if err != nil {
+			return roomTypeID, validFrom, validTo, fmt.Errorf("failed to check room type existence: %w", err)
+		}
+		roomTypeID = uuid.NullUUID{UUID: *body.RoomTypeID, Valid: true}
+	}
+
+	if body.ValidFrom != nil {
+		validFrom = sql.NullTime{Time: time.Time(*body.ValidFrom), Valid: true}
+	}
+	if body.ValidTo != nil {
+		validTo = sql.NullTime{Time: time.Time(*body.ValidTo), Valid: true}
+	}
+	if validFrom.Valid && validTo.Valid && validTo.Time.Before(validFrom.Time) {
+		return roomTypeID, validFrom, validTo, ErrInvalidDateRange
+	}
+
+	return roomTypeID, validFrom, validTo, nil
+}
@@ -0,0 +1,103 @@
+package overbooking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/google/uuid"
+)
+
+// Policies is the set of overbooking policies applicable to a single hotel,
+// including the global default stored in the database.
+type Policies struct {
+	policies      []database.BookingOverbookingPolicy
+	defaultFactor float64
+}
+
+// HotelPolicies loads, or takes from cache, the policies applicable to the hotel.
+func (s *OverbookingService) HotelPolicies(ctx context.Context, hotelID uuid.UUID) (Policies, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[hotelID]
+	s.mu.RUnlock()
+
+	if !ok || time.Since(cached.loadedAt) >= policyCacheTTL {
+		policies, err := s.queries.GetApplicableOverbookingPolicies(ctx, hotelID)
+		if err != nil {
+			return Policies{}, fmt.Errorf("failed to load overbooking policies for hotel %q: %w", hotelID, err)
+		}
+
+		cached = cachedPolicies{policies: policies, loadedAt: time.Now()}
+
+		s.mu.Lock()
+		s.cache[hotelID] = cached
+		s.mu.Unlock()
+	}
+
+	return Policies{policies: cached.policies, defaultFactor: s.defaultFactor}, nil
+}
+
+// Factor returns the overbooking factor for a room type on a date.
+//
+// The most specific matching policy wins: room type with a date range, room type, hotel with
+// a date range, hotel, the global default stored in the database and finally OVERBOOKING_FACTOR.
+func (p Policies) Factor(roomTypeID uuid.UUID, date time.Time) float64 {
+	factor := p.defaultFactor
+	bestRank := -1
+	var bestUpdatedAt time.Time
+
+	for _, policy := range p.policies {
+		if policy.RoomTypeID.Valid && policy.RoomTypeID.UUID != roomTypeID {
+			continue
+		}
+		if policy.ValidFrom.Valid && date.Before(policy.ValidFrom.Time) {
+			continue
+		}
+		if policy.ValidTo.Valid && date.After(policy.ValidTo.Time) {
+			continue
+		}
+
+		// Overlapping policies of the same specificity are settled by the latest change.
+		rank := policyRank(policy)
+		if rank > bestRank || (rank == bestRank && policy.UpdatedAt.After(bestUpdatedAt)) {
+			factor = policy.Factor
+			bestRank = rank
+			bestUpdatedAt = policy.UpdatedAt
+		}
+	}
+
+	return factor
+}
+
+// MaxCapacity is the number of reservations an inventory night can hold.
+func (p Policies) MaxCapacity(inv database.BookingRoomTypeInventory) int32 {
+	return int32(float64(inv.TotalInventory) * p.Factor(inv.RoomTypeID, inv.Date))
+}
+
+func policyRank(p database.BookingOverbookingPolicy) int {
+	if !p.HotelID.Valid {
+		return 0
+	}
+
+	rank := 1
+	if p.RoomTypeID.Valid {
+		rank += 2
+	}
+	if p.ValidFrom.Valid || p.ValidTo.Valid {
+		rank++
+	}
+	return rank
+}
+
+// invalidate drops the cached policies of a hotel, or of every hotel when hotelID is uuid.Nil.
+func (s *OverbookingService) invalidate(hotelID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if hotelID == uuid.Nil {
+		clear(s.cache)
+		return
+	}
+	delete(s.cache, hotelID)
+}
@@ -0,0 +1,210 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/overbooking"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+)
+
+type GetCalendarQuery struct {
+	HotelID uuid.UUID
+	Month   time.Time
+	// Optional stay to summarize. Both dates are inclusive, the same way makeReservation consumes inventory.
+	CheckIn  *time.Time
+	CheckOut *time.Time
+}
+
+type AvailabilityCalendar struct {
+	HotelID   uuid.UUID          `json:"hotel_id"`
+	Month     string             `json:"month"`
+	RoomTypes []RoomTypeCalendar `json:"room_types"`
+}
+
+type RoomTypeCalendar struct {
+	RoomTypeID   uuid.UUID     `json:"room_type_id"`
+	RoomTypeName string        `json:"room_type_name"`
+	Description  string        `json:"description,omitempty"`
+	Days         []CalendarDay `json:"days"`
+	Stay         *StaySummary  `json:"stay,omitempty"`
+}
+
+type CalendarDay struct {
+	Date           shared.Date      `json:"date"`
+	TotalInventory int32            `json:"total_inventory"`
+	TotalReserved  int32            `json:"total_reserved"`
+	Available      int32            `json:"available"`
+	Bookable       bool             `json:"bookable"`
+	SoldOut        bool             `json:"sold_out"`
+	Restrictions   *DayRestrictions `json:"restrictions,omitempty"`
+}
+
+type StaySummary struct {
+	CheckIn      shared.Date `json:"check_in"`
+	CheckOut     shared.Date `json:"check_out"`
+	Nights       int         `json:"nights"`
+	Bookable     bool        `json:"bookable"`
+	MinAvailable int32       `json:"min_available"`
+	Violations   []string    `json:"violations,omitempty"`
+}
+
+func (s *ReservationService) getAvailabilityCalendar(ctx context.Context, payload GetCalendarQuery) (AvailabilityCalendar, error) {
+	if payload.CheckIn != nil && payload.CheckOut != nil && stayNights(*payload.CheckIn, *payload.CheckOut) > s.cfg.Booking.MaxStayNights {
+		return AvailabilityCalendar{}, fmt.Errorf("%w: at most %d nights can be checked", ErrStayTooLong, s.cfg.Booking.MaxStayNights)
+	}
+
+	policies, err := s.overbooking.HotelPolicies(ctx, payload.HotelID)
+	if err != nil {
+		return AvailabilityCalendar{}, err
+	}
+
+	monthStart := time.Date(payload.Month.Year(), payload.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	// Stay dates may fall outside of the requested month, so fetch the union of both ranges at once.
+	rangeFrom, rangeTo := monthStart, monthEnd
+	if payload.CheckIn != nil && payload.CheckOut != nil {
+		if payload.CheckIn.Before(rangeFrom) {
+			rangeFrom = *payload.CheckIn
+		}
+		if payload.CheckOut.After(rangeTo) {
+			rangeTo = *payload.CheckOut
+		}
+	}
+
+	roomTypes, err := s.queries.GetHotelRoomTypes(ctx, payload.HotelID)
+	if err != nil {
+		return AvailabilityCalendar{}, fmt.Errorf("failed to get room types for hotel %q: %w", payload.HotelID, err)
+	}
+
+	inventory, err := s.queries.GetHotelInventoryForDates(ctx, database.GetHotelInventoryForDatesParams{
+		HotelID:  payload.HotelID,
+		DateFrom: rangeFrom,
+		DateTo:   rangeTo,
+	})
+	if err != nil {
+		return AvailabilityCalendar{}, fmt.Errorf("failed to get inventory for hotel %q: %w", payload.HotelID, err)
+	}
+
+	restrictions, err := s.queries.GetHotelRestrictionsForDates(ctx, database.GetHotelRestrictionsForDatesParams{
+		HotelID:  payload.HotelID,
+		DateFrom: rangeFrom,
+		// Departure rules of the stay apply on the day after its last night.
+		DateTo: rangeTo.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return AvailabilityCalendar{}, fmt.Errorf("failed to get restrictions for hotel %q: %w", payload.HotelID, err)
+	}
+
+	restrictionsByRoomType := make(map[uuid.UUID][]database.BookingRoomTypeRestriction)
+	for _, r := range restrictions {
+		restrictionsByRoomType[r.RoomTypeID] = append(restrictionsByRoomType[r.RoomTypeID], r)
+	}
+
+	inventoryByRoomType := make(map[uuid.UUID]map[string]database.BookingRoomTypeInventory, len(roomTypes))
+	for _, inv := range inventory {
+		if _, ok := inventoryByRoomType[inv.RoomTypeID]; !ok {
+			inventoryByRoomType[inv.RoomTypeID] = make(map[string]database.BookingRoomTypeInventory)
+		}
+		inventoryByRoomType[inv.RoomTypeID][inv.Date.Format(time.DateOnly)] = inv
+	}
+
+	calendar := AvailabilityCalendar{
+		HotelID:   payload.HotelID,
+		Month:     monthStart.Format("2006-01"),
+		RoomTypes: make([]RoomTypeCalendar, 0, len(roomTypes)),
+	}
+
+	for _, roomType := range roomTypes {
+		byDate := inventoryByRoomType[roomType.ID]
+		restrictedDates := restrictionsByDate(restrictionsByRoomType[roomType.ID])
+
+		rtCalendar := RoomTypeCalendar{
+			RoomTypeID:   roomType.ID,
+			RoomTypeName: roomType.Name,
+			Description:  roomType.Description.String,
+		}
+
+		for d := monthStart; !d.After(monthEnd); d = d.AddDate(0, 0, 1) {
+			rtCalendar.Days = append(rtCalendar.Days, buildCalendarDay(d, byDate, restrictedDates, policies))
+		}
+
+		if payload.CheckIn != nil && payload.CheckOut != nil {
+			stay := summarizeStay(*payload.CheckIn, *payload.CheckOut, byDate, restrictedDates, policies)
+			rtCalendar.Stay = &stay
+		}
+
+		calendar.RoomTypes = append(calendar.RoomTypes, rtCalendar)
+	}
+
+	return calendar, nil
+}
+
+func buildCalendarDay(date time.Time, byDate map[string]database.BookingRoomTypeInventory, restrictedDates map[string]database.BookingRoomTypeRestriction, policies overbooking.Policies) CalendarDay {
+	day := CalendarDay{Date: shared.Date(date)}
+
+	restriction, restricted := restrictedDates[date.Format(time.DateOnly)]
+	if restricted {
+		day.Restrictions = newDayRestrictions(restriction)
+	}
+
+	inv, ok := byDate[date.Format(time.DateOnly)]
+	if !ok {
+		// No inventory was generated for this date yet, so it can't be booked but isn't sold out either.
+		return day
+	}
+
+	day.TotalInventory = inv.TotalInventory
+	day.TotalReserved = inv.TotalReserved
+	day.Available = availableCapacity(inv, policies)
+	day.SoldOut = day.Available == 0
+	day.Bookable = !day.SoldOut && !(restricted && restriction.StopSell)
+
+	return day
+}
+
+// stayNights counts the nights of a stay whose dates are both inclusive.
+func stayNights(checkIn, checkOut time.Time) int {
+	return int(checkOut.Sub(checkIn).Hours()/24) + 1
+}
+
+func summarizeStay(checkIn, checkOut time.Time, byDate map[string]database.BookingRoomTypeInventory, restrictedDates map[string]database.BookingRoomTypeRestriction, policies overbooking.Policies) StaySummary {
+	stay := StaySummary{
+		CheckIn:  shared.Date(checkIn),
+		CheckOut: shared.Date(checkOut),
+		Bookable: true,
+	}
+
+	for d := checkIn; !d.After(checkOut); d = d.AddDate(0, 0, 1) {
+		stay.Nights++
+
+		inv, ok := byDate[d.Format(time.DateOnly)]
+		available := int32(0)
+		if ok {
+			available = availableCapacity(inv, policies)
+		}
+
+		if stay.Nights == 1 || available < stay.MinAvailable {
+			stay.MinAvailable = available
+		}
+		if available <= 0 {
+			stay.Bookable = false
+		}
+	}
+
+	stay.Violations = stayRestrictionViolations(checkIn, checkOut, restrictedDates)
+	if len(stay.Violations) > 0 {
+		stay.Bookable = false
+	}
+
+	return stay
+}
+
+// availableCapacity mirrors the capacity check in makeReservation: how many more reservations fit under the overbooking ceiling.
+func availableCapacity(inv database.BookingRoomTypeInventory, policies overbooking.Policies) int32 {
+	return max(policies.MaxCapacity(inv)-inv.TotalReserved, 0)
+}
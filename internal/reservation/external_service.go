@@ -0,0 +1,39 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/google/uuid"
+)
+
+// ReserveExternal books a stay sold outside of this API, such as through a booking channel, inside the
+// caller's transaction. It goes through the same restriction, overbooking and optimistic-locking checks
+// as MakeReservationHandler. Count it in metrics.ReservationsCreated once the transaction commits.
+func (s *ReservationService) ReserveExternal(ctx context.Context, qtx *database.Queries, params database.InsertReservationParams) error {
+	if params.Status == "" {
+		params.Status = string(ReservationStatusPending)
+	}
+	return s.reserveInventory(ctx, qtx, params)
+}
+
+// CancelExternal cancels a reservation on behalf of an external system inside the caller's transaction.
+func (s *ReservationService) CancelExternal(ctx context.Context, qtx *database.Queries, reservationID uuid.UUID) (database.BookingReservation, error) {
+	reservation, err := qtx.GetReservationByIdForUpdate(ctx, reservationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingReservation{}, ErrReservationNotFound
+	}
+	if err != nil {
+		return database.BookingReservation{}, fmt.Errorf("failed to get reservation %q: %w", reservationID, err)
+	}
+
+	if err := s.cancelLocked(ctx, qtx, reservation); err != nil {
+		return reservation, err
+	}
+
+	reservation.Status = string(ReservationStatusCancelled)
+	return reservation, nil
+}
@@ -2,21 +2,51 @@ package reservation
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/config"
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/overbooking"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
 	"github.com/go-playground/validator/v10"
 )
 
 var (
-	ErrInventoryCapacityReached = errors.New("reached maximum inventory capacity for date")
-	ErrOptimisticLockMismatch   = errors.New("optimistic lock mismatch - inventory updated by another transaction")
-	ErrInvalidReservationID     = errors.New("invalid reservation ID format")
-	ErrDuplicateReservation     = errors.New("reservation with this ID already exists")
-	ErrInvalidOverbookingFactor = errors.New("invalid overbooking factor")
-	ErrInventoryNotFound        = errors.New("no inventory found for specified dates")
+	ErrInventoryCapacityReached  = errors.New("reached maximum inventory capacity for date")
+	ErrOptimisticLockMismatch    = errors.New("optimistic lock mismatch - inventory updated by another transaction")
+	ErrInvalidReservationID      = errors.New("invalid reservation ID format")
+	ErrDuplicateReservation      = errors.New("reservation with this ID already exists")
+	ErrInventoryNotFound         = errors.New("no inventory found for specified dates")
+	ErrStayRestricted            = errors.New("stay violates room type restrictions")
+	ErrReservationNotFound       = errors.New("reservation not found")
+	ErrReservationNotCancellable = errors.New("reservation can't be cancelled")
+	ErrWaitlistEntryNotFound     = errors.New("waitlist entry not found")
+	ErrWaitlistOfferNotActive    = errors.New("waitlist entry has no active offer")
+	ErrInvalidStayDates          = errors.New("invalid stay dates")
+	ErrStayTooLong               = errors.New("stay is too long")
+	ErrReservationNotModifiable  = errors.New("reservation can't be modified")
+	ErrRoomTypeNotFound          = errors.New("room type not found")
 )
 
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrInventoryCapacityReached:  {Status: http.StatusConflict, Code: "inventory.capacity_reached", Message: "No availability for selected dates, join the waitlist to get notified"},
+		ErrOptimisticLockMismatch:    {Status: http.StatusConflict, Code: "inventory.concurrent_update", Message: "Reservation conflict - please try again"},
+		ErrInvalidReservationID:      {Status: http.StatusBadRequest, Code: "reservation.invalid_id", Message: "Invalid reservation ID"},
+		ErrDuplicateReservation:      {Status: http.StatusConflict, Code: "reservation.duplicate_id", Message: "Reservation ID already exists"},
+		ErrInventoryNotFound:         {Status: http.StatusConflict, Code: "inventory.not_found", Message: "No inventory for selected dates"},
+		ErrStayRestricted:            {Status: http.StatusConflict, Code: "reservation.stay_restricted"},
+		ErrReservationNotFound:       {Status: http.StatusNotFound, Code: "reservation.not_found", Message: "Reservation not found"},
+		ErrReservationNotCancellable: {Status: http.StatusConflict, Code: "reservation.not_cancellable"},
+		ErrWaitlistEntryNotFound:     {Status: http.StatusNotFound, Code: "waitlist.entry_not_found", Message: "Waitlist entry not found"},
+		ErrWaitlistOfferNotActive:    {Status: http.StatusConflict, Code: "waitlist.offer_not_active", Message: "No active offer for this waitlist entry"},
+		ErrInvalidStayDates:          {Status: http.StatusBadRequest, Code: "reservation.invalid_stay_dates", Message: "endDate should not be before startDate"},
+		ErrStayTooLong:               {Status: http.StatusBadRequest, Code: "reservation.stay_too_long"},
+		ErrReservationNotModifiable:  {Status: http.StatusConflict, Code: "reservation.not_modifiable"},
+		ErrRoomTypeNotFound:          {Status: http.StatusNotFound, Code: "room_type.not_found", Message: "Room type not found"},
+	})
+}
+
 type ReservationState string
 
 const (
@@ -24,20 +54,33 @@ const (
 	ReservationStatusPaid     ReservationState = "paid"
 	ReservationStatusRejected ReservationState = "rejected"
 	ReservationStatusRefunded ReservationState = "refunded"
+	// Held reservations take inventory on behalf of a waitlisted guest until the offer is confirmed or expires.
+	ReservationStatusHeld      ReservationState = "held"
+	ReservationStatusCancelled ReservationState = "cancelled"
 )
 
+var cancellableStatuses = []ReservationState{ReservationStatusPending, ReservationStatusPaid, ReservationStatusHeld}
+
+// Holds are confirmed or released as offered, only bookings of the guest can move.
+var modifiableStatuses = []ReservationState{ReservationStatusPending, ReservationStatusPaid}
+
+// HoldingStatuses take a room for every night of the stay, total_reserved counts reservations in them.
+var HoldingStatuses = []ReservationState{ReservationStatusPending, ReservationStatusPaid, ReservationStatusHeld}
+
 type ReservationService struct {
-	queries   *database.Queries
-	validator *validator.Validate
-	cfg       *config.Config
-	db        database.Service
+	queries     *database.Queries
+	validator   *validator.Validate
+	cfg         *config.Config
+	db          database.Service
+	overbooking *overbooking.OverbookingService
 }
 
-func New(queries *database.Queries, validator *validator.Validate, cfg *config.Config, db database.Service) *ReservationService {
+func New(queries *database.Queries, validator *validator.Validate, cfg *config.Config, db database.Service, overbookingSvc *overbooking.OverbookingService) *ReservationService {
 	return &ReservationService{
-		queries:   queries,
-		validator: validator,
-		cfg:       cfg,
-		db:        db,
+		queries:     queries,
+		validator:   validator,
+		cfg:         cfg,
+		db:          db,
+		overbooking: overbookingSvc,
 	}
 }
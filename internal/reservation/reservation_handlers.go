@@ -2,12 +2,12 @@ package reservation
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
 	"github.com/AlexKhomenko00/hotel-system/internal/shared"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -17,6 +17,17 @@ func (s *ReservationService) RegisterHandlers(r chi.Router) {
 	r.Get("/generate-id", s.GenerateReservationIdHandler)
 	r.Post("/", s.MakeReservationHandler)
 	r.Get("/availability", s.GetRoomAvailabilityHandler)
+	r.Get("/availability/calendar", s.GetAvailabilityCalendarHandler)
+	r.Post("/{reservationId}/cancel", s.CancelReservationHandler)
+	r.Post("/{reservationId}/modify", s.ModifyReservationHandler)
+
+	r.Route("/waitlist", func(r chi.Router) {
+		r.Get("/", s.ListWaitlistHandler)
+		r.Post("/", s.JoinWaitlistHandler)
+		r.Get("/{entryId}", s.GetWaitlistEntryHandler)
+		r.Post("/{entryId}/confirm", s.ConfirmWaitlistOfferHandler)
+		r.Delete("/{entryId}", s.LeaveWaitlistHandler)
+	})
 }
 
 func (s *ReservationService) GenerateReservationIdHandler(w http.ResponseWriter, r *http.Request) {
@@ -39,35 +50,71 @@ func (s *ReservationService) MakeReservationHandler(w http.ResponseWriter, r *ht
 	}
 
 	if err := s.validator.Struct(&body); err != nil {
-		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err.Error()))
+		shared.WriteValidationError(w, err)
+		return
+	}
+	logging.Add(r.Context(), "hotel_id", body.HotelID)
+
+	if err := s.makeReservation(r.Context(), usr.GuestId, body); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"message": "Reservation created successfully", "reservation_id": body.ReservationId})
+}
+func (s *ReservationService) CancelReservationHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
 		return
 	}
 
-	err := s.makeReservation(r.Context(), usr.GuestId, body)
+	reservationID, err := uuid.Parse(chi.URLParam(r, "reservationId"))
 	if err != nil {
-		if errors.Is(err, ErrInventoryCapacityReached) {
-			shared.WriteError(w, http.StatusConflict, "No availability for selected dates")
-			return
-		}
-		if errors.Is(err, ErrInvalidReservationID) {
-			shared.WriteError(w, http.StatusBadRequest, "Invalid reservation ID")
-			return
-		}
-		if errors.Is(err, ErrOptimisticLockMismatch) {
-			shared.WriteError(w, http.StatusConflict, "Reservation conflict - please try again")
-			return
-		}
-		if errors.Is(err, ErrDuplicateReservation) {
-			shared.WriteError(w, http.StatusConflict, "Reservation ID already exists")
-			return
-		}
+		shared.WriteError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
 
-		shared.WriteError(w, http.StatusInternalServerError, "Sorry, something went wrong")
+	if err := s.cancelReservation(r.Context(), usr.GuestId, reservationID); err != nil {
+		shared.WriteDomainError(w, err)
 		return
 	}
 
-	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"message": "Reservation created successfully", "reservation_id": body.ReservationId})
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"message": "Reservation cancelled successfully", "reservation_id": reservationID})
+}
+
+func (s *ReservationService) ModifyReservationHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	reservationID, err := uuid.Parse(chi.URLParam(r, "reservationId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	var body ModifyReservationBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	if err := s.modifyReservation(r.Context(), usr.GuestId, reservationID, body); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"message": "Reservation modified successfully", "reservation_id": reservationID})
 }
+
 func (s *ReservationService) GetRoomAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
 	hotelIDStr := r.URL.Query().Get("hotelId")
 	checkInStr := r.URL.Query().Get("checkIn")
@@ -109,3 +156,62 @@ func (s *ReservationService) GetRoomAvailabilityHandler(w http.ResponseWriter, r
 
 	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"availability": availability})
 }
+
+func (s *ReservationService) GetAvailabilityCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(r.URL.Query().Get("hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotelId")
+		return
+	}
+
+	query := GetCalendarQuery{HotelID: hotelID}
+
+	checkInStr := r.URL.Query().Get("checkIn")
+	checkOutStr := r.URL.Query().Get("checkOut")
+	if checkInStr != "" || checkOutStr != "" {
+		checkIn, err := time.Parse(time.DateOnly, checkInStr)
+		if err != nil {
+			shared.WriteError(w, http.StatusBadRequest, "Invalid checkIn")
+			return
+		}
+
+		checkOut, err := time.Parse(time.DateOnly, checkOutStr)
+		if err != nil {
+			shared.WriteError(w, http.StatusBadRequest, "Invalid checkOut")
+			return
+		}
+
+		if !checkOut.After(checkIn) {
+			shared.WriteError(w, http.StatusBadRequest, "checkOut should be after checkIn date")
+			return
+		}
+
+		query.CheckIn = &checkIn
+		query.CheckOut = &checkOut
+	}
+
+	// Month defaults to the check-in month so a date picker can open straight on the requested stay.
+	monthStr := r.URL.Query().Get("month")
+	switch {
+	case monthStr != "":
+		month, err := time.Parse("2006-01", monthStr)
+		if err != nil {
+			shared.WriteError(w, http.StatusBadRequest, "Invalid month, expected YYYY-MM")
+			return
+		}
+		query.Month = month
+	case query.CheckIn != nil:
+		query.Month = *query.CheckIn
+	default:
+		shared.WriteError(w, http.StatusBadRequest, "Either month or checkIn/checkOut is required")
+		return
+	}
+
+	calendar, err := s.getAvailabilityCalendar(r.Context(), query)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"calendar": calendar})
+}
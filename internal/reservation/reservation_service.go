@@ -2,13 +2,20 @@ package reservation
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/metrics"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -20,7 +27,18 @@ type MakeReservationBody struct {
 	ReservationId string    `json:"reservationId" validate:"uuid4"`
 }
 
-func (s *ReservationService) makeReservation(ctx context.Context, guestID uuid.UUID, body MakeReservationBody) error {
+func (s *ReservationService) makeReservation(ctx context.Context, guestID uuid.UUID, body MakeReservationBody) (err error) {
+	ctx, span := tracing.Start(ctx, "reservation.make",
+		attribute.String("hotel.id", body.HotelID.String()),
+		attribute.String("room_type.id", body.RoomTypeID.String()),
+		attribute.String("reservation.id", body.ReservationId))
+	defer func() { tracing.End(span, err) }()
+
+	reservationUUID, err := uuid.Parse(body.ReservationId)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReservationID, err)
+	}
+
 	tx, err := s.db.GetDB().BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to start make reservation transaction %w", err)
@@ -29,66 +47,162 @@ func (s *ReservationService) makeReservation(ctx context.Context, guestID uuid.U
 
 	qtx := s.queries.WithTx(tx)
 
-	inventory, err := qtx.GetHotelInventoryForRange(ctx, database.GetHotelInventoryForRangeParams{
-		RoomTypeID: body.RoomTypeID,
+	err = s.reserveInventory(ctx, qtx, database.InsertReservationParams{
+		ID:         reservationUUID,
 		HotelID:    body.HotelID,
-		Date:       body.StartDate,
-		Date_2:     body.EndDate,
+		RoomTypeID: body.RoomTypeID,
+		StartDate:  body.StartDate,
+		EndDate:    body.EndDate,
+		Status:     string(ReservationStatusPending),
+		GuestID:    guestID,
 	})
-
 	if err != nil {
-		return fmt.Errorf("failed to get hotel %q inventory: %w", body.HotelID, err)
+		return err
 	}
 
-	expectedDays := int(body.EndDate.Sub(body.StartDate).Hours()/24) + 1
-	if len(inventory) != expectedDays {
-		return ErrInventoryNotFound
+	if err = tx.Commit(); err != nil {
+		return err
 	}
+	metrics.ReservationsCreated.Inc()
+	return nil
+}
 
-	overbookingFactor, err := strconv.ParseFloat(s.cfg.OVERBOOKING_FACTOR, 64)
-
+// reserveInventory checks the stay against inventory, restrictions and overbooking policy, then inserts the
+// reservation and takes one room per night. It runs inside the caller's transaction, which counts the
+// reservation in metrics.ReservationsCreated once it commits, or once the guest confirms it for a hold.
+func (s *ReservationService) reserveInventory(ctx context.Context, qtx *database.Queries, params database.InsertReservationParams) error {
+	inventory, err := s.checkStay(ctx, qtx, params.HotelID, params.RoomTypeID, params.StartDate, params.EndDate)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidOverbookingFactor, err)
+		return err
 	}
 
-	for _, inventoryDate := range inventory {
-		maxCapacity := int32(float64(inventoryDate.TotalInventory) * overbookingFactor)
-		if (inventoryDate.TotalReserved + 1) > maxCapacity {
-			return fmt.Errorf("%w: %s", ErrInventoryCapacityReached, inventoryDate.Date)
+	_, err = qtx.InsertReservation(ctx, params)
+
+	if err != nil {
+		// Check for duplicate key violation (primary key constraint on reservation ID)
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
+		   strings.Contains(err.Error(), "reservations_pkey") {
+			metrics.DuplicateReservations.Inc()
+			return fmt.Errorf("%w: %v", ErrDuplicateReservation, err)
 		}
+		return fmt.Errorf("failed to insert reservation %w", err)
 	}
 
-	reservationUUID, err := uuid.Parse(body.ReservationId)
+	if err := updateInventory(ctx, qtx, inventory, 1); err != nil {
+		return fmt.Errorf("failed to update inventory: %w", err)
+	}
+
+	reservation := database.BookingReservation{
+		ID:         params.ID,
+		HotelID:    params.HotelID,
+		RoomTypeID: params.RoomTypeID,
+		StartDate:  params.StartDate,
+		EndDate:    params.EndDate,
+		Status:     params.Status,
+		GuestID:    params.GuestID,
+	}
+	if err := events.RecordReservation(ctx, qtx, events.ReservationCreated, reservation); err != nil {
+		return err
+	}
+
+	if err := recordInventoryChanged(ctx, qtx, reservation, 1); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkStay returns the inventory of every night of a stay once it passed the restrictions and fits under the
+// overbooking ceiling.
+func (s *ReservationService) checkStay(ctx context.Context, qtx *database.Queries, hotelID, roomTypeID uuid.UUID, startDate, endDate time.Time) ([]database.BookingRoomTypeInventory, error) {
+	inventory, err := qtx.GetHotelInventoryForRange(ctx, database.GetHotelInventoryForRangeParams{
+		RoomTypeID: roomTypeID,
+		HotelID:    hotelID,
+		Date:       startDate,
+		Date_2:     endDate,
+	})
+
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidReservationID, err)
+		return nil, fmt.Errorf("failed to get hotel %q inventory: %w", hotelID, err)
 	}
 
-	_, err = qtx.InsertReservation(ctx, database.InsertReservationParams{
-		ID:         reservationUUID,
-		HotelID:    body.HotelID,
-		RoomTypeID: body.RoomTypeID,
-		StartDate:  body.StartDate,
-		EndDate:    body.EndDate,
-		Status:     string(ReservationStatusPending),
-		GuestID:    guestID,
+	expectedDays := int(endDate.Sub(startDate).Hours()/24) + 1
+	if len(inventory) != expectedDays {
+		return nil, ErrInventoryNotFound
+	}
+
+	restrictions, err := qtx.GetRoomTypeRestrictionsForRange(ctx, database.GetRoomTypeRestrictionsForRangeParams{
+		HotelID:    hotelID,
+		RoomTypeID: roomTypeID,
+		DateFrom:   startDate,
+		// Departure rules apply on the day the guest leaves.
+		DateTo: endDate.AddDate(0, 0, 1),
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hotel %q restrictions: %w", hotelID, err)
+	}
+
+	if violations := stayRestrictionViolations(startDate, endDate, restrictionsByDate(restrictions)); len(violations) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrStayRestricted, strings.Join(violations, "; "))
+	}
 
+	policies, err := s.overbooking.HotelPolicies(ctx, hotelID)
 	if err != nil {
-		// Check for duplicate key violation (primary key constraint on reservation ID)
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
-		   strings.Contains(err.Error(), "reservations_pkey") {
-			return fmt.Errorf("%w: %v", ErrDuplicateReservation, err)
+		return nil, err
+	}
+
+	for _, inventoryDate := range inventory {
+		if (inventoryDate.TotalReserved + 1) > policies.MaxCapacity(inventoryDate) {
+			metrics.CapacityRejections.Inc()
+			return nil, fmt.Errorf("%w: %s", ErrInventoryCapacityReached, inventoryDate.Date)
 		}
-		return fmt.Errorf("failed to insert reservation %w", err)
 	}
 
+	return inventory, nil
+}
+
+// releaseInventory gives back the rooms a reservation took, inside the caller's transaction.
+func (s *ReservationService) releaseInventory(ctx context.Context, qtx *database.Queries, reservation database.BookingReservation) error {
+	inventory, err := qtx.GetHotelInventoryForRange(ctx, database.GetHotelInventoryForRangeParams{
+		RoomTypeID: reservation.RoomTypeID,
+		HotelID:    reservation.HotelID,
+		Date:       reservation.StartDate,
+		Date_2:     reservation.EndDate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get hotel %q inventory: %w", reservation.HotelID, err)
+	}
+
+	if err := updateInventory(ctx, qtx, inventory, -1); err != nil {
+		return fmt.Errorf("failed to release inventory: %w", err)
+	}
+
+	return recordInventoryChanged(ctx, qtx, reservation, -1)
+}
+
+func recordInventoryChanged(ctx context.Context, qtx *database.Queries, reservation database.BookingReservation, delta int32) error {
+	return events.Record(ctx, qtx, events.InventoryChanged, events.AggregateRoomType, reservation.RoomTypeID, events.InventoryChangedPayload{
+		HotelID:       reservation.HotelID,
+		RoomTypeID:    reservation.RoomTypeID,
+		From:          shared.Date(reservation.StartDate),
+		To:            shared.Date(reservation.EndDate),
+		ReservedDelta: delta,
+	})
+}
+
+func updateInventory(ctx context.Context, qtx *database.Queries, inventory []database.BookingRoomTypeInventory, delta int32) error {
 	g := new(errgroup.Group)
 
 	for _, inventoryDate := range inventory {
-		g.Go(func() error {
+		g.Go(func() (err error) {
+			ctx, span := tracing.Start(ctx, "inventory.update_date",
+				attribute.String("inventory.date", inventoryDate.Date.Format(time.DateOnly)),
+				attribute.Int("inventory.delta", int(delta)))
+			defer func() { tracing.End(span, err) }()
+
 			rowsCount, err := qtx.UpdateRoomTypeInventoryForDate(ctx, database.UpdateRoomTypeInventoryForDateParams{
 				RoomTypeID:    inventoryDate.RoomTypeID,
-				TotalReserved: 1,
+				TotalReserved: delta,
 				HotelID:       inventoryDate.HotelID,
 				Date:          inventoryDate.Date,
 				Version:       inventoryDate.Version,
@@ -99,6 +213,7 @@ func (s *ReservationService) makeReservation(ctx context.Context, guestID uuid.U
 			}
 
 			if rowsCount == 0 {
+				metrics.LockConflicts.Inc()
 				return fmt.Errorf("%w: date %q, hotel %q, room type %q", ErrOptimisticLockMismatch, inventoryDate.Date, inventoryDate.HotelID, inventoryDate.RoomTypeID)
 			}
 
@@ -106,12 +221,140 @@ func (s *ReservationService) makeReservation(ctx context.Context, guestID uuid.U
 		})
 	}
 
-	if err := g.Wait(); err != nil {
-		return fmt.Errorf("failed to update inventory: %w", err)
+	return g.Wait()
+}
+
+// cancelReservation cancels a guest's reservation and frees its inventory. The rooms are offered to the
+// waitlist once the cancellation event is relayed, see HandleEvent.
+func (s *ReservationService) cancelReservation(ctx context.Context, guestID, reservationID uuid.UUID) error {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start cancel reservation transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	reservation, err := qtx.GetReservationByIdForUpdate(ctx, reservationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrReservationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reservation %q: %w", reservationID, err)
+	}
+
+	if reservation.GuestID != guestID {
+		return ErrReservationNotFound
+	}
+
+	if err := s.cancelLocked(ctx, qtx, reservation); err != nil {
+		return err
 	}
 
 	return tx.Commit()
+}
+
+// cancelLocked cancels a reservation read with GetReservationByIdForUpdate and releases its inventory,
+// inside the caller's transaction. Cancelling a hold also withdraws the waitlist offer it belongs to.
+func (s *ReservationService) cancelLocked(ctx context.Context, qtx *database.Queries, reservation database.BookingReservation) error {
+	if !slices.Contains(cancellableStatuses, ReservationState(reservation.Status)) {
+		return fmt.Errorf("%w: reservation is %s", ErrReservationNotCancellable, reservation.Status)
+	}
+
+	if ReservationState(reservation.Status) == ReservationStatusHeld {
+		if err := qtx.CancelWaitlistOfferForReservation(ctx, uuid.NullUUID{UUID: reservation.ID, Valid: true}); err != nil {
+			return fmt.Errorf("failed to cancel waitlist offer of reservation %q: %w", reservation.ID, err)
+		}
+	}
+
+	if err := qtx.UpdateReservationStatus(ctx, database.UpdateReservationStatusParams{
+		Status: string(ReservationStatusCancelled),
+		ID:     reservation.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to cancel reservation %q: %w", reservation.ID, err)
+	}
+
+	if err := s.releaseInventory(ctx, qtx, reservation); err != nil {
+		return err
+	}
+
+	previousStatus := reservation.Status
+	reservation.Status = string(ReservationStatusCancelled)
+	return events.RecordReservationCancelled(ctx, qtx, reservation, previousStatus)
+}
+
+type ModifyReservationBody struct {
+	StartDate time.Time `json:"startDate" validate:"required"`
+	EndDate   time.Time `json:"endDate" validate:"required"`
+}
 
+// modifyReservation moves a guest's reservation to new dates. The old nights are given back before the new ones
+// are checked, so a stay can shift over the nights it already holds.
+func (s *ReservationService) modifyReservation(ctx context.Context, guestID, reservationID uuid.UUID, body ModifyReservationBody) (err error) {
+	ctx, span := tracing.Start(ctx, "reservation.modify",
+		attribute.String("reservation.id", reservationID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	if body.EndDate.Before(body.StartDate) {
+		return ErrInvalidStayDates
+	}
+
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start modify reservation transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	reservation, err := qtx.GetReservationByIdForUpdate(ctx, reservationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrReservationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reservation %q: %w", reservationID, err)
+	}
+
+	if reservation.GuestID != guestID {
+		return ErrReservationNotFound
+	}
+
+	if !slices.Contains(modifiableStatuses, ReservationState(reservation.Status)) {
+		return fmt.Errorf("%w: reservation is %s", ErrReservationNotModifiable, reservation.Status)
+	}
+
+	if err := s.releaseInventory(ctx, qtx, reservation); err != nil {
+		return err
+	}
+
+	inventory, err := s.checkStay(ctx, qtx, reservation.HotelID, reservation.RoomTypeID, body.StartDate, body.EndDate)
+	if err != nil {
+		return err
+	}
+
+	if err := updateInventory(ctx, qtx, inventory, 1); err != nil {
+		return fmt.Errorf("failed to update inventory: %w", err)
+	}
+
+	if err := qtx.UpdateReservationDates(ctx, database.UpdateReservationDatesParams{
+		StartDate: body.StartDate,
+		EndDate:   body.EndDate,
+		ID:        reservation.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to modify reservation %q: %w", reservation.ID, err)
+	}
+
+	reservation.StartDate = body.StartDate
+	reservation.EndDate = body.EndDate
+	if err := recordInventoryChanged(ctx, qtx, reservation, 1); err != nil {
+		return err
+	}
+
+	if err := events.RecordReservation(ctx, qtx, events.ReservationModified, reservation); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (s *ReservationService) generateReservationId() string {
@@ -129,21 +372,29 @@ type GetAvailabilityQuery struct {
 }
 
 func (s *ReservationService) getRoomAvailability(ctx context.Context, payload GetAvailabilityQuery) ([]database.GetRoomAvailabilityByDatesRow, error) {
-	overbookingFactor, err := strconv.ParseFloat(s.cfg.OVERBOOKING_FACTOR, 64)
+	policies, err := s.overbooking.HotelPolicies(ctx, payload.HotelID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidOverbookingFactor, err)
+		return nil, err
 	}
 
-	availability, err := s.queries.GetRoomAvailabilityByDates(ctx, database.GetRoomAvailabilityByDatesParams{
-		HotelID:     payload.HotelID,
-		CheckIn:     payload.CheckIn,
-		CheckOut:    payload.CheckOut,
-		Overbooking: float64(overbookingFactor),
+	rows, err := s.queries.GetRoomAvailabilityByDates(ctx, database.GetRoomAvailabilityByDatesParams{
+		HotelID:  payload.HotelID,
+		CheckIn:  payload.CheckIn,
+		CheckOut: payload.CheckOut,
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get room availability for hotel %q: %w", payload.HotelID, err)
 	}
 
+	// Overbooking differs per room type and date, so the ceiling is applied here rather than in SQL.
+	availability := make([]database.GetRoomAvailabilityByDatesRow, 0, len(rows))
+	for _, row := range rows {
+		maxCapacity := int32(float64(row.TotalInventory) * policies.Factor(row.RoomTypeID, row.Date))
+		if row.TotalReserved < maxCapacity {
+			availability = append(availability, row)
+		}
+	}
+
 	return availability, nil
 }
@@ -0,0 +1,77 @@
+package reservation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+)
+
+// DayRestrictions is the availability view of a booking.room_type_restrictions row.
+type DayRestrictions struct {
+	MinLengthOfStay   *int32 `json:"min_length_of_stay,omitempty"`
+	MaxLengthOfStay   *int32 `json:"max_length_of_stay,omitempty"`
+	ClosedToArrival   bool   `json:"closed_to_arrival"`
+	ClosedToDeparture bool   `json:"closed_to_departure"`
+	StopSell          bool   `json:"stop_sell"`
+}
+
+func newDayRestrictions(r database.BookingRoomTypeRestriction) *DayRestrictions {
+	dr := &DayRestrictions{
+		ClosedToArrival:   r.ClosedToArrival,
+		ClosedToDeparture: r.ClosedToDeparture,
+		StopSell:          r.StopSell,
+	}
+	if r.MinLengthOfStay.Valid {
+		dr.MinLengthOfStay = &r.MinLengthOfStay.Int32
+	}
+	if r.MaxLengthOfStay.Valid {
+		dr.MaxLengthOfStay = &r.MaxLengthOfStay.Int32
+	}
+	return dr
+}
+
+func restrictionsByDate(restrictions []database.BookingRoomTypeRestriction) map[string]database.BookingRoomTypeRestriction {
+	byDate := make(map[string]database.BookingRoomTypeRestriction, len(restrictions))
+	for _, r := range restrictions {
+		byDate[r.Date.Format(time.DateOnly)] = r
+	}
+	return byDate
+}
+
+// stayRestrictionViolations lists every restriction the stay breaks.
+//
+// Stays consume inventory from checkIn to checkOut inclusive (see makeReservation), so checkOut
+// is the last night and the length of stay counts both ends. Arrival rules are evaluated on
+// checkIn and departure rules on the day after checkOut, when the guest leaves. byDate must
+// cover that day. Length of stay limits follow the usual convention of applying on the arrival date.
+func stayRestrictionViolations(checkIn, checkOut time.Time, byDate map[string]database.BookingRoomTypeRestriction) []string {
+	var violations []string
+
+	lengthOfStay := int32(checkOut.Sub(checkIn).Hours()/24) + 1
+
+	if arrival, ok := byDate[checkIn.Format(time.DateOnly)]; ok {
+		if arrival.ClosedToArrival {
+			violations = append(violations, fmt.Sprintf("closed to arrival on %s", checkIn.Format(time.DateOnly)))
+		}
+		if arrival.MinLengthOfStay.Valid && lengthOfStay < arrival.MinLengthOfStay.Int32 {
+			violations = append(violations, fmt.Sprintf("minimum length of stay is %d nights", arrival.MinLengthOfStay.Int32))
+		}
+		if arrival.MaxLengthOfStay.Valid && lengthOfStay > arrival.MaxLengthOfStay.Int32 {
+			violations = append(violations, fmt.Sprintf("maximum length of stay is %d nights", arrival.MaxLengthOfStay.Int32))
+		}
+	}
+
+	departureDate := checkOut.AddDate(0, 0, 1)
+	if departure, ok := byDate[departureDate.Format(time.DateOnly)]; ok && departure.ClosedToDeparture {
+		violations = append(violations, fmt.Sprintf("closed to departure on %s", departureDate.Format(time.DateOnly)))
+	}
+
+	for d := checkIn; !d.After(checkOut); d = d.AddDate(0, 0, 1) {
+		if r, ok := byDate[d.Format(time.DateOnly)]; ok && r.StopSell {
+			violations = append(violations, fmt.Sprintf("sales stopped on %s", d.Format(time.DateOnly)))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,120 @@
+package reservation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (s *ReservationService) JoinWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	var body JoinWaitlistBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	entry, err := s.joinWaitlist(r.Context(), usr.GuestId, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"waitlist_entry": entry})
+}
+
+func (s *ReservationService) ListWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	entries, err := s.listWaitlistEntries(r.Context(), usr.GuestId)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to list waitlist entries")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"waitlist_entries": entries})
+}
+
+func (s *ReservationService) GetWaitlistEntryHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "entryId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid waitlist entry ID")
+		return
+	}
+
+	entry, err := s.getWaitlistEntry(r.Context(), usr.GuestId, entryID)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"waitlist_entry": entry})
+}
+
+func (s *ReservationService) ConfirmWaitlistOfferHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "entryId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid waitlist entry ID")
+		return
+	}
+
+	reservationID, err := s.confirmWaitlistOffer(r.Context(), usr.GuestId, entryID)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"message": "Reservation confirmed successfully", "reservation_id": reservationID})
+}
+
+func (s *ReservationService) LeaveWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	usr, ok := r.Context().Value(auth.UsrCtxKey).(auth.UserContext)
+	if !ok {
+		shared.WriteError(w, http.StatusUnauthorized, "Missing credentials context")
+		return
+	}
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "entryId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid waitlist entry ID")
+		return
+	}
+
+	if err := s.leaveWaitlist(r.Context(), usr.GuestId, entryID); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
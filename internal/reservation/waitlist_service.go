@@ -0,0 +1,337 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/metrics"
+	"github.com/google/uuid"
+)
+
+type WaitlistState string
+
+const (
+	WaitlistStatusWaiting   WaitlistState = "waiting"
+	WaitlistStatusOffered   WaitlistState = "offered"
+	WaitlistStatusConfirmed WaitlistState = "confirmed"
+	WaitlistStatusExpired   WaitlistState = "expired"
+	WaitlistStatusCancelled WaitlistState = "cancelled"
+)
+
+const (
+	// waitlistHoldTTL is how long a waitlisted guest has to confirm an offered hold.
+	waitlistHoldTTL = 30 * time.Minute
+	// waitlistSweepInterval is how often expired holds are released and freed capacity is offered.
+	waitlistSweepInterval = time.Minute
+)
+
+type JoinWaitlistBody struct {
+	StartDate  time.Time `json:"startDate"`
+	EndDate    time.Time `json:"endDate"`
+	HotelID    uuid.UUID `json:"hotelId" validate:"uuid4"`
+	RoomTypeID uuid.UUID `json:"roomTypeId" validate:"uuid4"`
+}
+
+func (s *ReservationService) joinWaitlist(ctx context.Context, guestID uuid.UUID, body JoinWaitlistBody) (database.BookingWaitlistEntry, error) {
+	if body.EndDate.Before(body.StartDate) {
+		return database.BookingWaitlistEntry{}, ErrInvalidStayDates
+	}
+
+	_, err := s.queries.GetRoomTypeByIdAndHotelId(ctx, database.GetRoomTypeByIdAndHotelIdParams{
+		ID:      body.RoomTypeID,
+		HotelID: body.HotelID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingWaitlistEntry{}, ErrRoomTypeNotFound
+	}
+	if err != nil {
+		return database.BookingWaitlistEntry{}, fmt.Errorf("failed to get room type %q: %w", body.RoomTypeID, err)
+	}
+
+	entry, err := s.queries.InsertWaitlistEntry(ctx, database.InsertWaitlistEntryParams{
+		ID:         uuid.New(),
+		HotelID:    body.HotelID,
+		RoomTypeID: body.RoomTypeID,
+		GuestID:    guestID,
+		StartDate:  body.StartDate,
+		EndDate:    body.EndDate,
+		Status:     string(WaitlistStatusWaiting),
+	})
+	if err != nil {
+		return database.BookingWaitlistEntry{}, fmt.Errorf("failed to join waitlist: %w", err)
+	}
+
+	// Capacity may have been released between the failed reservation and joining the waitlist.
+	s.offerReleasedInventory(ctx, body.HotelID, body.RoomTypeID)
+
+	return s.getWaitlistEntry(ctx, guestID, entry.ID)
+}
+
+func (s *ReservationService) listWaitlistEntries(ctx context.Context, guestID uuid.UUID) ([]database.BookingWaitlistEntry, error) {
+	return s.queries.ListGuestWaitlistEntries(ctx, guestID)
+}
+
+func (s *ReservationService) getWaitlistEntry(ctx context.Context, guestID, entryID uuid.UUID) (database.BookingWaitlistEntry, error) {
+	entry, err := s.queries.GetWaitlistEntryById(ctx, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingWaitlistEntry{}, ErrWaitlistEntryNotFound
+	}
+	if err != nil {
+		return database.BookingWaitlistEntry{}, fmt.Errorf("failed to get waitlist entry %q: %w", entryID, err)
+	}
+
+	if entry.GuestID != guestID {
+		return database.BookingWaitlistEntry{}, ErrWaitlistEntryNotFound
+	}
+
+	return entry, nil
+}
+
+// confirmWaitlistOffer turns an offered hold into a regular pending reservation.
+func (s *ReservationService) confirmWaitlistOffer(ctx context.Context, guestID, entryID uuid.UUID) (uuid.UUID, error) {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to start confirm offer transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	entry, err := s.lockGuestWaitlistEntry(ctx, qtx, guestID, entryID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if WaitlistState(entry.Status) != WaitlistStatusOffered || !entry.ReservationID.Valid || !entry.OfferExpiresAt.Time.After(time.Now()) {
+		return uuid.Nil, ErrWaitlistOfferNotActive
+	}
+
+	reservation, err := qtx.GetReservationByIdForUpdate(ctx, entry.ReservationID.UUID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get held reservation %q: %w", entry.ReservationID.UUID, err)
+	}
+
+	// The guest may have cancelled the hold itself, its inventory is already released.
+	if ReservationState(reservation.Status) != ReservationStatusHeld {
+		return uuid.Nil, ErrWaitlistOfferNotActive
+	}
+
+	if err := qtx.UpdateReservationStatus(ctx, database.UpdateReservationStatusParams{
+		Status: string(ReservationStatusPending),
+		ID:     reservation.ID,
+	}); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to confirm held reservation %q: %w", reservation.ID, err)
+	}
+
+	reservation.Status = string(ReservationStatusPending)
+	if err := events.RecordReservation(ctx, qtx, events.ReservationConfirmed, reservation); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := qtx.UpdateWaitlistEntryStatus(ctx, database.UpdateWaitlistEntryStatusParams{
+		Status: string(WaitlistStatusConfirmed),
+		ID:     entry.ID,
+	}); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to confirm waitlist entry %q: %w", entry.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+	metrics.ReservationsCreated.Inc()
+	return entry.ReservationID.UUID, nil
+}
+
+// leaveWaitlist removes the guest from the queue. A pending offer is released to the next guest in line
+// once the cancellation of its hold is relayed.
+func (s *ReservationService) leaveWaitlist(ctx context.Context, guestID, entryID uuid.UUID) error {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start leave waitlist transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	entry, err := s.lockGuestWaitlistEntry(ctx, qtx, guestID, entryID)
+	if err != nil {
+		return err
+	}
+
+	switch WaitlistState(entry.Status) {
+	case WaitlistStatusWaiting:
+		if err := qtx.UpdateWaitlistEntryStatus(ctx, database.UpdateWaitlistEntryStatusParams{
+			Status: string(WaitlistStatusCancelled),
+			ID:     entry.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to cancel waitlist entry %q: %w", entry.ID, err)
+		}
+		return tx.Commit()
+	case WaitlistStatusOffered:
+		if err := s.releaseWaitlistHold(ctx, qtx, entry, WaitlistStatusCancelled); err != nil {
+			return err
+		}
+		return tx.Commit()
+	default:
+		return ErrWaitlistEntryNotFound
+	}
+}
+
+func (s *ReservationService) lockGuestWaitlistEntry(ctx context.Context, qtx *database.Queries, guestID, entryID uuid.UUID) (database.BookingWaitlistEntry, error) {
+	entry, err := qtx.GetWaitlistEntryForUpdate(ctx, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.BookingWaitlistEntry{}, ErrWaitlistEntryNotFound
+	}
+	if err != nil {
+		return database.BookingWaitlistEntry{}, fmt.Errorf("failed to get waitlist entry %q: %w", entryID, err)
+	}
+
+	if entry.GuestID != guestID {
+		return database.BookingWaitlistEntry{}, ErrWaitlistEntryNotFound
+	}
+
+	return entry, nil
+}
+
+// releaseWaitlistHold cancels the held reservation of an offered entry and frees its inventory.
+func (s *ReservationService) releaseWaitlistHold(ctx context.Context, qtx *database.Queries, entry database.BookingWaitlistEntry, status WaitlistState) error {
+	if entry.ReservationID.Valid {
+		reservation, err := qtx.GetReservationByIdForUpdate(ctx, entry.ReservationID.UUID)
+		if err != nil {
+			return fmt.Errorf("failed to get held reservation %q: %w", entry.ReservationID.UUID, err)
+		}
+
+		if ReservationState(reservation.Status) == ReservationStatusHeld {
+			if err := qtx.UpdateReservationStatus(ctx, database.UpdateReservationStatusParams{
+				Status: string(ReservationStatusCancelled),
+				ID:     reservation.ID,
+			}); err != nil {
+				return fmt.Errorf("failed to cancel held reservation %q: %w", reservation.ID, err)
+			}
+
+			if err := s.releaseInventory(ctx, qtx, reservation); err != nil {
+				return err
+			}
+
+			reservation.Status = string(ReservationStatusCancelled)
+			if err := events.RecordReservationCancelled(ctx, qtx, reservation, string(ReservationStatusHeld)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := qtx.UpdateWaitlistEntryStatus(ctx, database.UpdateWaitlistEntryStatusParams{
+		Status: string(status),
+		ID:     entry.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to update waitlist entry %q: %w", entry.ID, err)
+	}
+
+	return nil
+}
+
+// offerReleasedInventory walks the queue of a room type in join order and offers a hold to every
+// waiting guest whose stay fits into the current capacity. Failures are logged: the periodic sweep retries them.
+func (s *ReservationService) offerReleasedInventory(ctx context.Context, hotelID, roomTypeID uuid.UUID) {
+	entries, err := s.queries.GetWaitingEntriesForRoomType(ctx, database.GetWaitingEntriesForRoomTypeParams{
+		HotelID:    hotelID,
+		RoomTypeID: roomTypeID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get waiting entries", "room_type_id", roomTypeID, "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		offered, err := s.offerHold(ctx, entry.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to offer waitlist hold", "entry_id", entry.ID, "error", err)
+			continue
+		}
+		if offered {
+			slog.InfoContext(ctx, "Offered waitlist hold", "entry_id", entry.ID)
+		}
+	}
+}
+
+// offerHold reserves the stay of a waiting entry as a held reservation. It reports false when the stay
+// doesn't fit yet or another worker is handling the entry.
+func (s *ReservationService) offerHold(ctx context.Context, entryID uuid.UUID) (bool, error) {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to start offer hold transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	entry, err := qtx.LockWaitingEntry(ctx, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock waitlist entry %q: %w", entryID, err)
+	}
+
+	reservationID := uuid.New()
+	err = s.reserveInventory(ctx, qtx, database.InsertReservationParams{
+		ID:         reservationID,
+		HotelID:    entry.HotelID,
+		RoomTypeID: entry.RoomTypeID,
+		StartDate:  entry.StartDate,
+		EndDate:    entry.EndDate,
+		Status:     string(ReservationStatusHeld),
+		GuestID:    entry.GuestID,
+	})
+	if errors.Is(err, ErrInventoryCapacityReached) || errors.Is(err, ErrInventoryNotFound) ||
+		errors.Is(err, ErrStayRestricted) || errors.Is(err, ErrOptimisticLockMismatch) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := qtx.MarkWaitlistEntryOffered(ctx, database.MarkWaitlistEntryOfferedParams{
+		ReservationID:  uuid.NullUUID{UUID: reservationID, Valid: true},
+		OfferExpiresAt: sql.NullTime{Time: time.Now().UTC().Add(waitlistHoldTTL), Valid: true},
+		ID:             entry.ID,
+	}); err != nil {
+		return false, fmt.Errorf("failed to mark waitlist entry %q offered: %w", entry.ID, err)
+	}
+
+	// The hold is counted in metrics.ReservationsCreated once the guest confirms it.
+	return true, tx.Commit()
+}
+
+// expireWaitlistOffer releases an unconfirmed hold so the next guest in line can get it.
+func (s *ReservationService) expireWaitlistOffer(ctx context.Context, entryID uuid.UUID) (bool, error) {
+	tx, err := s.db.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to start expire offer transaction %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.queries.WithTx(tx)
+
+	entry, err := qtx.GetWaitlistEntryForUpdate(ctx, entryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get waitlist entry %q: %w", entryID, err)
+	}
+
+	// The guest may have confirmed while the sweep was running.
+	if WaitlistState(entry.Status) != WaitlistStatusOffered || entry.OfferExpiresAt.Time.After(time.Now()) {
+		return false, nil
+	}
+
+	if err := s.releaseWaitlistHold(ctx, qtx, entry, WaitlistStatusExpired); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,77 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+)
+
+// RunWaitlistWorker periodically expires unconfirmed holds and offers freed capacity to waiting guests.
+// Sweeping every room type with a queue also picks up capacity added by inventory updates. It blocks until ctx is done.
+func (s *ReservationService) RunWaitlistWorker(ctx context.Context) {
+	ticker := time.NewTicker(waitlistSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweepWaitlist(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandleEvent offers the rooms a cancellation or a change of dates freed to the waitlist of their room type, so
+// neither waits for the queue to be walked. It is meant to be subscribed to the event bus.
+func (s *ReservationService) HandleEvent(ctx context.Context, e events.Event) error {
+	if e.Type != events.ReservationCancelled && e.Type != events.ReservationModified {
+		return nil
+	}
+
+	var payload events.ReservationPayload
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode %s event %q: %w", e.Type, e.ID, err)
+	}
+
+	s.offerReleasedInventory(ctx, payload.HotelID, payload.RoomTypeID)
+	return nil
+}
+
+func (s *ReservationService) sweepWaitlist(ctx context.Context) {
+	expired, err := s.queries.GetExpiredWaitlistOffers(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get expired waitlist offers", "error", err)
+		return
+	}
+
+	for _, entry := range expired {
+		released, err := s.expireWaitlistOffer(ctx, entry.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to expire waitlist offer", "entry_id", entry.ID, "error", err)
+			continue
+		}
+		if released {
+			slog.InfoContext(ctx, "Waitlist offer expired", "entry_id", entry.ID)
+		}
+	}
+
+	if _, err := s.queries.ExpireStaleWaitlistEntries(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to expire stale waitlist entries", "error", err)
+	}
+
+	roomTypes, err := s.queries.GetRoomTypesWithWaitingEntries(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get room types with waiting entries", "error", err)
+		return
+	}
+
+	for _, rt := range roomTypes {
+		s.offerReleasedInventory(ctx, rt.HotelID, rt.RoomTypeID)
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+)
+
+// startEventRelay subscribes the in-process consumers and starts the outbox relay. Handlers are
+// subscribed before the relay runs, so no event is published while nobody listens. Their names record
+// which of them handled an event, renaming one makes it see the events still being retried again. With
+// the notify wake-up, outbox commits wake the relay through LISTEN/NOTIFY instead of waiting for its next poll.
+func (s *Server) startEventRelay(handlers map[string]events.Handler) *events.Relay {
+	bus := events.NewInMemoryBus()
+	for _, name := range slices.Sorted(maps.Keys(handlers)) {
+		bus.Subscribe(name, handlers[name])
+	}
+
+	var wake <-chan struct{}
+	if s.cfg.Events.Wake == "notify" {
+		listener := events.NewListener(s.db.Pool())
+		go listener.Run(s.ctx)
+		wake = listener.Wake()
+	}
+
+	relay := events.NewRelay(s.queries, s.db, bus)
+	go relay.Run(s.ctx, wake)
+	return relay
+}
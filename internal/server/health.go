@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/migrate"
+)
+
+// registerHealthChecks adds the dependencies /readyz checks. The database and a schema missing migrations
+// decide whether the instance serves. A schema ahead of the build, the outbox and the inventory cron are only
+// reported: migrations run before the rollout, and failing the old pods on them would leave nothing serving
+// until the new ones are ready.
+func (s *Server) registerHealthChecks(relay *events.Relay) {
+	s.health.Require("database", s.db.GetDB().PingContext)
+	s.health.Require("migrations", func(ctx context.Context) error {
+		if err := s.migrator.Check(ctx); !errors.Is(err, migrate.ErrSchemaAhead) {
+			return err
+		}
+		return nil
+	})
+	s.health.Monitor("newer_migrations", func(ctx context.Context) error {
+		if err := s.migrator.Check(ctx); errors.Is(err, migrate.ErrSchemaAhead) {
+			return err
+		}
+		return nil
+	})
+
+	s.health.Monitor("outbox", func(ctx context.Context) error {
+		pending, oldest, err := relay.Backlog(ctx)
+		if err != nil {
+			return err
+		}
+		if oldest > s.cfg.Health.OutboxMaxAge {
+			return fmt.Errorf("%d events pending, the oldest for %s", pending, oldest.Round(time.Second))
+		}
+		return nil
+	})
+
+	s.health.Monitor("inventory_cron", func(ctx context.Context) error {
+		run, err := s.queries.GetLastCompletedInventoryCronRun(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("no completed run")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get last completed run: %w", err)
+		}
+		if age := time.Since(run.FinishedAt.Time); age > s.cfg.Health.InventoryMaxAge {
+			return fmt.Errorf("last completed run finished %s ago", age.Round(time.Minute))
+		}
+		return nil
+	})
+}
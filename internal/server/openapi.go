@@ -0,0 +1,447 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/calendar"
+	"github.com/AlexKhomenko00/hotel-system/internal/channel"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
+	"github.com/AlexKhomenko00/hotel-system/internal/inventory"
+	"github.com/AlexKhomenko00/hotel-system/internal/jobs"
+	"github.com/AlexKhomenko00/hotel-system/internal/openapi"
+	"github.com/AlexKhomenko00/hotel-system/internal/overbooking"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/AlexKhomenko00/hotel-system/internal/webhook"
+	"github.com/google/uuid"
+)
+
+const apiVersion = "1.0.0"
+
+var (
+	fromQuery = openapi.Param{Name: "from", Required: true, Schema: openapi.Date()}
+	toQuery   = openapi.Param{Name: "to", Required: true, Schema: openapi.Date()}
+	// Comma separated weekdays, 0 is Sunday.
+	weekdaysQuery = openapi.Param{Name: "weekdays", Schema: openapi.Pattern(`^[0-6](,[0-6])*$`)}
+	limitQuery    = openapi.Param{Name: "limit", Schema: openapi.Integer(1)}
+	noContent     = openapi.Response{Status: http.StatusNoContent}
+)
+
+// apiDocument describes every route of v1, RegisterRoutes refuses to start when they differ.
+// Bodies and responses are the handlers' own types, so renaming a json field changes the document as well.
+func apiDocument() *openapi.Document {
+	doc := openapi.New("Hotel System API", apiVersion)
+	doc.Servers = []openapi.Server{{URL: "/v1"}}
+
+	addAuthOperations(doc)
+	addHotelOperations(doc)
+	addReservationOperations(doc)
+	addHotelAdminOperations(doc)
+	addAdminOperations(doc)
+	addFeedOperations(doc)
+
+	return doc
+}
+
+func addAuthOperations(doc *openapi.Document) {
+	doc.Add(openapi.Operation{
+		ID: "register", Method: http.MethodPost, Path: "/register", Tag: "auth", Public: true,
+		Summary:   "Register a guest account",
+		Body:      auth.RegisterBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: shared.Envelope{"user_id": uuid.UUID{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "login", Method: http.MethodPost, Path: "/login", Tag: "auth", Public: true,
+		Summary:   "Exchange credentials for an access token",
+		Body:      auth.AuthBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: auth.LoginResponse{}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getMe", Method: http.MethodGet, Path: "/me", Tag: "auth",
+		Summary: "Current user",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{
+			"user_id":  uuid.UUID{},
+			"email":    "",
+			"guest_id": uuid.UUID{},
+		}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "verifyToken", Method: http.MethodGet, Path: "/verify", Tag: "auth",
+		Summary:   "Check the access token",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"message": ""}}},
+	})
+}
+
+func addHotelOperations(doc *openapi.Document) {
+	hotelResponse := shared.Envelope{"hotel": database.BookingHotel{}}
+	roomTypeResponse := shared.Envelope{"roomType": database.BookingRoomType{}}
+
+	doc.Add(openapi.Operation{
+		ID: "createHotel", Method: http.MethodPost, Path: "/hotel/", Tag: "hotels",
+		Summary:   "Create a hotel",
+		Body:      hotel.CreateHotelBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: hotelResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getHotel", Method: http.MethodGet, Path: "/hotel/{id}", Tag: "hotels",
+		Summary:   "Get a hotel",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: hotelResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "updateHotel", Method: http.MethodPut, Path: "/hotel/{id}", Tag: "hotels",
+		Summary:   "Update a hotel",
+		Body:      hotel.UpdateHotelBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: hotelResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "deleteHotel", Method: http.MethodDelete, Path: "/hotel/{id}", Tag: "hotels",
+		Summary:   "Deactivate a hotel",
+		Responses: []openapi.Response{noContent},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "addRoomType", Method: http.MethodPost, Path: "/hotel/{hotelId}/rooms/", Tag: "room-types",
+		Summary:   "Add a room type",
+		Body:      hotel.AddRoomType{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: roomTypeResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getRoomType", Method: http.MethodGet, Path: "/hotel/{hotelId}/rooms/{id}", Tag: "room-types",
+		Summary:   "Get a room type",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: roomTypeResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "updateRoomType", Method: http.MethodPut, Path: "/hotel/{hotelId}/rooms/{id}", Tag: "room-types",
+		Summary:   "Update a room type",
+		Body:      hotel.UpdateRoomType{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: roomTypeResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "deleteRoomType", Method: http.MethodDelete, Path: "/hotel/{hotelId}/rooms/{id}", Tag: "room-types",
+		Summary:   "Delete a room type",
+		Responses: []openapi.Response{noContent},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "getRoomTypeRestrictions", Method: http.MethodGet, Path: "/hotel/{hotelId}/rooms/{id}/restrictions/", Tag: "restrictions",
+		Summary:   "List stay restrictions of a room type",
+		Query:     []openapi.Param{fromQuery, toQuery, weekdaysQuery},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"restrictions": []database.BookingRoomTypeRestriction{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "setRoomTypeRestrictions", Method: http.MethodPut, Path: "/hotel/{hotelId}/rooms/{id}/restrictions/", Tag: "restrictions",
+		Summary:   "Set stay restrictions of a room type for a date range",
+		Body:      hotel.SetRestrictionsBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"updated_dates": int64(0)}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "clearRoomTypeRestrictions", Method: http.MethodDelete, Path: "/hotel/{hotelId}/rooms/{id}/restrictions/", Tag: "restrictions",
+		Summary:   "Clear stay restrictions of a room type for a date range",
+		Query:     []openapi.Param{fromQuery, toQuery, weekdaysQuery},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"cleared_dates": int64(0)}}},
+	})
+}
+
+func addReservationOperations(doc *openapi.Document) {
+	hotelQuery := openapi.Param{Name: "hotelId", Required: true, Schema: openapi.UUID()}
+	waitlistEntryResponse := shared.Envelope{"waitlist_entry": database.BookingWaitlistEntry{}}
+	confirmation := func(status int) openapi.Response {
+		return openapi.Response{Status: status, Body: shared.Envelope{"message": "", "reservation_id": uuid.UUID{}}}
+	}
+
+	doc.Add(openapi.Operation{
+		ID: "generateReservationId", Method: http.MethodGet, Path: "/reservation/generate-id", Tag: "reservations",
+		Summary:   "Generate an ID to make a reservation with, retries with the same ID are idempotent",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"reservation_id": ""}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "makeReservation", Method: http.MethodPost, Path: "/reservation/", Tag: "reservations",
+		Summary:   "Make a reservation",
+		Body:      reservation.MakeReservationBody{},
+		Responses: []openapi.Response{confirmation(http.StatusCreated)},
+	})
+	doc.Add(openapi.Operation{
+		ID: "cancelReservation", Method: http.MethodPost, Path: "/reservation/{reservationId}/cancel", Tag: "reservations",
+		Summary:   "Cancel a reservation",
+		Responses: []openapi.Response{confirmation(http.StatusOK)},
+	})
+	doc.Add(openapi.Operation{
+		ID: "modifyReservation", Method: http.MethodPost, Path: "/reservation/{reservationId}/modify", Tag: "reservations",
+		Summary:   "Move a reservation to new dates",
+		Body:      reservation.ModifyReservationBody{},
+		Responses: []openapi.Response{confirmation(http.StatusOK)},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getRoomAvailability", Method: http.MethodGet, Path: "/reservation/availability", Tag: "reservations",
+		Summary: "Room types available for a stay",
+		Query: []openapi.Param{
+			hotelQuery,
+			{Name: "checkIn", Required: true, Schema: openapi.Date()},
+			{Name: "checkOut", Required: true, Schema: openapi.Date()},
+		},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"availability": []database.GetRoomAvailabilityByDatesRow{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getAvailabilityCalendar", Method: http.MethodGet, Path: "/reservation/availability/calendar", Tag: "reservations",
+		Summary: "Availability of a hotel's room types for a month, with a summary of the stay when given",
+		Query: []openapi.Param{
+			hotelQuery,
+			{Name: "month", Description: "YYYY-MM, defaults to the check-in month", Schema: openapi.Pattern(`^[0-9]{4}-[0-9]{2}$`)},
+			{Name: "checkIn", Schema: openapi.Date()},
+			{Name: "checkOut", Schema: openapi.Date()},
+		},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"calendar": reservation.AvailabilityCalendar{}}}},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "listWaitlistEntries", Method: http.MethodGet, Path: "/reservation/waitlist/", Tag: "waitlist",
+		Summary:   "List the guest's waitlist entries",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"waitlist_entries": []database.BookingWaitlistEntry{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "joinWaitlist", Method: http.MethodPost, Path: "/reservation/waitlist/", Tag: "waitlist",
+		Summary:   "Join the waitlist for a sold out stay",
+		Body:      reservation.JoinWaitlistBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: waitlistEntryResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getWaitlistEntry", Method: http.MethodGet, Path: "/reservation/waitlist/{entryId}", Tag: "waitlist",
+		Summary:   "Get a waitlist entry",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: waitlistEntryResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "confirmWaitlistOffer", Method: http.MethodPost, Path: "/reservation/waitlist/{entryId}/confirm", Tag: "waitlist",
+		Summary:   "Turn a waitlist offer into a reservation",
+		Responses: []openapi.Response{confirmation(http.StatusOK)},
+	})
+	doc.Add(openapi.Operation{
+		ID: "leaveWaitlist", Method: http.MethodDelete, Path: "/reservation/waitlist/{entryId}", Tag: "waitlist",
+		Summary:   "Leave the waitlist",
+		Responses: []openapi.Response{noContent},
+	})
+}
+
+// addHotelAdminOperations documents the routes under /hotel/{hotelId} that need the hotel admin role.
+func addHotelAdminOperations(doc *openapi.Document) {
+	policyResponse := shared.Envelope{"policy": database.BookingOverbookingPolicy{}}
+	subscriptionResponse := shared.Envelope{"subscription": webhook.Subscription{}}
+	channelResponse := shared.Envelope{"channel": channel.Channel{}}
+
+	doc.Add(openapi.Operation{
+		ID: "listOverbookingPolicies", Method: http.MethodGet, Path: "/hotel/{hotelId}/overbooking/policies", Tag: "overbooking",
+		Summary:   "List overbooking policies of a hotel",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"policies": []database.BookingOverbookingPolicy{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "createOverbookingPolicy", Method: http.MethodPost, Path: "/hotel/{hotelId}/overbooking/policies", Tag: "overbooking",
+		Summary:   "Create an overbooking policy",
+		Body:      overbooking.PolicyBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: policyResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "updateOverbookingPolicy", Method: http.MethodPut, Path: "/hotel/{hotelId}/overbooking/policies/{policyId}", Tag: "overbooking",
+		Summary:   "Update an overbooking policy",
+		Body:      overbooking.PolicyBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: policyResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "deleteOverbookingPolicy", Method: http.MethodDelete, Path: "/hotel/{hotelId}/overbooking/policies/{policyId}", Tag: "overbooking",
+		Summary:   "Delete an overbooking policy",
+		Responses: []openapi.Response{noContent},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getOverbookedNightsReport", Method: http.MethodGet, Path: "/hotel/{hotelId}/overbooking/report", Tag: "overbooking",
+		Summary:   "Nights booked beyond physical inventory",
+		Query:     []openapi.Param{fromQuery, toQuery},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"report": overbooking.OverbookedNightsReport{}}}},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "listWebhookSubscriptions", Method: http.MethodGet, Path: "/hotel/{hotelId}/webhooks", Tag: "webhooks",
+		Summary:   "List webhook subscriptions of a hotel",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"subscriptions": []webhook.Subscription{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "createWebhookSubscription", Method: http.MethodPost, Path: "/hotel/{hotelId}/webhooks", Tag: "webhooks",
+		Summary:   "Subscribe to events, the signing secret is only returned here",
+		Body:      webhook.SubscriptionBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: shared.Envelope{"subscription": webhook.CreatedSubscription{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "updateWebhookSubscription", Method: http.MethodPut, Path: "/hotel/{hotelId}/webhooks/{subscriptionId}", Tag: "webhooks",
+		Summary:   "Update a webhook subscription",
+		Body:      webhook.SubscriptionBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: subscriptionResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "deleteWebhookSubscription", Method: http.MethodDelete, Path: "/hotel/{hotelId}/webhooks/{subscriptionId}", Tag: "webhooks",
+		Summary:   "Delete a webhook subscription",
+		Responses: []openapi.Response{noContent},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "listCalendarFeeds", Method: http.MethodGet, Path: "/hotel/{hotelId}/calendar-feeds", Tag: "calendar",
+		Summary:   "List iCalendar feeds of a hotel",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"feeds": []calendar.Feed{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "createCalendarFeed", Method: http.MethodPost, Path: "/hotel/{hotelId}/calendar-feeds", Tag: "calendar",
+		Summary:   "Create an iCalendar feed",
+		Body:      calendar.CreateFeedBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: shared.Envelope{"feed": calendar.Feed{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "issueGuestCalendarFeed", Method: http.MethodPost, Path: "/calendar/guest-feed", Tag: "calendar",
+		Summary:   "Issue the token URL of the guest's iCalendar feed, revoking the previous one",
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: shared.Envelope{"feed": calendar.GuestFeed{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "revokeGuestCalendarFeed", Method: http.MethodDelete, Path: "/calendar/guest-feed", Tag: "calendar",
+		Summary:   "Revoke the token URL of the guest's iCalendar feed",
+		Responses: []openapi.Response{noContent},
+	})
+	doc.Add(openapi.Operation{
+		ID: "deleteCalendarFeed", Method: http.MethodDelete, Path: "/hotel/{hotelId}/calendar-feeds/{feedId}", Tag: "calendar",
+		Summary:   "Delete an iCalendar feed",
+		Responses: []openapi.Response{noContent},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "listChannels", Method: http.MethodGet, Path: "/hotel/{hotelId}/channels", Tag: "channels",
+		Summary:   "List distribution channels of a hotel",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"channels": []channel.Channel{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "createChannel", Method: http.MethodPost, Path: "/hotel/{hotelId}/channels", Tag: "channels",
+		Summary:   "Connect a distribution channel",
+		Body:      channel.CreateChannelBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: channelResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "deleteChannel", Method: http.MethodDelete, Path: "/hotel/{hotelId}/channels/{channelId}", Tag: "channels",
+		Summary:   "Disconnect a distribution channel",
+		Responses: []openapi.Response{noContent},
+	})
+	doc.Add(openapi.Operation{
+		ID: "syncChannel", Method: http.MethodPost, Path: "/hotel/{hotelId}/channels/{channelId}/sync", Tag: "channels",
+		Summary:   "Push availability and rates to the channel now",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: channelResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "listChannelMappings", Method: http.MethodGet, Path: "/hotel/{hotelId}/channels/{channelId}/mappings", Tag: "channels",
+		Summary:   "List room type mappings of a channel",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"mappings": []database.BookingChannelRoomMapping{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "setChannelMapping", Method: http.MethodPut, Path: "/hotel/{hotelId}/channels/{channelId}/mappings/{roomTypeId}", Tag: "channels",
+		Summary:   "Map a room type to the channel's room and rate plan",
+		Body:      channel.RoomMappingBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"mapping": database.BookingChannelRoomMapping{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "deleteChannelMapping", Method: http.MethodDelete, Path: "/hotel/{hotelId}/channels/{channelId}/mappings/{roomTypeId}", Tag: "channels",
+		Summary:   "Remove a room type mapping",
+		Responses: []openapi.Response{noContent},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "previewBulkInventoryUpdate", Method: http.MethodPost, Path: "/hotel/{hotelId}/inventory/bulk/preview", Tag: "inventory",
+		Summary:   "Preview a bulk inventory update without applying it",
+		Body:      inventory.BulkUpdateBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"result": inventory.BulkUpdateResult{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "bulkInventoryUpdate", Method: http.MethodPost, Path: "/hotel/{hotelId}/inventory/bulk", Tag: "inventory",
+		Summary:   "Update inventory of a room type for a date range",
+		Body:      inventory.BulkUpdateBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"result": inventory.BulkUpdateResult{}}}},
+	})
+}
+
+func addAdminOperations(doc *openapi.Document) {
+	defaultPolicyResponse := shared.Envelope{"policy": overbooking.DefaultPolicy{}}
+	jobResponse := shared.Envelope{"job": jobs.Job{}}
+
+	doc.Add(openapi.Operation{
+		ID: "getDefaultOverbookingPolicy", Method: http.MethodGet, Path: "/admin/overbooking/default", Tag: "admin",
+		Summary:   "Get the overbooking factor used when a hotel has no policy",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: defaultPolicyResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "setDefaultOverbookingPolicy", Method: http.MethodPut, Path: "/admin/overbooking/default", Tag: "admin",
+		Summary:   "Set the overbooking factor used when a hotel has no policy",
+		Body:      overbooking.DefaultPolicyBody{},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: defaultPolicyResponse}},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "listWebhookDeliveries", Method: http.MethodGet, Path: "/admin/webhooks/deliveries", Tag: "admin",
+		Summary: "List webhook deliveries, newest first",
+		Query: []openapi.Param{
+			{Name: "status", Schema: openapi.Enum(webhook.DeliveryStatusPending, webhook.DeliveryStatusDelivered, webhook.DeliveryStatusDead)},
+			{Name: "hotelId", Schema: openapi.UUID()},
+			limitQuery,
+		},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"deliveries": []database.ListWebhookDeliveriesRow{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getWebhookDeliveryAttempts", Method: http.MethodGet, Path: "/admin/webhooks/deliveries/{deliveryId}/attempts", Tag: "admin",
+		Summary:   "List attempts of a webhook delivery",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"attempts": []database.BookingWebhookDeliveryAttempt{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "replayWebhookDelivery", Method: http.MethodPost, Path: "/admin/webhooks/deliveries/{deliveryId}/replay", Tag: "admin",
+		Summary:   "Queue a webhook delivery again",
+		Responses: []openapi.Response{{Status: http.StatusAccepted, Body: shared.Envelope{"message": "", "delivery_id": uuid.UUID{}}}},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "listReservationNotifications", Method: http.MethodGet, Path: "/admin/notifications/reservations/{reservationId}", Tag: "admin",
+		Summary:   "List guest notifications of a reservation",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"notifications": []database.BookingNotification{}}}},
+	})
+
+	doc.Add(openapi.Operation{
+		ID: "listJobs", Method: http.MethodGet, Path: "/admin/jobs/", Tag: "admin",
+		Summary:   "List background job runs, newest first",
+		Query:     []openapi.Param{{Name: "hotelId", Schema: openapi.UUID()}, limitQuery},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"jobs": []jobs.Job{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getJob", Method: http.MethodGet, Path: "/admin/jobs/{jobId}", Tag: "admin",
+		Summary:   "Get a background job run",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: jobResponse}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "triggerHotelInventoryJob", Method: http.MethodPost, Path: "/admin/jobs/inventory/hotels/{hotelId}", Tag: "admin",
+		Summary:   "Populate inventory of a hotel in the background",
+		Responses: []openapi.Response{{Status: http.StatusAccepted, Body: jobResponse}},
+	})
+}
+
+// addFeedOperations documents the routes serving other systems rather than JSON clients.
+func addFeedOperations(doc *openapi.Document) {
+	doc.Add(openapi.Operation{
+		ID: "getHotelCalendarFeed", Method: http.MethodGet, Path: "/calendar/feeds/{token}.ics", Tag: "calendar", Public: true,
+		Summary:   "iCalendar feed of a hotel's reservations, the token authorizes it",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: "", ContentType: "text/calendar"}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getGuestCalendarFeed", Method: http.MethodGet, Path: "/calendar/reservations.ics", Tag: "calendar",
+		Summary:   "iCalendar feed of the guest's reservations",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: "", ContentType: "text/calendar"}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getGuestTokenCalendarFeed", Method: http.MethodGet, Path: "/calendar/guest-feeds/{token}.ics", Tag: "calendar", Public: true,
+		Summary:   "iCalendar feed of a guest's reservations, the token authorizes it",
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: "", ContentType: "text/calendar"}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "notifyChannel", Method: http.MethodPost, Path: "/channels/{channelId}/notify", Tag: "channels", Public: true,
+		Summary:         "Reservation messages pushed by a channel, authorized by the channel's API key",
+		BodyContentType: "application/xml",
+		Responses:       []openapi.Response{{Status: http.StatusOK, Body: "", ContentType: "application/xml"}},
+	})
+}
@@ -2,50 +2,151 @@ package server
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
+	"time"
 
+	"github.com/AlexKhomenko00/hotel-system/internal/apiversion"
 	"github.com/AlexKhomenko00/hotel-system/internal/auth"
 	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
-	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
+	"github.com/AlexKhomenko00/hotel-system/internal/metrics"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
+// legacyRoutesDeprecated is when the unversioned paths were deprecated in favour of /v1.
+var legacyRoutesDeprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
 func (s *Server) RegisterRoutes() http.Handler {
-	hotelSvc := hotel.New(s.queries, s.validator)
-	authSvc := auth.New(s.queries, s.validator, s.cfg)
-	reservationSvc := reservation.New(s.queries, s.validator, s.cfg, s.db)
+	hotelSvc := s.services.hotel
+	authSvc := s.services.auth
+	overbookingSvc := s.services.overbooking
+	reservationSvc := s.services.reservation
+	webhookSvc := s.services.webhook
+	notificationSvc := s.services.notification
+	calendarSvc := s.services.calendar
+	channelSvc := s.services.channel
+	inventorySvc := s.services.inventory
+	jobSvc := s.services.jobs
+
+	doc := apiDocument()
 
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
+	r.Use(shared.RequestID)
+	r.Use(tracing.Middleware)
+	r.Use(logging.Middleware)
+	r.Use(metrics.Instrument)
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", shared.RequestIDHeader},
+		ExposedHeaders:   []string{shared.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
-	authSvc.RegisterHandlers(r)
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		shared.WriteError(w, http.StatusNotFound, "Route not found")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		shared.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	})
+
+	// api registers the routes of v1. A v2 gets its own registration function mounted at /v2 next to it, reusing
+	// the handlers that didn't change and tagging requests so shared handlers can tell the versions apart.
+	api := func(r *chi.Mux) {
+		r.Use(doc.ValidateRequests)
 
-	r.Group(func(r chi.Router) {
-		authSvc.SetupJWTAuthMiddleware(r)
+		authSvc.RegisterHandlers(r)
+		calendarSvc.RegisterPublicHandlers(r)
+		channelSvc.RegisterPublicHandlers(r)
 
-		r.Route("/hotel", func(r chi.Router) {
-			registerHotelRoutes(r, hotelSvc)
-			registerRoomTypesRoutes(r, hotelSvc)
-		})
+		r.Group(func(r chi.Router) {
+			authSvc.SetupJWTAuthMiddleware(r)
+
+			calendarSvc.RegisterGuestHandlers(r)
+
+			r.Route("/hotel", func(r chi.Router) {
+				registerHotelRoutes(r, hotelSvc)
+				registerRoomTypesRoutes(r, hotelSvc, authSvc)
+
+				r.Route("/{hotelId}/overbooking", func(r chi.Router) {
+					r.Use(authSvc.RequireHotelAdmin("hotelId"))
+					overbookingSvc.RegisterHotelHandlers(r)
+				})
+
+				r.Route("/{hotelId}/webhooks", func(r chi.Router) {
+					r.Use(authSvc.RequireHotelAdmin("hotelId"))
+					webhookSvc.RegisterHotelHandlers(r)
+				})
+
+				r.Route("/{hotelId}/calendar-feeds", func(r chi.Router) {
+					r.Use(authSvc.RequireHotelAdmin("hotelId"))
+					calendarSvc.RegisterHotelHandlers(r)
+				})
 
-		r.Route("/reservation", func(r chi.Router) {
-			reservationSvc.RegisterHandlers(r)
+				r.Route("/{hotelId}/channels", func(r chi.Router) {
+					r.Use(authSvc.RequireHotelAdmin("hotelId"))
+					channelSvc.RegisterHotelHandlers(r)
+				})
+
+				r.Route("/{hotelId}/inventory", func(r chi.Router) {
+					r.Use(authSvc.RequireHotelAdmin("hotelId"))
+					inventorySvc.RegisterHotelHandlers(r)
+				})
+			})
+
+			r.Route("/reservation", func(r chi.Router) {
+				reservationSvc.RegisterHandlers(r)
+			})
+
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(authSvc.RequireAdmin)
+				r.Route("/overbooking", overbookingSvc.RegisterAdminHandlers)
+				r.Route("/webhooks", webhookSvc.RegisterAdminHandlers)
+				r.Route("/notifications", notificationSvc.RegisterAdminHandlers)
+				r.Route("/jobs", jobSvc.RegisterAdminHandlers)
+			})
 		})
-	})
+	}
+
+	v1 := chi.NewRouter()
+	v1.Use(apiversion.Tag("v1"))
+	api(v1)
+	if err := doc.CheckRoutes(v1); err != nil {
+		log.Fatalf("Invalid API document: %v", err)
+	}
+	r.Mount("/v1", v1)
+
+	// Clients of the unversioned paths keep working until they have moved to /v1.
+	if s.cfg.Server.LegacyRoutes {
+		legacy := chi.NewRouter()
+		legacy.Use(apiversion.Deprecation{
+			Since:     legacyRoutesDeprecated,
+			Sunset:    s.cfg.Server.LegacyRoutesSunset,
+			Successor: "/v1",
+		}.Handler)
+		api(legacy)
+		r.Mount("/", legacy)
+	}
 
 	r.Get("/health", s.healthHandler)
+	r.Get("/openapi.json", doc.Handler())
+	r.Get("/metrics", metrics.Handler(collectors.NewDBStatsCollector(s.db.GetDB(), s.cfg.DB.Database)).ServeHTTP)
 
-	return r
+	// Probes are answered before the middlewares, kubelet polling them every few seconds would flood the
+	// request logs, traces and latency histograms.
+	probes := chi.NewRouter()
+	probes.Get("/livez", s.health.LiveHandler)
+	probes.Get("/readyz", s.health.ReadyHandler)
+	probes.Mount("/", r)
+
+	return probes
 }
 
 func registerHotelRoutes(r chi.Router, hotelSvc *hotel.HotelService) {
@@ -55,17 +156,32 @@ func registerHotelRoutes(r chi.Router, hotelSvc *hotel.HotelService) {
 	r.Delete("/{id}", hotelSvc.DeleteHotelHandler)
 }
 
-func registerRoomTypesRoutes(r chi.Router, hotelSvc *hotel.HotelService) {
+func registerRoomTypesRoutes(r chi.Router, hotelSvc *hotel.HotelService, authSvc *auth.AuthService) {
 	r.Route("/{hotelId}/rooms", func(r chi.Router) {
 		r.Post("/", hotelSvc.AddRoomTypeHandler)
 		r.Get("/{id}", hotelSvc.GetRoomTypeHandler)
 		r.Put("/{id}", hotelSvc.UpdateRoomTypeHandler)
 		r.Delete("/{id}", hotelSvc.DeleteRoomTypeHandler)
+
+		r.Route("/{id}/restrictions", func(r chi.Router) {
+			r.Use(authSvc.RequireHotelAdmin("hotelId"))
+			r.Get("/", hotelSvc.GetRoomTypeRestrictionsHandler)
+			r.Put("/", hotelSvc.SetRoomTypeRestrictionsHandler)
+			r.Delete("/", hotelSvc.ClearRoomTypeRestrictionsHandler)
+		})
 	})
 }
 
+// healthHandler reports the database pool, prefer /readyz for probes.
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
-	jsonResp, _ := json.Marshal(s.db.Health())
-	_, _ = w.Write(jsonResp)
+	stats := s.db.Health()
+	status := http.StatusOK
+	if stats["status"] != "up" {
+		status = http.StatusServiceUnavailable
+	}
 
+	jsonResp, _ := json.Marshal(stats)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(jsonResp)
 }
@@ -1,42 +1,61 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/config"
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/health"
+	"github.com/AlexKhomenko00/hotel-system/internal/migrate"
 	"github.com/go-playground/validator/v10"
 	_ "github.com/joho/godotenv/autoload"
 )
 
 type Server struct {
+	// ctx bounds the lifetime of background workers started alongside the HTTP server.
+	ctx       context.Context
 	port      int
 	db        database.Service
 	queries   *database.Queries
 	cfg       *config.Config
 	validator *validator.Validate
+	migrator  *migrate.Migrator
+	health    *health.Checker
+	services  services
 }
 
-func NewServer() *http.Server {
-	validator := validator.New()
-	cfg := config.GetConfig(validator)
+// NewServer returns the HTTP server and the health checker whose readiness it drains before shutting down.
+func NewServer(ctx context.Context, cfg *config.Config, validator *validator.Validate) (*http.Server, *health.Checker) {
 	dbService, err := database.Create(cfg)
 	if err != nil {
 		log.Fatal("Failed to initialize database %w", err)
 	}
 
-	port, _ := strconv.Atoi(cfg.PORT)
+	// Serving against a schema missing migrations fails in confusing ways, so refuse to start instead.
+	migrator, err := migrate.New(dbService.GetDB())
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Ensure(ctx, cfg.DB.AutoMigrate); err != nil {
+		log.Fatalf("Database schema check failed: %v", err)
+	}
+
 	NewServer := &Server{
-		port:      port,
+		ctx:       ctx,
+		port:      cfg.Server.Port,
 		db:        dbService,
 		queries:   database.New(dbService.GetDB()),
 		cfg:       cfg,
 		validator: validator,
+		migrator:  migrator,
+		health:    health.New(),
 	}
+	NewServer.services = NewServer.newServices()
+	NewServer.startWorkers()
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", NewServer.port),
@@ -46,5 +65,5 @@ func NewServer() *http.Server {
 		WriteTimeout: 30 * time.Second,
 	}
 
-	return server
+	return server, NewServer.health
 }
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/calendar"
+	"github.com/AlexKhomenko00/hotel-system/internal/channel"
+	"github.com/AlexKhomenko00/hotel-system/internal/channel/otaxml"
+	"github.com/AlexKhomenko00/hotel-system/internal/egress"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
+	"github.com/AlexKhomenko00/hotel-system/internal/inventory"
+	"github.com/AlexKhomenko00/hotel-system/internal/jobs"
+	"github.com/AlexKhomenko00/hotel-system/internal/notification"
+	"github.com/AlexKhomenko00/hotel-system/internal/overbooking"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/webhook"
+)
+
+// services are shared by the routes and the background workers.
+type services struct {
+	hotel        *hotel.HotelService
+	auth         *auth.AuthService
+	overbooking  *overbooking.OverbookingService
+	reservation  *reservation.ReservationService
+	webhook      *webhook.WebhookService
+	notification *notification.NotificationService
+	calendar     *calendar.CalendarService
+	channel      *channel.ChannelService
+	inventory    *inventory.InventoryService
+	jobs         *jobs.JobService
+}
+
+func (s *Server) newServices() services {
+	overbookingSvc := overbooking.New(s.queries, s.validator, s.cfg)
+	reservationSvc := reservation.New(s.queries, s.validator, s.cfg, s.db, overbookingSvc)
+
+	return services{
+		hotel:        hotel.New(s.queries, s.validator),
+		auth:         auth.New(s.queries, s.validator, s.cfg),
+		overbooking:  overbookingSvc,
+		reservation:  reservationSvc,
+		webhook:      webhook.New(s.queries, s.validator, s.cfg),
+		notification: notification.New(s.queries, notification.NewSender(s.cfg)),
+		calendar:     calendar.New(s.queries, s.validator),
+		channel: channel.New(s.queries, s.validator, s.cfg, s.db, reservationSvc, overbookingSvc, map[string]channel.Adapter{
+			otaxml.Name: otaxml.New(egress.NewClient(30*time.Second, s.cfg.Webhooks.AllowPrivateTargets)),
+		}),
+		inventory: inventory.New(s.queries, s.validator, s.db),
+		jobs:      jobs.New(s.queries, s.db),
+	}
+}
+
+// startWorkers runs the background workers and the outbox relay until s.ctx is done, and registers the health
+// checks watching them.
+func (s *Server) startWorkers() {
+	go s.services.reservation.RunWaitlistWorker(s.ctx)
+	go s.services.webhook.RunDeliveryWorker(s.ctx)
+	go s.services.notification.RunWorker(s.ctx)
+	go s.services.channel.RunSyncWorker(s.ctx)
+	go s.services.jobs.RunTriggeredWorker(s.ctx)
+
+	relay := s.startEventRelay(map[string]events.Handler{
+		"reservation":  s.services.reservation.HandleEvent,
+		"webhook":      s.services.webhook.HandleEvent,
+		"notification": s.services.notification.HandleEvent,
+		"channel":      s.services.channel.HandleEvent,
+	})
+	s.registerHealthChecks(relay)
+}
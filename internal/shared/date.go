@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"slices"
 	"strings"
 	"time"
 )
@@ -21,3 +22,22 @@ func (d Date) MarshalJSON() ([]byte, error) {
 	t := time.Time(d)
 	return []byte(`"` + t.Format(time.DateOnly) + `"`), nil
 }
+
+// CheckOut returns the departure date of a stay: reservations store the last night, guests check out the
+// morning after.
+func CheckOut(endDate time.Time) time.Time {
+	return endDate.AddDate(0, 0, 1)
+}
+
+// DatesInRange returns every date between from and to inclusive.
+// When weekdays is not empty only dates falling on one of those weekdays are returned.
+func DatesInRange(from, to time.Time, weekdays []time.Weekday) []time.Time {
+	var dates []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if len(weekdays) > 0 && !slices.Contains(weekdays, d.Weekday()) {
+			continue
+		}
+		dates = append(dates, d)
+	}
+	return dates
+}
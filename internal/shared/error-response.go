@@ -1,5 +0,0 @@
-package shared
-
-type ErrorRes struct {
-	Message string `json:"message"`
-}
@@ -0,0 +1,144 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:hotel-system:problem:"
+)
+
+// Generic error codes, used when the error isn't one of the registered domain errors.
+const (
+	CodeInvalidRequest   = "request.invalid"
+	CodeValidationFailed = "request.validation_failed"
+	CodeBodyTooLarge     = "request.body_too_large"
+	CodeUnauthorized     = "auth.unauthorized"
+	CodeForbidden        = "auth.forbidden"
+	CodeNotFound         = "resource.not_found"
+	CodeConflict         = "resource.conflict"
+	CodeInternal         = "internal"
+)
+
+// Problem is an RFC 7807 problem details response. Code identifies the error for clients and never changes
+// once published, Detail is meant for humans and may.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes an invalid field of the request body, Code is the failed validation rule.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// WriteProblem writes the problem, filling in the type, title and the request id set by RequestID.
+func WriteProblem(w http.ResponseWriter, p Problem) error {
+	if p.Code == "" {
+		p.Code = statusCode(p.Status)
+	}
+	p.Type = problemTypePrefix + p.Code
+	p.Title = http.StatusText(p.Status)
+	p.RequestID = w.Header().Get(RequestIDHeader)
+
+	js, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	js = append(js, '\n')
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	w.Write(js)
+	return nil
+}
+
+// WriteError writes a problem with the generic code of the status.
+func WriteError(w http.ResponseWriter, status int, message string) error {
+	return WriteProblem(w, Problem{Status: status, Detail: message})
+}
+
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeBodyTooLarge
+	default:
+		if status >= 500 {
+			return CodeInternal
+		}
+		return CodeInvalidRequest
+	}
+}
+
+// DomainError is how a registered error is presented to clients.
+type DomainError struct {
+	Status int
+	Code   string
+	// Message defaults to the error text, for errors wrapped with details worth showing.
+	Message string
+}
+
+var domainErrors struct {
+	sync.RWMutex
+	errs  []error
+	specs []DomainError
+}
+
+// RegisterErrors maps a package's errors to their status and code. Packages register them next to where
+// the errors are declared, so handlers pass any error to WriteDomainError instead of matching them.
+func RegisterErrors(errs map[error]DomainError) {
+	domainErrors.Lock()
+	defer domainErrors.Unlock()
+
+	for err, spec := range errs {
+		for _, registered := range domainErrors.errs {
+			if registered == err {
+				panic(fmt.Sprintf("shared: error %q registered twice", err))
+			}
+		}
+		domainErrors.errs = append(domainErrors.errs, err)
+		domainErrors.specs = append(domainErrors.specs, spec)
+	}
+}
+
+// WriteDomainError writes the problem registered for err, unknown errors are answered with 500 without
+// leaking their text.
+func WriteDomainError(w http.ResponseWriter, err error) error {
+	domainErrors.RLock()
+	defer domainErrors.RUnlock()
+
+	for i, registered := range domainErrors.errs {
+		if !errors.Is(err, registered) {
+			continue
+		}
+		spec := domainErrors.specs[i]
+		message := spec.Message
+		if message == "" {
+			message = err.Error()
+		}
+		return WriteProblem(w, Problem{Status: spec.Status, Code: spec.Code, Detail: message})
+	}
+	return WriteError(w, http.StatusInternalServerError, "Sorry, something went wrong")
+}
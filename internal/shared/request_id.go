@@ -0,0 +1,30 @@
+package shared
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-Id"
+	// maxRequestIDLength bounds ids taken from clients, longer ones are replaced.
+	maxRequestIDLength = 128
+)
+
+// RequestID keeps the X-Request-Id a proxy or client sent or generates one, and echoes it in the response
+// so problems can be matched with logs. The id is also stored where chi's request logger reads it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		ctx := context.WithValue(r.Context(), middleware.RequestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
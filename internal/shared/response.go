@@ -19,7 +19,3 @@ func WriteJSON(w http.ResponseWriter, status int, data Envelope) error {
 	w.Write(js)
 	return nil
 }
-
-func WriteError(w http.ResponseWriter, status int, message string) error {
-	return WriteJSON(w, status, Envelope{"error": message})
-}
\ No newline at end of file
@@ -0,0 +1,103 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// NewValidator returns a validator reporting fields by their json names, so validation errors name the
+// fields clients sent.
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// WriteValidationError writes the failed rules of a validated body as field errors.
+func WriteValidationError(w http.ResponseWriter, err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body: %v", err))
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Code:    fe.Tag(),
+			Message: validationMessage(fe),
+		})
+	}
+	return WriteProblem(w, Problem{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidationFailed,
+		Detail: "Invalid body",
+		Errors: fields,
+	})
+}
+
+// fieldPath drops the struct name the validator starts namespaces with.
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+func validationMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + param
+	case "max":
+		return "must be at most " + param
+	case "len":
+		return "must be exactly " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "oneof":
+		return "must be one of " + param
+	case "gtfield", "gtefield", "ltfield", "ltefield":
+		return fmt.Sprintf("must be %s %s", comparison(fe.Tag()), fieldPath(param))
+	case "email", "uuid", "uuid4", "url", "http_url", "timezone", "numeric", "uppercase":
+		return "must be a valid " + fe.Tag()
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
+
+func comparison(tag string) string {
+	switch tag {
+	case "gtfield":
+		return "greater than"
+	case "gtefield":
+		return "greater than or equal to"
+	case "ltfield":
+		return "less than"
+	default:
+		return "less than or equal to"
+	}
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/apiversion"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIVersioning(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	newRouter := func(sunset time.Time) http.Handler {
+		routes := func(r *chi.Mux) {
+			r.Get("/hotel/{id}", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(apiversion.Prefix(r.Context())))
+			})
+		}
+
+		v1 := chi.NewRouter()
+		v1.Use(apiversion.Tag("v1"))
+		routes(v1)
+
+		legacy := chi.NewRouter()
+		legacy.Use(apiversion.Deprecation{Since: since, Sunset: sunset, Successor: "/v1"}.Handler)
+		routes(legacy)
+
+		r := chi.NewRouter()
+		r.Mount("/v1", v1)
+		r.Mount("/", legacy)
+		return r
+	}
+
+	serve := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("should_serve_versioned_routes_without_deprecation", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(newRouter(time.Time{}), "/v1/hotel/42")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "/v1", rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+	})
+
+	t.Run("should_mark_legacy_routes_deprecated", func(t *testing.T) {
+		t.Parallel()
+
+		sunset := time.Now().AddDate(0, 3, 0)
+		rec := serve(newRouter(sunset), "/hotel/42")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, "@"+strconv.FormatInt(since.Unix(), 10), rec.Header().Get("Deprecation"))
+		assert.Equal(t, sunset.UTC().Format(http.TimeFormat), rec.Header().Get("Sunset"))
+		assert.Equal(t, `</v1/hotel/42>; rel="successor-version"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("should_retire_legacy_routes_after_sunset", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newRouter(time.Now().AddDate(0, 0, -1))
+		problem := decodeProblem(t, serve(handler, "/hotel/42"), http.StatusGone)
+		assert.Equal(t, apiversion.CodeVersionSunset, problem.Code)
+
+		assert.Equal(t, http.StatusOK, serve(handler, "/v1/hotel/42").Code)
+	})
+}
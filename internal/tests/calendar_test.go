@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/calendar"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/ical"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CreateCalendarFeedResponse struct {
+	Feed calendar.Feed `json:"feed"`
+}
+
+type IssueGuestFeedResponse struct {
+	Feed calendar.GuestFeed `json:"feed"`
+}
+
+var (
+	calendarSuite *TestSuite
+	calendarSvc   *calendar.CalendarService
+)
+
+func init() {
+	calendarSuite = GetTestSuite()
+	calendarSvc = calendar.New(calendarSuite.GetQueries(), calendarSuite.GetValidator())
+	authSvc := calendarSuite.GetAuthService()
+
+	calendarSuite.RegisterPublicHandlers(calendarSvc.RegisterPublicHandlers)
+	calendarSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Get("/", calendarSvc.GuestFeedHandler)
+	}, "/calendar/reservations.ics")
+	calendarSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Post("/", calendarSvc.IssueGuestFeedHandler)
+		r.Delete("/", calendarSvc.RevokeGuestFeedHandler)
+	}, "/calendar/guest-feed")
+	calendarSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Use(authSvc.RequireHotelAdmin("hotelId"))
+		calendarSvc.RegisterHotelHandlers(r)
+	}, "/hotel/{hotelId}/calendar-feeds")
+}
+
+func readCalendar(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestCalendarFeeds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_export_guest_reservations_and_update_on_cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := calendarSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := calendarSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := calendarSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		otherGuest, err := calendarSuite.CreateTestGuest()
+		require.NoError(t, err)
+
+		startDate := time.Now().UTC().AddDate(0, 0, 3).Truncate(24 * time.Hour)
+		res, err := calendarSuite.CreateTestReservation(user.GuestID, roomType.ID, hotel.ID, startDate, startDate.AddDate(0, 0, 1))
+		require.NoError(t, err)
+
+		otherRes, err := calendarSuite.CreateTestReservation(otherGuest.ID, roomType.ID, hotel.ID, startDate, startDate)
+		require.NoError(t, err)
+
+		resp, err := calendarSuite.MakeAuthenticatedRequest("GET", "/calendar/reservations.ics", nil, user)
+		require.NoError(t, err)
+		feed := readCalendar(t, resp)
+
+		assert.Contains(t, feed, "BEGIN:VCALENDAR\r\n")
+		assert.Contains(t, feed, "METHOD:PUBLISH\r\n")
+		assert.Contains(t, feed, "UID:"+ical.ReservationUID(res.ID)+"\r\n")
+		assert.Contains(t, feed, "DTSTART;VALUE=DATE:"+startDate.Format("20060102")+"\r\n")
+		assert.Contains(t, feed, "DTEND;VALUE=DATE:"+startDate.AddDate(0, 0, 2).Format("20060102")+"\r\n")
+		assert.Contains(t, feed, "STATUS:CONFIRMED\r\n")
+		assert.NotContains(t, feed, otherRes.ID.String())
+
+		err = calendarSuite.GetQueries().UpdateReservationStatus(context.Background(), database.UpdateReservationStatusParams{
+			Status: "cancelled",
+			ID:     res.ID,
+		})
+		require.NoError(t, err)
+
+		resp, err = calendarSuite.MakeAuthenticatedRequest("GET", "/calendar/reservations.ics", nil, user)
+		require.NoError(t, err)
+		feed = readCalendar(t, resp)
+
+		assert.Contains(t, feed, "UID:"+ical.ReservationUID(res.ID)+"\r\n")
+		assert.Contains(t, feed, "SEQUENCE:1\r\n")
+		assert.Contains(t, feed, "STATUS:CANCELLED\r\n")
+	})
+
+	t.Run("should_export_guest_reservations_by_token", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := calendarSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := calendarSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := calendarSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().UTC().AddDate(0, 0, 4).Truncate(24 * time.Hour)
+		res, err := calendarSuite.CreateTestReservation(user.GuestID, roomType.ID, hotel.ID, startDate, startDate)
+		require.NoError(t, err)
+
+		issue := func() calendar.GuestFeed {
+			resp, err := calendarSuite.MakeAuthenticatedRequest("POST", "/calendar/guest-feed", nil, user)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			var issued IssueGuestFeedResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&issued))
+			require.NotEmpty(t, issued.Feed.URL)
+			return issued.Feed
+		}
+
+		first := issue()
+		resp, err := calendarSuite.MakeRequest("GET", first.URL, nil)
+		require.NoError(t, err)
+		feed := readCalendar(t, resp)
+		assert.Contains(t, feed, "UID:"+ical.ReservationUID(res.ID)+"\r\n")
+
+		// Issuing a new URL revokes the one that may have leaked.
+		second := issue()
+		assert.NotEqual(t, first.URL, second.URL)
+
+		resp, err = calendarSuite.MakeRequest("GET", first.URL, nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		revokeResp, err := calendarSuite.MakeAuthenticatedRequest("DELETE", "/calendar/guest-feed", nil, user)
+		require.NoError(t, err)
+		defer revokeResp.Body.Close()
+		require.Equal(t, http.StatusNoContent, revokeResp.StatusCode)
+
+		resp, err = calendarSuite.MakeRequest("GET", second.URL, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should_export_room_type_arrivals_and_departures_by_token", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := calendarSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := calendarSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		otherRoomType, err := calendarSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		admin, err := calendarSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		err = calendarSuite.GrantTestRole(admin.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: hotel.ID, Valid: true})
+		require.NoError(t, err)
+
+		guest, err := calendarSuite.CreateTestGuest()
+		require.NoError(t, err)
+
+		startDate := time.Now().UTC().AddDate(0, 0, 5).Truncate(24 * time.Hour)
+		res, err := calendarSuite.CreateTestReservation(guest.ID, roomType.ID, hotel.ID, startDate, startDate.AddDate(0, 0, 2))
+		require.NoError(t, err)
+
+		otherRes, err := calendarSuite.CreateTestReservation(guest.ID, otherRoomType.ID, hotel.ID, startDate, startDate)
+		require.NoError(t, err)
+
+		createResp, err := calendarSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/calendar-feeds", hotel.ID), calendar.CreateFeedBody{
+			RoomTypeID: &roomType.ID,
+		}, admin)
+		require.NoError(t, err)
+		defer createResp.Body.Close()
+		require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+		var created CreateCalendarFeedResponse
+		require.NoError(t, json.NewDecoder(createResp.Body).Decode(&created))
+		require.NotEmpty(t, created.Feed.URL)
+
+		resp, err := calendarSuite.MakeRequest("GET", created.Feed.URL, nil)
+		require.NoError(t, err)
+		feed := readCalendar(t, resp)
+
+		assert.Contains(t, feed, "UID:"+ical.ReservationPartUID(res.ID, "arrival")+"\r\n")
+		assert.Contains(t, feed, "UID:"+ical.ReservationPartUID(res.ID, "departure")+"\r\n")
+		assert.Contains(t, feed, "DTSTART;VALUE=DATE:"+startDate.Format("20060102")+"\r\n")
+		assert.Contains(t, feed, "DTSTART;VALUE=DATE:"+startDate.AddDate(0, 0, 3).Format("20060102")+"\r\n")
+		assert.Contains(t, feed, "Arrival: TestFirst TestLast")
+		assert.NotContains(t, feed, otherRes.ID.String())
+
+		deleteResp, err := calendarSuite.MakeAuthenticatedRequest("DELETE", fmt.Sprintf("/hotel/%s/calendar-feeds/%s", hotel.ID, created.Feed.ID), nil, admin)
+		require.NoError(t, err)
+		defer deleteResp.Body.Close()
+		require.Equal(t, http.StatusNoContent, deleteResp.StatusCode)
+
+		resp, err = calendarSuite.MakeRequest("GET", created.Feed.URL, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should_reject_room_type_of_another_hotel", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := calendarSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		otherHotel, err := calendarSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		foreignRoomType, err := calendarSuite.CreateTestRoomType(otherHotel.ID)
+		require.NoError(t, err)
+
+		admin, err := calendarSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		err = calendarSuite.GrantTestRole(admin.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: hotel.ID, Valid: true})
+		require.NoError(t, err)
+
+		resp, err := calendarSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/calendar-feeds", hotel.ID), calendar.CreateFeedBody{
+			RoomTypeID: &foreignRoomType.ID,
+		}, admin)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,305 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/channel"
+	"github.com/AlexKhomenko00/hotel-system/internal/channel/mockchannel"
+	"github.com/AlexKhomenko00/hotel-system/internal/channel/otaxml"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testChannelHotelCode = "TESTHOTEL"
+	testChannelAPIKey    = "test-channel-api-key"
+	testChannelRoomCode  = "DBL"
+)
+
+type ChannelResponse struct {
+	Channel channel.Channel `json:"channel"`
+}
+
+var channelSuite *TestSuite
+
+func init() {
+	channelSuite = GetTestSuite()
+	config := channelSuite.GetConfig()
+	reservationSvc := reservation.New(channelSuite.GetQueries(), channelSuite.GetValidator(), &config, channelSuite.GetDB(), channelSuite.GetOverbookingService())
+	channelSvc := channel.New(channelSuite.GetQueries(), channelSuite.GetValidator(), &config, channelSuite.GetDB(), reservationSvc, channelSuite.GetOverbookingService(), map[string]channel.Adapter{
+		otaxml.Name: otaxml.New(&http.Client{Timeout: 5 * time.Second}),
+	})
+	authSvc := channelSuite.GetAuthService()
+
+	channelSuite.RegisterPublicHandlers(channelSvc.RegisterPublicHandlers)
+	channelSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Use(authSvc.RequireHotelAdmin("hotelId"))
+		channelSvc.RegisterHotelHandlers(r)
+	}, "/hotel/{hotelId}/channels")
+}
+
+type channelFixture struct {
+	hotel     database.BookingHotel
+	roomType  database.BookingRoomType
+	admin     database.AuthUser
+	channel   channel.Channel
+	mock      *mockchannel.Server
+	startDate time.Time
+}
+
+// setupChannel creates a hotel with three nights of inventory and a channel mapped to its room type.
+func setupChannel(t *testing.T) channelFixture {
+	t.Helper()
+
+	hotel, err := channelSuite.CreateTestHotel()
+	require.NoError(t, err)
+
+	roomType, err := channelSuite.CreateTestRoomType(hotel.ID)
+	require.NoError(t, err)
+
+	admin, err := channelSuite.CreateTestUser()
+	require.NoError(t, err)
+	require.NoError(t, channelSuite.GrantTestRole(admin.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: hotel.ID, Valid: true}))
+
+	startDate := time.Now().UTC().AddDate(0, 0, 2).Truncate(24 * time.Hour)
+	_, err = channelSuite.GetQueries().BatchUpdateRoomTypeInventory(context.Background(), database.BatchUpdateRoomTypeInventoryParams{
+		HotelID:        hotel.ID,
+		RoomTypeID:     roomType.ID,
+		Dates:          []time.Time{startDate, startDate.AddDate(0, 0, 1), startDate.AddDate(0, 0, 2)},
+		TotalInventory: TestInventoryMax,
+	})
+	require.NoError(t, err)
+
+	mock := mockchannel.New(testChannelHotelCode, testChannelAPIKey)
+	mockServer := httptest.NewServer(mock)
+	t.Cleanup(mockServer.Close)
+
+	createResp, err := channelSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/channels", hotel.ID), channel.CreateChannelBody{
+		Name:        "Test OTA",
+		Adapter:     otaxml.Name,
+		EndpointURL: mockServer.URL,
+		HotelCode:   testChannelHotelCode,
+		APIKey:      testChannelAPIKey,
+	}, admin)
+	require.NoError(t, err)
+	defer createResp.Body.Close()
+	require.Equal(t, http.StatusCreated, createResp.StatusCode)
+
+	var created ChannelResponse
+	require.NoError(t, json.NewDecoder(createResp.Body).Decode(&created))
+
+	mappingResp, err := channelSuite.MakeAuthenticatedRequest("PUT", fmt.Sprintf("/hotel/%s/channels/%s/mappings/%s", hotel.ID, created.Channel.ID, roomType.ID), channel.RoomMappingBody{
+		ExternalRoomCode: testChannelRoomCode,
+		RatePlanCode:     "BAR",
+		Rate:             "120.00",
+		Currency:         "EUR",
+	}, admin)
+	require.NoError(t, err)
+	defer mappingResp.Body.Close()
+	require.Equal(t, http.StatusOK, mappingResp.StatusCode)
+
+	return channelFixture{
+		hotel:     hotel,
+		roomType:  roomType,
+		admin:     admin,
+		channel:   created.Channel,
+		mock:      mock,
+		startDate: startDate,
+	}
+}
+
+func (f channelFixture) sync(t *testing.T) channel.Channel {
+	t.Helper()
+
+	resp, err := channelSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/channels/%s/sync", f.hotel.ID, f.channel.ID), nil, f.admin)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var synced ChannelResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&synced))
+	return synced.Channel
+}
+
+func (f channelFixture) totalReserved(t *testing.T, date time.Time) int32 {
+	t.Helper()
+
+	var reserved int32
+	err := channelSuite.GetDB().GetDB().QueryRow(`
+		SELECT total_reserved FROM booking.room_type_inventory
+		WHERE hotel_id = $1 AND room_type_id = $2 AND date = $3`, f.hotel.ID, f.roomType.ID, date).Scan(&reserved)
+	require.NoError(t, err)
+	return reserved
+}
+
+func TestChannelManager(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_push_ari_and_import_pulled_booking_once", func(t *testing.T) {
+		t.Parallel()
+		f := setupChannel(t)
+
+		synced := f.sync(t)
+		assert.NotNil(t, synced.LastSyncedAt)
+		assert.Nil(t, synced.LastSyncError)
+
+		before, ok := f.mock.Night(testChannelRoomCode, f.startDate)
+		require.True(t, ok)
+		assert.Equal(t, int32(TestInventoryMax), before.Inventory)
+		assert.Equal(t, "BAR", before.RatePlanCode)
+		assert.Equal(t, "120.00", before.Rate)
+		assert.Equal(t, "EUR", before.Currency)
+		assert.False(t, before.StopSell)
+
+		booking := mockchannel.Booking("OTA-1", testChannelRoomCode, "BAR", f.startDate, f.startDate.AddDate(0, 0, 2), "Channel", "Guest", fmt.Sprintf("channel-%s@example.com", uuid.NewString()))
+		f.mock.AddReservation(booking)
+		f.sync(t)
+
+		reservationID, ok := f.mock.Confirmed("OTA-1")
+		require.True(t, ok)
+		assert.Equal(t, 0, f.mock.Pending())
+
+		res, err := channelSuite.GetQueries().GetReservationById(context.Background(), uuid.MustParse(reservationID))
+		require.NoError(t, err)
+		assert.Equal(t, f.roomType.ID, res.RoomTypeID)
+		assert.Equal(t, f.startDate.Format("2006-01-02"), res.StartDate.Format("2006-01-02"))
+		// The channel sends the departure day, the reservation stores the last night.
+		assert.Equal(t, f.startDate.AddDate(0, 0, 1).Format("2006-01-02"), res.EndDate.Format("2006-01-02"))
+
+		assert.Equal(t, int32(1), f.totalReserved(t, f.startDate))
+		assert.Equal(t, int32(1), f.totalReserved(t, f.startDate.AddDate(0, 0, 1)))
+		assert.Equal(t, int32(0), f.totalReserved(t, f.startDate.AddDate(0, 0, 2)))
+
+		after, ok := f.mock.Night(testChannelRoomCode, f.startDate)
+		require.True(t, ok)
+		assert.Equal(t, before.Available-1, after.Available)
+
+		// A channel that missed the confirmation sends the booking again.
+		f.mock.AddReservation(booking)
+		f.sync(t)
+
+		again, ok := f.mock.Confirmed("OTA-1")
+		require.True(t, ok)
+		assert.Equal(t, reservationID, again)
+		assert.Equal(t, int32(1), f.totalReserved(t, f.startDate))
+	})
+
+	t.Run("should_release_inventory_on_channel_cancellation", func(t *testing.T) {
+		t.Parallel()
+		f := setupChannel(t)
+
+		f.mock.AddReservation(mockchannel.Booking("OTA-2", testChannelRoomCode, "BAR", f.startDate, f.startDate.AddDate(0, 0, 1), "Channel", "Guest", fmt.Sprintf("channel-%s@example.com", uuid.NewString())))
+		f.sync(t)
+
+		reservationID, ok := f.mock.Confirmed("OTA-2")
+		require.True(t, ok)
+		assert.Equal(t, int32(1), f.totalReserved(t, f.startDate))
+
+		f.mock.AddReservation(mockchannel.Cancellation("OTA-2"))
+		f.sync(t)
+		// Redelivered cancellations are confirmed as well.
+		f.mock.AddReservation(mockchannel.Cancellation("OTA-2"))
+		f.sync(t)
+
+		_, failed := f.mock.Failed("OTA-2")
+		assert.False(t, failed)
+		assert.Equal(t, 0, f.mock.Pending())
+
+		res, err := channelSuite.GetQueries().GetReservationById(context.Background(), uuid.MustParse(reservationID))
+		require.NoError(t, err)
+		assert.Equal(t, string(reservation.ReservationStatusCancelled), res.Status)
+		assert.Equal(t, int32(0), f.totalReserved(t, f.startDate))
+	})
+
+	t.Run("should_refuse_private_endpoints", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := channelSuite.GetConfig()
+		cfg.Webhooks.AllowPrivateTargets = false
+		reservationSvc := reservation.New(channelSuite.GetQueries(), channelSuite.GetValidator(), &cfg, channelSuite.GetDB(), channelSuite.GetOverbookingService())
+		strictSvc := channel.New(channelSuite.GetQueries(), channelSuite.GetValidator(), &cfg, channelSuite.GetDB(), reservationSvc, channelSuite.GetOverbookingService(), map[string]channel.Adapter{
+			otaxml.Name: otaxml.New(&http.Client{Timeout: 5 * time.Second}),
+		})
+
+		r := chi.NewRouter()
+		r.Route("/hotel/{hotelId}/channels", strictSvc.RegisterHotelHandlers)
+
+		hotel, err := channelSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		for _, endpoint := range []string{"http://127.0.0.1:8080/ota", "http://169.254.169.254/latest/meta-data", "http://10.0.0.1/ota"} {
+			body, err := json.Marshal(channel.CreateChannelBody{
+				Name:        "Private " + endpoint,
+				Adapter:     otaxml.Name,
+				EndpointURL: endpoint,
+				HotelCode:   testChannelHotelCode,
+				APIKey:      testChannelAPIKey,
+			})
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/hotel/%s/channels/", hotel.ID), bytes.NewReader(body)))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, endpoint)
+		}
+
+		channels, err := channelSuite.GetQueries().ListHotelChannels(context.Background(), hotel.ID)
+		require.NoError(t, err)
+		assert.Empty(t, channels)
+	})
+
+	t.Run("should_report_bookings_it_cannot_take", func(t *testing.T) {
+		t.Parallel()
+		f := setupChannel(t)
+
+		f.mock.AddReservation(mockchannel.Booking("OTA-3", "UNMAPPED", "BAR", f.startDate, f.startDate.AddDate(0, 0, 1), "Channel", "Guest", fmt.Sprintf("channel-%s@example.com", uuid.NewString())))
+		f.mock.AddReservation(mockchannel.Cancellation("OTA-UNKNOWN"))
+		f.sync(t)
+
+		msg, ok := f.mock.Failed("OTA-3")
+		require.True(t, ok)
+		assert.Contains(t, msg, "Room mapping not found")
+
+		_, ok = f.mock.Failed("OTA-UNKNOWN")
+		assert.True(t, ok)
+		assert.Equal(t, int32(0), f.totalReserved(t, f.startDate))
+	})
+
+	t.Run("should_import_pushed_booking_and_reject_bad_credentials", func(t *testing.T) {
+		t.Parallel()
+		f := setupChannel(t)
+
+		api := httptest.NewServer(channelSuite.GetHandler())
+		defer api.Close()
+		notifyURL := fmt.Sprintf("%s/channels/%s/notify", api.URL, f.channel.ID)
+
+		booking := mockchannel.Booking("OTA-4", testChannelRoomCode, "BAR", f.startDate, f.startDate.AddDate(0, 0, 1), "Channel", "Guest", fmt.Sprintf("channel-%s@example.com", uuid.NewString()))
+
+		impostor := mockchannel.New(testChannelHotelCode, "wrong-channel-api-key")
+		_, status, err := impostor.Notify(context.Background(), http.DefaultClient, notifyURL, booking)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, int32(0), f.totalReserved(t, f.startDate))
+
+		rs, status, err := f.mock.Notify(context.Background(), http.DefaultClient, notifyURL, booking)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+		require.NotNil(t, rs.Success)
+		require.Len(t, rs.HotelReservations, 1)
+		assert.Equal(t, "OTA-4", rs.HotelReservations[0].UniqueID.ID)
+		require.NotNil(t, rs.HotelReservations[0].ResGlobalInfo)
+		assert.NotEmpty(t, rs.HotelReservations[0].ResGlobalInfo.HotelReservationIDs)
+		assert.Equal(t, int32(1), f.totalReserved(t, f.startDate))
+	})
+}
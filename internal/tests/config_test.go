@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(t *testing.T, name, content string) string {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	file := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  legacy_routes_sunset: 2027-01-01
+db:
+  host: file-host
+  port: 5433
+  database: booking
+  username: file-user
+  password: file-password
+  schema: public
+  sslmode: disable
+  statement_timeout: 2s
+auth:
+  jwt_secret: file-secret
+`)
+
+	t.Run("should_apply_sources_in_order", func(t *testing.T) {
+		secrets := filepath.Join(dir, "secrets")
+		require.NoError(t, os.Mkdir(secrets, 0o700))
+		writeFile(t, "secrets/psql-password", "mounted-password\n")
+
+		t.Setenv("DB_HOST", "env-host")
+		t.Setenv("DB_PASSWORD", "env-password")
+		t.Setenv("DB_MAX_OPEN_CONNS", "40")
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("LOG_FORMAT", "")
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		loader := config.NewLoader(fs)
+		require.NoError(t, fs.Parse([]string{"-config", file, "-secrets-dir", secrets, "-db.max-open-conns", "50"}))
+
+		cfg, err := loader.Load(shared.NewValidator())
+		require.NoError(t, err)
+
+		assert.Equal(t, 9000, cfg.Server.Port)
+		assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), cfg.Server.LegacyRoutesSunset)
+		assert.Equal(t, 2*time.Second, cfg.DB.StatementTimeout)
+		assert.Equal(t, "file-user", cfg.DB.Username)
+		assert.Equal(t, "env-host", cfg.DB.Host)
+		assert.Equal(t, "mounted-password", cfg.DB.Password)
+		assert.Equal(t, 50, cfg.DB.MaxOpenConns)
+		// Defaults fill what no source sets.
+		assert.Equal(t, 10, cfg.DB.MaxIdleConns)
+		assert.Equal(t, 1.0, cfg.Booking.OverbookingFactor)
+		assert.Equal(t, "json", cfg.Log.Format)
+	})
+
+	t.Run("should_report_every_invalid_setting", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", file)
+		t.Setenv("DB_PORT", "postgres")
+		t.Setenv("SHUTDOWN_DRAIN_DELAY", "5")
+
+		_, err := config.NewLoader(nil).Load(shared.NewValidator())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "DB_PORT")
+		assert.ErrorContains(t, err, "SHUTDOWN_DRAIN_DELAY")
+	})
+
+	t.Run("should_reject_unknown_keys", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", writeFile(t, "typo.yaml", "db:\n  hostname: localhost\n"))
+
+		_, err := config.NewLoader(nil).Load(shared.NewValidator())
+		assert.ErrorContains(t, err, "hostname")
+	})
+
+	t.Run("should_validate_the_result", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", file)
+		t.Setenv("EVENT_WAKE", "kafka")
+
+		_, err := config.NewLoader(nil).Load(shared.NewValidator())
+		assert.ErrorContains(t, err, "Wake")
+	})
+}
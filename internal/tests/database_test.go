@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase(t *testing.T) {
+	t.Parallel()
+
+	suite := GetTestSuite()
+	ctx := context.Background()
+
+	t.Run("should_apply_pool_settings", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, 20, suite.GetDB().GetDB().Stats().MaxOpenConnections)
+		assert.Equal(t, int32(2), suite.GetDB().Pool().Config().MaxConns)
+
+		var timeout string
+		require.NoError(t, suite.GetDB().GetDB().QueryRowContext(ctx, "SHOW statement_timeout").Scan(&timeout))
+		assert.Equal(t, "30s", timeout)
+
+		require.NoError(t, suite.GetDB().Pool().QueryRow(ctx, "SHOW statement_timeout").Scan(&timeout))
+		assert.Equal(t, "30s", timeout)
+	})
+
+	t.Run("should_insert_inventory_in_one_batch", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := suite.CreateTestHotel()
+		require.NoError(t, err)
+		first, err := suite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+		second, err := suite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		from := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 10)
+		dates := []time.Time{from, from.AddDate(0, 0, 1), from.AddDate(0, 0, 2)}
+
+		rows, err := database.BatchUpdateRoomTypeInventories(ctx, suite.GetDB().Pool(), []database.BatchUpdateRoomTypeInventoryParams{
+			{HotelID: hotel.ID, RoomTypeID: first.ID, Dates: dates, TotalInventory: 5},
+			{HotelID: hotel.ID, RoomTypeID: second.ID, Dates: dates[:2], TotalInventory: 5},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 2}, rows)
+
+		// Dates already there are skipped.
+		rows, err = database.BatchUpdateRoomTypeInventories(ctx, suite.GetDB().Pool(), []database.BatchUpdateRoomTypeInventoryParams{
+			{HotelID: hotel.ID, RoomTypeID: second.ID, Dates: dates, TotalInventory: 5},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, rows)
+	})
+
+	t.Run("should_roll_back_the_batch_when_a_statement_fails", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := suite.CreateTestHotel()
+		require.NoError(t, err)
+		roomType, err := suite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		date := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 10)
+		_, err = database.BatchUpdateRoomTypeInventories(ctx, suite.GetDB().Pool(), []database.BatchUpdateRoomTypeInventoryParams{
+			{HotelID: hotel.ID, RoomTypeID: roomType.ID, Dates: []time.Time{date}, TotalInventory: 5},
+			{HotelID: hotel.ID, RoomTypeID: uuid.New(), Dates: []time.Time{date}, TotalInventory: 5},
+		})
+
+		var batchErr *database.BatchError
+		require.True(t, errors.As(err, &batchErr), "got %v", err)
+		assert.Equal(t, 1, batchErr.Index)
+
+		var count int
+		require.NoError(t, suite.GetDB().GetDB().QueryRowContext(ctx,
+			"SELECT count(*) FROM booking.room_type_inventory WHERE room_type_id = $1", roomType.ID).Scan(&count))
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+
+	passing := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+
+	ready := func(t *testing.T, checker *health.Checker) (int, string, map[string]health.Result) {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		checker.ReadyHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var body struct {
+			Status string                   `json:"status"`
+			Checks map[string]health.Result `json:"checks"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body.Status, body.Checks
+	}
+
+	t.Run("should_be_ready_when_required_checks_pass", func(t *testing.T) {
+		t.Parallel()
+
+		checker := health.New()
+		checker.Require("database", passing)
+		checker.Monitor("outbox", failing)
+
+		code, status, checks := ready(t, checker)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, health.StatusReady, status)
+		assert.Equal(t, health.Result{Status: health.StatusOK, Critical: true}, checks["database"])
+		assert.Equal(t, health.Result{Status: health.StatusFailed, Error: "connection refused"}, checks["outbox"])
+	})
+
+	t.Run("should_not_be_ready_when_required_check_fails", func(t *testing.T) {
+		t.Parallel()
+
+		checker := health.New()
+		checker.Require("database", failing)
+
+		code, status, _ := ready(t, checker)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, health.StatusNotReady, status)
+	})
+
+	t.Run("should_fail_readiness_but_stay_live_while_draining", func(t *testing.T) {
+		t.Parallel()
+
+		checker := health.New()
+		checker.Require("database", passing)
+		checker.Drain()
+
+		code, status, _ := ready(t, checker)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, health.StatusDraining, status)
+
+		rec := httptest.NewRecorder()
+		checker.LiveHandler(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,363 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/inventory"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type BulkUpdateResponse struct {
+	Result inventory.BulkUpdateResult `json:"result"`
+}
+
+var inventorySuite *TestSuite
+
+func init() {
+	inventorySuite = GetTestSuite()
+	inventorySvc := inventory.New(inventorySuite.GetQueries(), inventorySuite.GetValidator(), inventorySuite.GetDB())
+	authSvc := inventorySuite.GetAuthService()
+
+	inventorySuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Use(authSvc.RequireHotelAdmin("hotelId"))
+		inventorySvc.RegisterHotelHandlers(r)
+	}, "/hotel/{hotelId}/inventory")
+}
+
+type inventoryFixture struct {
+	hotel     database.BookingHotel
+	roomType  database.BookingRoomType
+	admin     database.AuthUser
+	startDate time.Time
+}
+
+// setupInventory creates a room type with a week of inventory starting on a Monday.
+func setupInventory(t *testing.T) inventoryFixture {
+	t.Helper()
+
+	hotel, err := inventorySuite.CreateTestHotel()
+	require.NoError(t, err)
+
+	roomType, err := inventorySuite.CreateTestRoomType(hotel.ID)
+	require.NoError(t, err)
+
+	admin, err := inventorySuite.CreateTestUser()
+	require.NoError(t, err)
+	require.NoError(t, inventorySuite.GrantTestRole(admin.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: hotel.ID, Valid: true}))
+
+	startDate := time.Now().UTC().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	for startDate.Weekday() != time.Monday {
+		startDate = startDate.AddDate(0, 0, 1)
+	}
+
+	dates := make([]time.Time, 0, 7)
+	for i := 0; i < 7; i++ {
+		dates = append(dates, startDate.AddDate(0, 0, i))
+	}
+	_, err = inventorySuite.GetQueries().BatchUpdateRoomTypeInventory(context.Background(), database.BatchUpdateRoomTypeInventoryParams{
+		HotelID:        hotel.ID,
+		RoomTypeID:     roomType.ID,
+		Dates:          dates,
+		TotalInventory: TestInventoryMax,
+	})
+	require.NoError(t, err)
+
+	return inventoryFixture{
+		hotel:     hotel,
+		roomType:  roomType,
+		admin:     admin,
+		startDate: startDate,
+	}
+}
+
+func (f inventoryFixture) bulk(t *testing.T, path string, body inventory.BulkUpdateBody) (int, inventory.BulkUpdateResult) {
+	t.Helper()
+
+	resp, err := inventorySuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/inventory/%s", f.hotel.ID, path), body, f.admin)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result BulkUpdateResponse
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	}
+	return resp.StatusCode, result.Result
+}
+
+func (f inventoryFixture) inventory(t *testing.T, date time.Time) database.BookingRoomTypeInventory {
+	t.Helper()
+
+	rows, err := inventorySuite.GetQueries().GetRoomTypeInventoryForDates(context.Background(), database.GetRoomTypeInventoryForDatesParams{
+		HotelID:    f.hotel.ID,
+		RoomTypeID: f.roomType.ID,
+		Dates:      []time.Time{date},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	return rows[0]
+}
+
+func TestBulkInventory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_preview_without_changes", func(t *testing.T) {
+		t.Parallel()
+		f := setupInventory(t)
+		total := int32(5)
+
+		status, result := f.bulk(t, "bulk/preview", inventory.BulkUpdateBody{
+			RoomTypeID:     f.roomType.ID,
+			From:           shared.Date(f.startDate),
+			To:             shared.Date(f.startDate.AddDate(0, 0, 6)),
+			Operation:      inventory.OperationSet,
+			TotalInventory: &total,
+		})
+		require.Equal(t, http.StatusOK, status)
+		assert.False(t, result.Applied)
+		require.Len(t, result.Dates, 7)
+		assert.Equal(t, inventory.DateStatusUpdated, result.Dates[0].Status)
+		assert.Equal(t, total, *result.Dates[0].NewTotalInventory)
+
+		inv := f.inventory(t, f.startDate)
+		assert.Equal(t, int32(TestInventoryMax), inv.TotalInventory)
+		assert.Equal(t, result.Dates[0].Version, inv.Version)
+	})
+
+	t.Run("should_set_and_adjust_matching_weekdays", func(t *testing.T) {
+		t.Parallel()
+		f := setupInventory(t)
+		before := f.inventory(t, f.startDate)
+		total := int32(4)
+
+		status, result := f.bulk(t, "bulk", inventory.BulkUpdateBody{
+			RoomTypeID:     f.roomType.ID,
+			From:           shared.Date(f.startDate),
+			To:             shared.Date(f.startDate.AddDate(0, 0, 6)),
+			Weekdays:       []time.Weekday{time.Monday, time.Wednesday},
+			Operation:      inventory.OperationSet,
+			TotalInventory: &total,
+		})
+		require.Equal(t, http.StatusOK, status)
+		assert.True(t, result.Applied)
+		require.Len(t, result.Dates, 2)
+		assert.Empty(t, result.Conflicts)
+
+		monday := f.inventory(t, f.startDate)
+		assert.Equal(t, total, monday.TotalInventory)
+		assert.Greater(t, monday.Version, before.Version)
+		assert.Equal(t, monday.Version, result.Dates[0].Version)
+		assert.Equal(t, int32(TestInventoryMax), f.inventory(t, f.startDate.AddDate(0, 0, 1)).TotalInventory)
+		assert.Equal(t, total, f.inventory(t, f.startDate.AddDate(0, 0, 2)).TotalInventory)
+
+		delta := int32(-3)
+		status, result = f.bulk(t, "bulk", inventory.BulkUpdateBody{
+			RoomTypeID: f.roomType.ID,
+			From:       shared.Date(f.startDate),
+			To:         shared.Date(f.startDate.AddDate(0, 0, 1)),
+			Operation:  inventory.OperationAdjust,
+			Delta:      &delta,
+		})
+		require.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, int32(1), f.inventory(t, f.startDate).TotalInventory)
+		assert.Equal(t, int32(TestInventoryMax-3), f.inventory(t, f.startDate.AddDate(0, 0, 1)).TotalInventory)
+	})
+
+	t.Run("should_list_conflicts_below_reserved", func(t *testing.T) {
+		t.Parallel()
+		f := setupInventory(t)
+
+		_, err := inventorySuite.GetDB().GetDB().Exec(`
+			UPDATE booking.room_type_inventory SET total_reserved = 6
+			WHERE hotel_id = $1 AND room_type_id = $2 AND date = $3`, f.hotel.ID, f.roomType.ID, f.startDate)
+		require.NoError(t, err)
+
+		total := int32(5)
+		status, result := f.bulk(t, "bulk", inventory.BulkUpdateBody{
+			RoomTypeID:     f.roomType.ID,
+			From:           shared.Date(f.startDate),
+			To:             shared.Date(f.startDate.AddDate(0, 0, 1)),
+			Operation:      inventory.OperationSet,
+			TotalInventory: &total,
+		})
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, inventory.DateStatusConflict, result.Conflicts[0].Status)
+		assert.Equal(t, int32(6), result.Conflicts[0].TotalReserved)
+		assert.Equal(t, inventory.DateStatusUpdated, result.Dates[1].Status)
+
+		assert.Equal(t, int32(TestInventoryMax), f.inventory(t, f.startDate).TotalInventory)
+		assert.Equal(t, total, f.inventory(t, f.startDate.AddDate(0, 0, 1)).TotalInventory)
+	})
+
+	t.Run("should_update_overbooked_nights", func(t *testing.T) {
+		t.Parallel()
+		f := setupInventory(t)
+
+		overbooked := int32(TestInventoryMax + 2)
+		_, err := inventorySuite.GetDB().GetDB().Exec(`
+			UPDATE booking.room_type_inventory SET total_reserved = $4
+			WHERE hotel_id = $1 AND room_type_id = $2 AND date = $3`, f.hotel.ID, f.roomType.ID, f.startDate, overbooked)
+		require.NoError(t, err)
+
+		body := inventory.BulkUpdateBody{
+			RoomTypeID: f.roomType.ID,
+			From:       shared.Date(f.startDate),
+			To:         shared.Date(f.startDate),
+			Operation:  inventory.OperationClose,
+		}
+		status, result := f.bulk(t, "bulk", body)
+		require.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Conflicts)
+		assert.True(t, result.Dates[0].NewStopSell)
+
+		delta := int32(1)
+		body.Operation, body.Delta = inventory.OperationAdjust, &delta
+		status, result = f.bulk(t, "bulk", body)
+		require.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, int32(TestInventoryMax+1), f.inventory(t, f.startDate).TotalInventory)
+
+		// Cutting further below the reservations is still refused.
+		total := int32(TestInventoryMax - 1)
+		body.Operation, body.Delta, body.TotalInventory = inventory.OperationSet, nil, &total
+		status, result = f.bulk(t, "bulk", body)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, overbooked, result.Conflicts[0].TotalReserved)
+		assert.Equal(t, int32(TestInventoryMax+1), f.inventory(t, f.startDate).TotalInventory)
+	})
+
+	t.Run("should_close_and_open_sales", func(t *testing.T) {
+		t.Parallel()
+		f := setupInventory(t)
+		body := inventory.BulkUpdateBody{
+			RoomTypeID: f.roomType.ID,
+			From:       shared.Date(f.startDate),
+			To:         shared.Date(f.startDate.AddDate(0, 0, 2)),
+			Operation:  inventory.OperationClose,
+		}
+
+		stopSell := func() []bool {
+			restrictions, err := inventorySuite.GetQueries().GetRoomTypeRestrictionsForRange(context.Background(), database.GetRoomTypeRestrictionsForRangeParams{
+				HotelID:    f.hotel.ID,
+				RoomTypeID: f.roomType.ID,
+				DateFrom:   f.startDate,
+				DateTo:     f.startDate.AddDate(0, 0, 2),
+			})
+			require.NoError(t, err)
+			values := make([]bool, 0, len(restrictions))
+			for _, r := range restrictions {
+				values = append(values, r.StopSell)
+			}
+			return values
+		}
+
+		status, result := f.bulk(t, "bulk", body)
+		require.Equal(t, http.StatusOK, status)
+		assert.True(t, result.Dates[0].NewStopSell)
+		assert.Equal(t, []bool{true, true, true}, stopSell())
+		assert.Equal(t, int32(TestInventoryMax), f.inventory(t, f.startDate).TotalInventory)
+
+		// Closing again changes nothing.
+		status, result = f.bulk(t, "bulk", body)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, inventory.DateStatusUnchanged, result.Dates[0].Status)
+
+		body.Operation = inventory.OperationOpen
+		status, _ = f.bulk(t, "bulk", body)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []bool{false, false, false}, stopSell())
+	})
+
+	t.Run("should_reject_invalid_requests", func(t *testing.T) {
+		t.Parallel()
+		f := setupInventory(t)
+
+		status, _ := f.bulk(t, "bulk", inventory.BulkUpdateBody{
+			RoomTypeID: f.roomType.ID,
+			From:       shared.Date(f.startDate),
+			To:         shared.Date(f.startDate),
+			Operation:  inventory.OperationSet,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = f.bulk(t, "bulk", inventory.BulkUpdateBody{
+			RoomTypeID: f.roomType.ID,
+			From:       shared.Date(f.startDate.AddDate(0, 0, 1)),
+			To:         shared.Date(f.startDate),
+			Operation:  inventory.OperationClose,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = f.bulk(t, "bulk", inventory.BulkUpdateBody{
+			RoomTypeID: uuid.New(),
+			From:       shared.Date(f.startDate),
+			To:         shared.Date(f.startDate),
+			Operation:  inventory.OperationClose,
+		})
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestReconcileInventory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_report_and_repair_reserved_drift", func(t *testing.T) {
+		t.Parallel()
+		f := setupInventory(t)
+		ctx := context.Background()
+		inventorySvc := inventory.New(inventorySuite.GetQueries(), inventorySuite.GetValidator(), inventorySuite.GetDB())
+
+		guest, err := inventorySuite.CreateTestGuest()
+		require.NoError(t, err)
+		res, err := inventorySuite.CreateTestReservation(guest.ID, f.roomType.ID, f.hotel.ID, f.startDate, f.startDate.AddDate(0, 0, 1))
+		require.NoError(t, err)
+
+		// The reservation holds two nights nobody counted, the third night counts rooms nobody holds.
+		db := inventorySuite.GetDB().GetDB()
+		_, err = db.Exec(`UPDATE booking.reservations SET status = 'paid' WHERE id = $1`, res.ID)
+		require.NoError(t, err)
+		_, err = db.Exec(`
+			UPDATE booking.room_type_inventory SET total_reserved = 3
+			WHERE hotel_id = $1 AND room_type_id = $2 AND date = $3`, f.hotel.ID, f.roomType.ID, f.startDate.AddDate(0, 0, 2))
+		require.NoError(t, err)
+
+		report, err := inventorySvc.Reconcile(ctx, f.hotel.ID, f.startDate, false)
+		require.NoError(t, err)
+		require.Len(t, report.Discrepancies, 3)
+		assert.Equal(t, 3, report.Unrepaired)
+		assert.Equal(t, int32(0), report.Discrepancies[0].TotalReserved)
+		assert.Equal(t, int32(1), report.Discrepancies[0].ActualReserved)
+		assert.Equal(t, int32(3), report.Discrepancies[2].TotalReserved)
+		assert.Equal(t, int32(0), report.Discrepancies[2].ActualReserved)
+		assert.Equal(t, int32(0), f.inventory(t, f.startDate).TotalReserved)
+
+		before := f.inventory(t, f.startDate)
+		report, err = inventorySvc.Reconcile(ctx, f.hotel.ID, f.startDate, true)
+		require.NoError(t, err)
+		require.Len(t, report.Discrepancies, 3)
+		assert.Equal(t, 0, report.Unrepaired)
+
+		after := f.inventory(t, f.startDate)
+		assert.Equal(t, int32(1), after.TotalReserved)
+		assert.Greater(t, after.Version, before.Version)
+		assert.Equal(t, int32(1), f.inventory(t, f.startDate.AddDate(0, 0, 1)).TotalReserved)
+		assert.Equal(t, int32(0), f.inventory(t, f.startDate.AddDate(0, 0, 2)).TotalReserved)
+
+		report, err = inventorySvc.Reconcile(ctx, f.hotel.ID, f.startDate, false)
+		require.NoError(t, err)
+		assert.Empty(t, report.Discrepancies)
+	})
+}
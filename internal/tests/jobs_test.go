@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/jobs"
+	"github.com/AlexKhomenko00/hotel-system/internal/lock"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type JobResponse struct {
+	Job jobs.Job `json:"job"`
+}
+
+type JobsResponse struct {
+	Jobs []jobs.Job `json:"jobs"`
+}
+
+var jobsSuite *TestSuite
+
+func init() {
+	jobsSuite = GetTestSuite()
+	jobSvc := jobs.New(jobsSuite.GetQueries(), jobsSuite.GetDB())
+	go jobSvc.RunTriggeredWorker(context.Background())
+	authSvc := jobsSuite.GetAuthService()
+
+	jobsSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Use(authSvc.RequireAdmin)
+		jobSvc.RegisterAdminHandlers(r)
+	}, "/admin/jobs")
+}
+
+func triggerHotelInventory(t *testing.T, hotelID uuid.UUID, admin database.AuthUser) jobs.Job {
+	t.Helper()
+
+	// The previous run of the hotel releases its lock right after it's recorded as finished.
+	var triggered JobResponse
+	require.Eventually(t, func() bool {
+		resp, err := jobsSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/admin/jobs/inventory/hotels/%s", hotelID), nil, admin)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusConflict {
+			return false
+		}
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&triggered))
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, jobs.RunStatusRunning, triggered.Job.Status)
+
+	var job jobs.Job
+	require.Eventually(t, func() bool {
+		resp, err := jobsSuite.MakeAuthenticatedRequest("GET", fmt.Sprintf("/admin/jobs/%s", triggered.Job.ID), nil, admin)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got JobResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		job = got.Job
+		return job.Status != jobs.RunStatusRunning
+	}, 10*time.Second, 100*time.Millisecond)
+
+	return job
+}
+
+func TestJobs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_populate_triggered_hotel_once", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := jobsSuite.CreateTestHotel()
+		require.NoError(t, err)
+		_, err = jobsSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		admin, err := jobsSuite.CreateTestUser()
+		require.NoError(t, err)
+		require.NoError(t, jobsSuite.GrantTestRole(admin.ID, auth.RoleAdmin, uuid.NullUUID{}))
+
+		first := triggerHotelInventory(t, hotel.ID, admin)
+		assert.Equal(t, jobs.RunStatusCompleted, first.Status)
+		require.NotNil(t, first.HotelID)
+		assert.Equal(t, hotel.ID, *first.HotelID)
+		assert.Equal(t, int32(1), first.HotelsProcessed)
+		assert.Equal(t, int64(jobs.DefaultWindowDays+1), first.RowsInserted)
+		assert.NotNil(t, first.FinishedAt)
+		assert.Empty(t, first.Errors)
+
+		// Inventory is already there, the second run has nothing to add.
+		second := triggerHotelInventory(t, hotel.ID, admin)
+		assert.Equal(t, jobs.RunStatusCompleted, second.Status)
+		assert.Equal(t, int64(0), second.RowsInserted)
+
+		resp, err := jobsSuite.MakeAuthenticatedRequest("GET", fmt.Sprintf("/admin/jobs?hotelId=%s", hotel.ID), nil, admin)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var listed JobsResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&listed))
+		require.Len(t, listed.Jobs, 2)
+		assert.Equal(t, second.ID, listed.Jobs[0].ID)
+		assert.Equal(t, first.ID, listed.Jobs[1].ID)
+	})
+
+	t.Run("should_not_overlap_runs_of_a_hotel", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := jobsSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		admin, err := jobsSuite.CreateTestUser()
+		require.NoError(t, err)
+		require.NoError(t, jobsSuite.GrantTestRole(admin.ID, auth.RoleAdmin, uuid.NullUUID{}))
+
+		ctx := context.Background()
+		held, err := lock.New(jobsSuite.GetDB().GetDB()).TryLock(ctx, jobs.LockName("hotel="+hotel.ID.String()))
+		require.NoError(t, err)
+
+		resp, err := jobsSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/admin/jobs/inventory/hotels/%s", hotel.ID), nil, admin)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		require.NoError(t, held.Unlock(ctx))
+		job := triggerHotelInventory(t, hotel.ID, admin)
+		assert.Equal(t, jobs.RunStatusCompleted, job.Status)
+	})
+
+	t.Run("should_interrupt_runs_left_by_a_stopped_process", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := jobsSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		abandoned, err := jobsSuite.GetQueries().CreateInventoryCronRun(ctx, database.CreateInventoryCronRunParams{
+			ID:           uuid.New(),
+			PartitionKey: "hotel=" + hotel.ID.String(),
+			WindowEnd:    time.Now().UTC().Truncate(24 * time.Hour),
+			HotelID:      uuid.NullUUID{UUID: hotel.ID, Valid: true},
+		})
+		require.NoError(t, err)
+
+		workerCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go jobs.New(jobsSuite.GetQueries(), jobsSuite.GetDB()).RunTriggeredWorker(workerCtx)
+
+		require.Eventually(t, func() bool {
+			run, err := jobsSuite.GetQueries().GetInventoryCronRun(ctx, abandoned.ID)
+			require.NoError(t, err)
+			return run.Status == string(jobs.RunStatusInterrupted) && run.FinishedAt.Valid
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("should_reject_unknown_hotels_and_non_admins", func(t *testing.T) {
+		t.Parallel()
+
+		admin, err := jobsSuite.CreateTestUser()
+		require.NoError(t, err)
+		require.NoError(t, jobsSuite.GrantTestRole(admin.ID, auth.RoleAdmin, uuid.NullUUID{}))
+
+		resp, err := jobsSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/admin/jobs/inventory/hotels/%s", uuid.New()), nil, admin)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		resp, err = jobsSuite.MakeAuthenticatedRequest("GET", fmt.Sprintf("/admin/jobs/%s", uuid.New()), nil, admin)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		user, err := jobsSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		resp, err = jobsSuite.MakeAuthenticatedRequest("GET", "/admin/jobs", nil, user)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/lock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+	suite := GetTestSuite()
+	locker := lock.New(suite.GetDB().GetDB())
+	ctx := context.Background()
+
+	t.Run("should_allow_one_holder_at_a_time", func(t *testing.T) {
+		t.Parallel()
+		name := "test-lock:" + uuid.NewString()
+
+		first, err := locker.TryLock(ctx, name)
+		require.NoError(t, err)
+
+		_, err = locker.TryLock(ctx, name)
+		assert.ErrorIs(t, err, lock.ErrLocked)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+		defer cancel()
+		_, err = locker.WaitLock(waitCtx, name, 50*time.Millisecond)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		released := make(chan struct{})
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			assert.NoError(t, first.Unlock(ctx))
+			close(released)
+		}()
+
+		second, err := locker.WaitLock(ctx, name, 50*time.Millisecond)
+		require.NoError(t, err)
+		<-released
+		require.NoError(t, second.Unlock(ctx))
+	})
+
+	t.Run("should_report_lost_lease", func(t *testing.T) {
+		t.Parallel()
+		name := "test-lease:" + uuid.NewString()
+
+		lease, err := locker.TryLease(ctx, name, 50*time.Millisecond)
+		require.NoError(t, err)
+		runCtx, cancel := lease.Context(ctx)
+		defer cancel()
+
+		// Ending the holder's session drops its locks, as a broken connection would.
+		key := lock.Key(name)
+		_, err = suite.GetDB().GetDB().Exec(`
+			SELECT pg_terminate_backend(pid) FROM pg_locks
+			WHERE locktype = 'advisory' AND objsubid = 1
+				AND classid::bigint = ($1::bigint >> 32) & 4294967295
+				AND objid::bigint = $1::bigint & 4294967295`, key)
+		require.NoError(t, err)
+
+		select {
+		case <-lease.Lost():
+		case <-time.After(5 * time.Second):
+			t.Fatal("lease was not reported lost")
+		}
+		<-runCtx.Done()
+		assert.ErrorIs(t, context.Cause(runCtx), lock.ErrLost)
+		assert.ErrorIs(t, lease.Release(ctx), lock.ErrLost)
+
+		// The lock is free for the next holder.
+		next, err := locker.TryLock(ctx, name)
+		require.NoError(t, err)
+		require.NoError(t, next.Unlock(ctx))
+	})
+}
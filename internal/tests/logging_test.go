@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.FormatJSON, "info")
+	require.NoError(t, err)
+
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	lines := func(t *testing.T) []map[string]any {
+		t.Helper()
+
+		var out []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+			out = append(out, entry)
+		}
+		buf.Reset()
+		return out
+	}
+
+	t.Run("should_log_request_fields_on_every_line", func(t *testing.T) {
+		r := chi.NewRouter()
+		r.Use(shared.RequestID)
+		r.Use(logging.Middleware)
+		r.Get("/hotel/{hotelId}/rooms/{id}", func(w http.ResponseWriter, r *http.Request) {
+			logging.Add(r.Context(), "user_id", "u-1")
+			logging.FromContext(r.Context()).Info("Loading room type")
+			slog.DebugContext(r.Context(), "Hidden below the level")
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/hotel/h-1/rooms/r-1", nil)
+		req.Header.Set(shared.RequestIDHeader, "req-1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		entries := lines(t)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "Loading room type", entries[0]["msg"])
+		assert.Equal(t, "Request served", entries[1]["msg"])
+		assert.EqualValues(t, http.StatusTeapot, entries[1]["status"])
+		for _, entry := range entries {
+			assert.Equal(t, "req-1", entry["request_id"])
+			assert.Equal(t, "/hotel/{hotelId}/rooms/{id}", entry["route"])
+			assert.Equal(t, "h-1", entry["hotel_id"])
+			assert.Equal(t, "u-1", entry["user_id"])
+		}
+		assert.NotContains(t, entries[1], "path")
+	})
+
+	t.Run("should_redact_secrets_and_emails", func(t *testing.T) {
+		slog.Info("Login failed for guest@example.com",
+			"password", "hunter2",
+			"Authorization", "Bearer abc",
+			"error", errors.New(`user "guest@example.com" not found`))
+
+		entries := lines(t)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Login failed for ***@example.com", entries[0]["msg"])
+		assert.Equal(t, "[REDACTED]", entries[0]["password"])
+		assert.Equal(t, "[REDACTED]", entries[0]["Authorization"])
+		assert.Equal(t, `user "***@example.com" not found`, entries[0]["error"])
+	})
+
+	t.Run("should_reject_unknown_settings", func(t *testing.T) {
+		_, err := logging.New(&buf, "xml", "info")
+		assert.Error(t, err)
+
+		_, err = logging.New(&buf, logging.FormatText, "verbose")
+		assert.Error(t, err)
+	})
+}
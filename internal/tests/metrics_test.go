@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	scrape := func(t *testing.T, collectors ...prometheus.Collector) string {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		metrics.Handler(collectors...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	t.Run("should_label_requests_by_route_pattern", func(t *testing.T) {
+		t.Parallel()
+
+		r := chi.NewRouter()
+		r.Use(metrics.Instrument)
+		r.Route("/metrics-test", func(r chi.Router) {
+			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+		})
+
+		for _, path := range []string{"/metrics-test/1", "/metrics-test/2"} {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		body := scrape(t)
+		assert.Contains(t, body, `hotel_http_request_duration_seconds_count{method="GET",route="/metrics-test/{id}",status="418"} 2`)
+		assert.NotContains(t, body, `route="/metrics-test/1"`)
+	})
+
+	t.Run("should_expose_booking_counters_and_caller_collectors", func(t *testing.T) {
+		t.Parallel()
+
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "metrics_test_pool_open"})
+		gauge.Set(3)
+
+		body := scrape(t, gauge)
+		assert.Contains(t, body, "hotel_inventory_lock_conflicts_total")
+		assert.Contains(t, body, "hotel_reservation_created_total")
+		assert.Contains(t, body, "metrics_test_pool_open 3")
+	})
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/migrate"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// newerSchemaVersion stands for a migration of a build newer than this one.
+const newerSchemaVersion = 99991231000000
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	suite := GetTestSuite()
+	ctx := context.Background()
+
+	migrator, err := migrate.New(suite.GetDB().GetDB())
+	require.NoError(t, err)
+
+	// Not parallel: the unknown version it records would fail the other subtests' checks.
+	t.Run("should_start_on_newer_schema", func(t *testing.T) {
+		db := suite.GetDB().GetDB()
+		_, err := db.ExecContext(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)`, newerSchemaVersion)
+		require.NoError(t, err)
+		defer func() {
+			_, err := db.ExecContext(ctx, `DELETE FROM goose_db_version WHERE version_id = $1`, newerSchemaVersion)
+			require.NoError(t, err)
+		}()
+
+		assert.ErrorIs(t, migrator.Check(ctx), migrate.ErrSchemaAhead)
+		assert.NoError(t, migrator.Ensure(ctx, false))
+		assert.NoError(t, migrator.Ensure(ctx, true))
+	})
+
+	t.Run("should_accept_migrated_schema", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, migrator.Check(ctx))
+		require.NoError(t, migrator.Ensure(ctx, false))
+
+		migrations, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, migrations)
+		for _, m := range migrations {
+			assert.Equal(t, goose.StateApplied, m.State, m.Name)
+			assert.NotNil(t, m.AppliedAt, m.Name)
+		}
+	})
+
+	t.Run("should_let_concurrent_runs_apply_nothing_twice", func(t *testing.T) {
+		t.Parallel()
+
+		var g errgroup.Group
+		for range 3 {
+			g.Go(func() error {
+				applied, err := migrator.Up(ctx)
+				assert.Empty(t, applied)
+				return err
+			})
+		}
+		require.NoError(t, g.Wait())
+	})
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/ical"
+	"github.com/AlexKhomenko00/hotel-system/internal/notification"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSender struct{}
+
+func (failingSender) Send(context.Context, notification.Message) error {
+	return errors.New("smtp: connection refused")
+}
+
+var notificationSuite *TestSuite
+
+func init() {
+	notificationSuite = GetTestSuite()
+}
+
+func createNotificationTestReservation(t *testing.T, locale string) database.BookingReservation {
+	t.Helper()
+
+	hotel, err := notificationSuite.CreateTestHotel()
+	require.NoError(t, err)
+
+	roomType, err := notificationSuite.CreateTestRoomType(hotel.ID)
+	require.NoError(t, err)
+
+	guest, err := notificationSuite.CreateTestGuest()
+	require.NoError(t, err)
+
+	_, err = notificationSuite.GetDB().GetDB().Exec(`UPDATE booking.guests SET locale = $1 WHERE id = $2`, locale, guest.ID)
+	require.NoError(t, err)
+
+	startDate := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	res, err := notificationSuite.CreateTestReservation(guest.ID, roomType.ID, hotel.ID, startDate, startDate.AddDate(0, 0, 1))
+	require.NoError(t, err)
+
+	return res
+}
+
+func reservationEvent(t *testing.T, eventType events.Type, res database.BookingReservation, status string) events.Event {
+	t.Helper()
+
+	payload, err := json.Marshal(events.ReservationPayload{
+		ReservationID: res.ID,
+		HotelID:       res.HotelID,
+		RoomTypeID:    res.RoomTypeID,
+		GuestID:       res.GuestID,
+		StartDate:     shared.Date(res.StartDate),
+		EndDate:       shared.Date(res.EndDate),
+		Status:        status,
+	})
+	require.NoError(t, err)
+
+	return events.Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: events.AggregateReservation,
+		AggregateID:   res.ID,
+		Payload:       payload,
+		OccurredAt:    time.Now().UTC(),
+	}
+}
+
+// Subtests run sequentially, SendDue claims every pending notification in the shared database.
+func TestNotifications(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should_send_confirmation_with_calendar_once", func(t *testing.T) {
+		sender := notification.NewFileSender(t.TempDir(), "reservations@hotel-system.test")
+		svc := notification.New(notificationSuite.GetQueries(), sender)
+
+		res := createNotificationTestReservation(t, "en")
+		e := reservationEvent(t, events.ReservationCreated, res, "pending")
+
+		require.NoError(t, svc.HandleEvent(ctx, e))
+		// The relay delivers at-least-once, a redelivered event must not queue a second email.
+		require.NoError(t, svc.HandleEvent(ctx, e))
+
+		_, err := svc.SendDue(ctx)
+		require.NoError(t, err)
+
+		sent, err := notificationSuite.GetQueries().ListReservationNotifications(ctx, res.ID)
+		require.NoError(t, err)
+		require.Len(t, sent, 1)
+
+		n := sent[0]
+		assert.Equal(t, string(notification.KindBookingConfirmation), n.Kind)
+		assert.Equal(t, string(notification.StatusSent), n.Status)
+		assert.Equal(t, int32(1), n.Attempts)
+		assert.True(t, n.SentAt.Valid)
+		assert.Contains(t, n.Calendar, "BEGIN:VCALENDAR")
+		assert.Contains(t, n.Calendar, "METHOD:REQUEST")
+		assert.Contains(t, n.Calendar, "UID:"+ical.ReservationUID(res.ID))
+		assert.Contains(t, n.Calendar, "DTSTART;VALUE=DATE:"+res.StartDate.Format("20060102"))
+		assert.Contains(t, n.Calendar, "DTEND;VALUE=DATE:"+res.EndDate.AddDate(0, 0, 1).Format("20060102"))
+
+		eml, err := os.ReadFile(sender.Path(n.ID))
+		require.NoError(t, err)
+		assert.Contains(t, string(eml), "To: "+n.Recipient)
+		assert.Contains(t, string(eml), "text/calendar; charset=utf-8; method=REQUEST")
+		assert.Contains(t, string(eml), "text/html; charset=utf-8")
+	})
+
+	t.Run("should_skip_waitlist_holds", func(t *testing.T) {
+		svc := notification.New(notificationSuite.GetQueries(), notification.NewFileSender(t.TempDir(), "reservations@hotel-system.test"))
+
+		res := createNotificationTestReservation(t, "en")
+		require.NoError(t, svc.HandleEvent(ctx, reservationEvent(t, events.ReservationCreated, res, "held")))
+
+		// Expiring the hold cancels a reservation the guest never confirmed.
+		expired := reservationEvent(t, events.ReservationCancelled, res, "cancelled")
+		expired.Payload, _ = json.Marshal(events.ReservationPayload{ReservationID: res.ID, Status: "cancelled", PreviousStatus: "held"})
+		require.NoError(t, svc.HandleEvent(ctx, expired))
+
+		queued, err := notificationSuite.GetQueries().ListReservationNotifications(ctx, res.ID)
+		require.NoError(t, err)
+		assert.Empty(t, queued)
+	})
+
+	t.Run("should_render_cancellation_in_guest_locale", func(t *testing.T) {
+		svc := notification.New(notificationSuite.GetQueries(), notification.NewFileSender(t.TempDir(), "reservations@hotel-system.test"))
+
+		res := createNotificationTestReservation(t, "uk")
+		require.NoError(t, svc.HandleEvent(ctx, reservationEvent(t, events.ReservationCancelled, res, "cancelled")))
+
+		queued, err := notificationSuite.GetQueries().ListReservationNotifications(ctx, res.ID)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+
+		n := queued[0]
+		assert.Equal(t, string(notification.KindCancellation), n.Kind)
+		assert.Equal(t, "uk", n.Locale)
+		assert.Contains(t, n.Subject, "скасовано")
+		assert.Contains(t, n.Calendar, "METHOD:CANCEL")
+		assert.Contains(t, n.Calendar, "STATUS:CANCELLED")
+	})
+
+	t.Run("should_send_modification_as_calendar_update", func(t *testing.T) {
+		svc := notification.New(notificationSuite.GetQueries(), notification.NewFileSender(t.TempDir(), "reservations@hotel-system.test"))
+
+		res := createNotificationTestReservation(t, "en")
+		require.NoError(t, svc.HandleEvent(ctx, reservationEvent(t, events.ReservationModified, res, "pending")))
+
+		queued, err := notificationSuite.GetQueries().ListReservationNotifications(ctx, res.ID)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+
+		n := queued[0]
+		assert.Equal(t, string(notification.KindModification), n.Kind)
+		assert.Contains(t, n.Subject, "has changed")
+		assert.Contains(t, n.Calendar, "METHOD:REQUEST")
+		assert.Contains(t, n.Calendar, "UID:"+ical.ReservationUID(res.ID))
+	})
+
+	t.Run("should_fall_back_to_default_locale", func(t *testing.T) {
+		svc := notification.New(notificationSuite.GetQueries(), notification.NewFileSender(t.TempDir(), "reservations@hotel-system.test"))
+
+		res := createNotificationTestReservation(t, "fr")
+		require.NoError(t, svc.HandleEvent(ctx, reservationEvent(t, events.ReservationCreated, res, "pending")))
+
+		queued, err := notificationSuite.GetQueries().ListReservationNotifications(ctx, res.ID)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+		assert.Equal(t, "en", queued[0].Locale)
+	})
+
+	t.Run("should_record_failed_send_for_retry", func(t *testing.T) {
+		svc := notification.New(notificationSuite.GetQueries(), failingSender{})
+
+		res := createNotificationTestReservation(t, "en")
+		require.NoError(t, svc.HandleEvent(ctx, reservationEvent(t, events.ReservationCreated, res, "pending")))
+
+		_, err := svc.SendDue(ctx)
+		require.NoError(t, err)
+
+		queued, err := notificationSuite.GetQueries().ListReservationNotifications(ctx, res.ID)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+
+		n := queued[0]
+		assert.Equal(t, string(notification.StatusPending), n.Status)
+		assert.Equal(t, int32(1), n.Attempts)
+		assert.True(t, n.LastError.Valid)
+		assert.True(t, strings.Contains(n.LastError.String, "connection refused"))
+		assert.True(t, n.NextAttemptAt.After(time.Now().UTC()))
+	})
+
+	t.Run("should_queue_pre_arrival_reminder_once", func(t *testing.T) {
+		svc := notification.New(notificationSuite.GetQueries(), notification.NewFileSender(t.TempDir(), "reservations@hotel-system.test"))
+
+		res := createNotificationTestReservation(t, "en")
+		_, err := notificationSuite.GetDB().GetDB().Exec(`UPDATE booking.reservations SET status = 'pending' WHERE id = $1`, res.ID)
+		require.NoError(t, err)
+
+		svc.QueueReminders(ctx)
+		svc.QueueReminders(ctx)
+
+		queued, err := notificationSuite.GetQueries().ListReservationNotifications(ctx, res.ID)
+		require.NoError(t, err)
+		require.Len(t, queued, 1)
+		assert.Equal(t, string(notification.KindPreArrivalReminder), queued[0].Kind)
+	})
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
+	"github.com/AlexKhomenko00/hotel-system/internal/openapi"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDocument() *openapi.Document {
+	doc := openapi.New("Test API", "1.0.0")
+	doc.Add(openapi.Operation{
+		ID: "createHotel", Method: http.MethodPost, Path: "/hotel/", Tag: "hotels",
+		Body:      hotel.CreateHotelBody{},
+		Responses: []openapi.Response{{Status: http.StatusCreated, Body: shared.Envelope{"hotel": database.BookingHotel{}}}},
+	})
+	doc.Add(openapi.Operation{
+		ID: "getHotelReport", Method: http.MethodGet, Path: "/hotel/{id}/report", Tag: "hotels",
+		Query:     []openapi.Param{{Name: "from", Required: true, Schema: openapi.Date()}},
+		Responses: []openapi.Response{{Status: http.StatusOK, Body: shared.Envelope{"report": ""}}},
+	})
+	return doc
+}
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	echoBody := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}
+
+	doc := newTestDocument()
+	r := chi.NewRouter()
+	r.Use(doc.ValidateRequests)
+	r.Route("/hotel", func(r chi.Router) {
+		r.Post("/", echoBody)
+		r.Get("/{id}/report", echoBody)
+	})
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	t.Run("should_match_registered_routes", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, doc.CheckRoutes(r))
+
+		extra := chi.NewRouter()
+		extra.Mount("/", r)
+		extra.Delete("/hotel/{id}", echoBody)
+		err := doc.CheckRoutes(extra)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "undocumented routes: DELETE /hotel/{id}")
+	})
+
+	t.Run("should_describe_handler_types", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		doc.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var spec struct {
+			Paths      map[string]map[string]json.RawMessage `json:"paths"`
+			Components struct {
+				Schemas map[string]struct {
+					Properties map[string]json.RawMessage `json:"properties"`
+					Required   []string                   `json:"required"`
+				} `json:"schemas"`
+			} `json:"components"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+
+		assert.Contains(t, spec.Paths, "/hotel")
+		assert.Contains(t, spec.Paths["/hotel/{id}/report"], "get")
+
+		body := spec.Components.Schemas["CreateHotelBody"]
+		assert.Contains(t, body.Properties, "timeZone")
+		assert.Equal(t, []string{"location", "name"}, body.Required)
+		assert.Contains(t, spec.Components.Schemas["BookingHotel"].Properties, "time_zone")
+	})
+
+	t.Run("should_reject_invalid_body", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodPost, "/hotel/", `{"name": "Grand"}`)
+		problem := decodeProblem(t, rec, http.StatusBadRequest)
+		assert.Equal(t, shared.CodeValidationFailed, problem.Code)
+		assert.Equal(t, []shared.FieldError{{Field: "location", Code: "required", Message: "is required"}}, problem.Errors)
+
+		rec = serve(http.MethodPost, "/hotel/", `{"name": 5, "location": "Kyiv"}`)
+		problem = decodeProblem(t, rec, http.StatusBadRequest)
+		assert.Equal(t, []shared.FieldError{{Field: "name", Code: "type", Message: "must be a string"}}, problem.Errors)
+
+		rec = serve(http.MethodPost, "/hotel/", `{"name": "Grand"`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("should_pass_valid_body_to_handler", func(t *testing.T) {
+		t.Parallel()
+
+		body := `{"name": "Grand", "location": "Kyiv", "unknown": true}`
+		rec := serve(http.MethodPost, "/hotel/", body)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("should_validate_parameters", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodGet, "/hotel/not-a-uuid/report?from=2026-01-01", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Invalid path parameter id")
+
+		rec = serve(http.MethodGet, "/hotel/"+uuid.NewString()+"/report", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Missing query parameter from")
+
+		rec = serve(http.MethodGet, "/hotel/"+uuid.NewString()+"/report?from=01.01.2026", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Invalid query parameter from")
+
+		rec = serve(http.MethodGet, "/hotel/"+uuid.NewString()+"/report?from=2026-01-01", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxEvents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_relay_events_written_with_reservation", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := reservationSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+		ctx := context.Background()
+		_, err = reservationSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate},
+			TotalInventory: TestInventoryMax,
+		})
+		require.NoError(t, err)
+
+		reservationID := uuid.New()
+		resp, err := reservationSuite.MakeAuthenticatedRequest("POST", "/reservation", reservation.MakeReservationBody{
+			StartDate:     startDate,
+			EndDate:       startDate,
+			HotelID:       hotel.ID,
+			RoomTypeID:    roomType.ID,
+			ReservationId: reservationID.String(),
+		}, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var mu sync.Mutex
+		received := map[events.Type]events.Event{}
+
+		bus := events.NewInMemoryBus()
+		bus.Subscribe("test", func(ctx context.Context, e events.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if e.AggregateID == reservationID || e.AggregateID == roomType.ID {
+				received[e.Type] = e
+			}
+			return nil
+		})
+
+		relay := events.NewRelay(reservationSuite.GetQueries(), reservationSuite.GetDB(), bus)
+		_, err = relay.Flush(ctx)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Contains(t, received, events.ReservationCreated)
+		assert.Contains(t, received, events.InventoryChanged)
+
+		var unpublished int
+		err = reservationSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM booking.outbox_events
+			WHERE aggregate_id = $1 AND published_at IS NULL
+		`, reservationID).Scan(&unpublished)
+		require.NoError(t, err)
+		assert.Equal(t, 0, unpublished)
+	})
+
+	// Not parallel: a relay of another subtest would publish the event with handlers that succeed.
+	t.Run("should_keep_events_whose_handlers_failed", func(t *testing.T) {
+		ctx := context.Background()
+		aggregateID := uuid.New()
+
+		err := events.Record(ctx, reservationSuite.GetQueries(), events.ReservationCancelled, events.AggregateReservation, aggregateID, struct{}{})
+		require.NoError(t, err)
+
+		bus := events.NewInMemoryBus()
+		bus.Subscribe("test", func(ctx context.Context, e events.Event) error {
+			if e.AggregateID == aggregateID {
+				return errors.New("channel unavailable")
+			}
+			return nil
+		})
+
+		relay := events.NewRelay(reservationSuite.GetQueries(), reservationSuite.GetDB(), bus)
+		_, err = relay.Flush(ctx)
+		require.NoError(t, err)
+
+		var attempts int
+		var published bool
+		err = reservationSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT attempts, published_at IS NOT NULL FROM booking.outbox_events WHERE aggregate_id = $1
+		`, aggregateID).Scan(&attempts, &published)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+		assert.False(t, published)
+	})
+
+	// Not parallel, for the same reason as above.
+	t.Run("should_retry_only_failed_subscribers_after_backoff", func(t *testing.T) {
+		ctx := context.Background()
+		aggregateID := uuid.New()
+
+		err := events.Record(ctx, reservationSuite.GetQueries(), events.ReservationCancelled, events.AggregateReservation, aggregateID, struct{}{})
+		require.NoError(t, err)
+
+		calls := map[string]int{}
+		channelDown := true
+		bus := events.NewInMemoryBus()
+		bus.Subscribe("mail", func(ctx context.Context, e events.Event) error {
+			if e.AggregateID == aggregateID {
+				calls["mail"]++
+			}
+			return nil
+		})
+		bus.Subscribe("channel", func(ctx context.Context, e events.Event) error {
+			if e.AggregateID != aggregateID {
+				return nil
+			}
+			calls["channel"]++
+			if channelDown {
+				return errors.New("channel unavailable")
+			}
+			return nil
+		})
+
+		relay := events.NewRelay(reservationSuite.GetQueries(), reservationSuite.GetDB(), bus)
+		_, err = relay.Flush(ctx)
+		require.NoError(t, err)
+
+		// The failed event backs off instead of being claimed again by the next flush.
+		_, err = relay.Flush(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"mail": 1, "channel": 1}, calls)
+
+		_, err = reservationSuite.GetDB().GetDB().ExecContext(ctx, `
+			UPDATE booking.outbox_events SET next_attempt_at = CURRENT_TIMESTAMP WHERE aggregate_id = $1
+		`, aggregateID)
+		require.NoError(t, err)
+
+		channelDown = false
+		_, err = relay.Flush(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"mail": 1, "channel": 2}, calls)
+
+		var published bool
+		err = reservationSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT published_at IS NOT NULL FROM booking.outbox_events WHERE aggregate_id = $1
+		`, aggregateID).Scan(&published)
+		require.NoError(t, err)
+		assert.True(t, published)
+	})
+
+	t.Run("should_listen_again_after_connection_is_killed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		db := reservationSuite.GetDB()
+		listener := events.NewListener(db.Pool())
+		go listener.Run(ctx)
+
+		listenerPID := func() int {
+			var pid int
+			err := db.GetDB().QueryRowContext(ctx, `
+				SELECT COALESCE(MAX(pid), 0) FROM pg_stat_activity WHERE query = 'LISTEN ' || $1
+			`, events.NotifyChannel).Scan(&pid)
+			require.NoError(t, err)
+			return pid
+		}
+
+		var killed int
+		require.Eventually(t, func() bool {
+			killed = listenerPID()
+			return killed != 0
+		}, 10*time.Second, 50*time.Millisecond)
+
+		_, err := db.GetDB().ExecContext(ctx, "SELECT pg_terminate_backend($1)", killed)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			pid := listenerPID()
+			return pid != 0 && pid != killed
+		}, 10*time.Second, 50*time.Millisecond)
+
+		// Drain the wake-ups so far, the next one comes from the event committed below.
+		for len(listener.Wake()) > 0 {
+			<-listener.Wake()
+		}
+		err = events.Record(ctx, reservationSuite.GetQueries(), events.ReservationCancelled, events.AggregateReservation, uuid.New(), struct{}{})
+		require.NoError(t, err)
+
+		select {
+		case <-listener.Wake():
+		case <-time.After(5 * time.Second):
+			t.Fatal("listener wasn't woken by the committed event")
+		}
+	})
+}
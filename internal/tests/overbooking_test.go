@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/overbooking"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type OverbookingReportResponse struct {
+	Report overbooking.OverbookedNightsReport `json:"report"`
+}
+
+var overbookingSuite *TestSuite
+
+func init() {
+	overbookingSuite = GetTestSuite()
+	overbookingSvc := overbookingSuite.GetOverbookingService()
+	authSvc := overbookingSuite.GetAuthService()
+	overbookingSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Use(authSvc.RequireHotelAdmin("hotelId"))
+		overbookingSvc.RegisterHotelHandlers(r)
+	}, "/hotel/{hotelId}/overbooking")
+}
+
+func TestOverbookingPolicies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_apply_room_type_policy_over_default", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := overbookingSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := overbookingSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := overbookingSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		err = overbookingSuite.GrantTestRole(user.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: hotel.ID, Valid: true})
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+		ctx := context.Background()
+		_, err = overbookingSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate},
+			TotalInventory: TestInventorySingle,
+		})
+		require.NoError(t, err)
+
+		makeReservation := func() int {
+			body := reservation.MakeReservationBody{
+				StartDate:     startDate,
+				EndDate:       startDate,
+				HotelID:       hotel.ID,
+				RoomTypeID:    roomType.ID,
+				ReservationId: uuid.New().String(),
+			}
+			resp, err := overbookingSuite.MakeAuthenticatedRequest("POST", "/reservation", body, user)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		// The default factor of 1.2 still rounds down to a single room.
+		assert.Equal(t, http.StatusCreated, makeReservation())
+		assert.Equal(t, http.StatusConflict, makeReservation())
+
+		roomTypeID := roomType.ID
+		policy := overbooking.PolicyBody{RoomTypeID: &roomTypeID, Factor: 2}
+		resp, err := overbookingSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/overbooking/policies", hotel.ID), policy, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		assert.Equal(t, http.StatusCreated, makeReservation())
+
+		reportURL := fmt.Sprintf("/hotel/%s/overbooking/report?from=%s&to=%s", hotel.ID, startDate.Format(time.DateOnly), startDate.Format(time.DateOnly))
+		reportResp, err := overbookingSuite.MakeAuthenticatedRequest("GET", reportURL, nil, user)
+		require.NoError(t, err)
+		defer reportResp.Body.Close()
+		require.Equal(t, http.StatusOK, reportResp.StatusCode)
+
+		var report OverbookingReportResponse
+		require.NoError(t, json.NewDecoder(reportResp.Body).Decode(&report))
+		assert.Equal(t, int32(1), report.Report.TotalOverbooked)
+		require.Len(t, report.Report.Nights, 1)
+		assert.Equal(t, roomType.ID, report.Report.Nights[0].RoomTypeID)
+	})
+
+	t.Run("should_reject_factor_below_one", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := overbookingSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		user, err := overbookingSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		err = overbookingSuite.GrantTestRole(user.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: hotel.ID, Valid: true})
+		require.NoError(t, err)
+
+		resp, err := overbookingSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/overbooking/policies", hotel.ID), overbooking.PolicyBody{Factor: 0.5}, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder, status int) shared.Problem {
+	t.Helper()
+
+	require.Equal(t, status, rec.Code, rec.Body.String())
+	assert.Equal(t, shared.ProblemContentType, rec.Header().Get("Content-Type"))
+
+	var problem shared.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, status, problem.Status)
+	assert.Equal(t, "urn:hotel-system:problem:"+problem.Code, problem.Type)
+	return problem
+}
+
+func TestProblemDetails(t *testing.T) {
+	t.Parallel()
+	suite := GetTestSuite()
+
+	t.Run("should_translate_validation_errors_to_fields", func(t *testing.T) {
+		t.Parallel()
+
+		hotelSvc := hotel.New(suite.GetQueries(), suite.GetValidator())
+		handler := shared.RequestID(http.HandlerFunc(hotelSvc.CreateHotelHandler))
+
+		req := httptest.NewRequest(http.MethodPost, "/hotel/", strings.NewReader(`{"name": "Grand", "timeZone": "Mars/Olympus"}`))
+		req.Header.Set(shared.RequestIDHeader, "req-123")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		problem := decodeProblem(t, rec, http.StatusBadRequest)
+		assert.Equal(t, shared.CodeValidationFailed, problem.Code)
+		assert.Equal(t, "req-123", problem.RequestID)
+		assert.Equal(t, "req-123", rec.Header().Get(shared.RequestIDHeader))
+		assert.ElementsMatch(t, []shared.FieldError{
+			{Field: "location", Code: "required", Message: "is required"},
+			{Field: "timeZone", Code: "timezone", Message: "must be a valid timezone"},
+		}, problem.Errors)
+	})
+
+	t.Run("should_map_domain_errors_to_codes", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		shared.WriteDomainError(rec, fmt.Errorf("failed to insert reservation: %w", reservation.ErrDuplicateReservation))
+
+		problem := decodeProblem(t, rec, http.StatusConflict)
+		assert.Equal(t, "reservation.duplicate_id", problem.Code)
+		assert.Equal(t, "Reservation ID already exists", problem.Detail)
+
+		rec = httptest.NewRecorder()
+		shared.WriteDomainError(rec, reservation.ErrInventoryCapacityReached)
+		assert.Equal(t, "inventory.capacity_reached", decodeProblem(t, rec, http.StatusConflict).Code)
+	})
+
+	t.Run("should_hide_unknown_errors", func(t *testing.T) {
+		t.Parallel()
+
+		handler := shared.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			shared.WriteDomainError(w, errors.New("pq: connection refused"))
+		}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		problem := decodeProblem(t, rec, http.StatusInternalServerError)
+		assert.Equal(t, shared.CodeInternal, problem.Code)
+		assert.NotContains(t, problem.Detail, "pq")
+		assert.NotEmpty(t, problem.RequestID)
+	})
+
+	t.Run("should_answer_unauthenticated_requests_with_problem", func(t *testing.T) {
+		t.Parallel()
+
+		resp, err := suite.MakeRequest(http.MethodGet, "/verify", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		var problem shared.Problem
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+		assert.Equal(t, "auth.invalid_token", problem.Code)
+		assert.NotEmpty(t, problem.RequestID)
+	})
+}
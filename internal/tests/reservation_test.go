@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
 	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -33,12 +34,19 @@ type GetAvailabilityResponse struct {
 	Availability []interface{} `json:"availability"`
 }
 
-var reservationSuite *TestSuite
+type GetCalendarResponse struct {
+	Calendar reservation.AvailabilityCalendar `json:"calendar"`
+}
+
+var (
+	reservationSuite *TestSuite
+	reservationSvc   *reservation.ReservationService
+)
 
 func init() {
 	reservationSuite = GetTestSuite()
 	config := reservationSuite.GetConfig()
-	reservationSvc := reservation.New(reservationSuite.GetQueries(), reservationSuite.GetValidator(), &config, reservationSuite.GetDB())
+	reservationSvc = reservation.New(reservationSuite.GetQueries(), reservationSuite.GetValidator(), &config, reservationSuite.GetDB(), reservationSuite.GetOverbookingService())
 	reservationSuite.RegisterPrivateHandlers(reservationSvc.RegisterHandlers, "/reservation")
 }
 
@@ -356,6 +364,88 @@ func TestReservationBusinessLogic(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 	})
 
+	t.Run("should_move_reservation_to_new_dates", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := reservationSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		day1 := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+		day2 := day1.AddDate(0, 0, 1)
+		day3 := day1.AddDate(0, 0, 2)
+
+		ctx := context.Background()
+		_, err = reservationSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{day1, day2, day3},
+			TotalInventory: TestInventorySingle,
+		})
+		require.NoError(t, err)
+
+		reservationID := uuid.New()
+		resp, err := reservationSuite.MakeAuthenticatedRequest("POST", "/reservation", reservation.MakeReservationBody{
+			StartDate:     day1,
+			EndDate:       day2,
+			HotelID:       hotel.ID,
+			RoomTypeID:    roomType.ID,
+			ReservationId: reservationID.String(),
+		}, user)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		// The new stay overlaps the old one on a room type with a single room.
+		modifyURL := fmt.Sprintf("/reservation/%s/modify", reservationID)
+		modifyResp, err := reservationSuite.MakeAuthenticatedRequest("POST", modifyURL, reservation.ModifyReservationBody{
+			StartDate: day2,
+			EndDate:   day3,
+		}, user)
+		require.NoError(t, err)
+		defer modifyResp.Body.Close()
+		require.Equal(t, http.StatusOK, modifyResp.StatusCode)
+
+		for date, expected := range map[time.Time]int32{day1: 0, day2: 1, day3: 1} {
+			var totalReserved int32
+			err = reservationSuite.GetDB().GetDB().QueryRowContext(ctx, `
+				SELECT total_reserved FROM booking.room_type_inventory
+				WHERE hotel_id = $1 AND room_type_id = $2 AND date = $3
+			`, hotel.ID, roomType.ID, date).Scan(&totalReserved)
+			require.NoError(t, err)
+			assert.Equal(t, expected, totalReserved, "total reserved on %s", date.Format(time.DateOnly))
+		}
+
+		modified, err := reservationSuite.GetQueries().GetReservationById(ctx, reservationID)
+		require.NoError(t, err)
+		assert.Equal(t, day2.Format(time.DateOnly), modified.StartDate.Format(time.DateOnly))
+		assert.Equal(t, day3.Format(time.DateOnly), modified.EndDate.Format(time.DateOnly))
+		assert.Equal(t, int32(1), modified.Revision)
+
+		var modifiedEvents int
+		err = reservationSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM booking.outbox_events
+			WHERE aggregate_id = $1 AND event_type = $2
+		`, reservationID, events.ReservationModified).Scan(&modifiedEvents)
+		require.NoError(t, err)
+		assert.Equal(t, 1, modifiedEvents)
+
+		otherUser, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+		otherResp, err := reservationSuite.MakeAuthenticatedRequest("POST", modifyURL, reservation.ModifyReservationBody{
+			StartDate: day1,
+			EndDate:   day1,
+		}, otherUser)
+		require.NoError(t, err)
+		defer otherResp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, otherResp.StatusCode)
+	})
+
 	t.Run("should_validate_reservation_dates", func(t *testing.T) {
 		t.Parallel()
 
@@ -568,6 +658,114 @@ func TestRoomAvailability(t *testing.T) {
 	})
 }
 
+func TestAvailabilityCalendar(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_flag_sold_out_nights_and_unbookable_stay", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := reservationSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+		endDate := startDate.AddDate(0, 0, 2)
+		soldOutDate := startDate.AddDate(0, 0, 1)
+
+		ctx := context.Background()
+		_, err = reservationSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate, soldOutDate, endDate},
+			TotalInventory: TestInventoryMax,
+		})
+		require.NoError(t, err)
+		_, err = reservationSuite.GetDB().GetDB().ExecContext(ctx, `
+			UPDATE booking.room_type_inventory
+			SET total_reserved = $1
+			WHERE hotel_id = $2 AND room_type_id = $3 AND date = $4
+		`, TestInventoryOverbooked, hotel.ID, roomType.ID, soldOutDate)
+		require.NoError(t, err)
+
+		url := fmt.Sprintf("/reservation/availability/calendar?hotelId=%s&checkIn=%s&checkOut=%s",
+			hotel.ID.String(),
+			startDate.Format("2006-01-02"),
+			endDate.Format("2006-01-02"))
+
+		resp, err := reservationSuite.MakeAuthenticatedRequest("GET", url, nil, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var calendarResp GetCalendarResponse
+		err = json.NewDecoder(resp.Body).Decode(&calendarResp)
+		require.NoError(t, err)
+		require.Len(t, calendarResp.Calendar.RoomTypes, 1)
+
+		rtCalendar := calendarResp.Calendar.RoomTypes[0]
+		assert.Equal(t, roomType.ID, rtCalendar.RoomTypeID)
+
+		for _, day := range rtCalendar.Days {
+			if time.Time(day.Date).Equal(soldOutDate) {
+				assert.True(t, day.SoldOut, "Night at capacity should be sold out")
+				assert.False(t, day.Bookable)
+			}
+			if time.Time(day.Date).Equal(startDate) {
+				assert.True(t, day.Bookable, "Night under capacity should be bookable")
+			}
+		}
+
+		require.NotNil(t, rtCalendar.Stay)
+		assert.False(t, rtCalendar.Stay.Bookable, "Stay spanning a sold out night should not be bookable")
+		assert.Equal(t, int32(0), rtCalendar.Stay.MinAvailable)
+		assert.Equal(t, 3, rtCalendar.Stay.Nights)
+	})
+
+	t.Run("should_reject_stays_over_the_limit", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		maxNights := reservationSuite.GetConfig().Booking.MaxStayNights
+		checkIn := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+		for nights, status := range map[int]int{maxNights: http.StatusOK, maxNights + 1: http.StatusBadRequest} {
+			url := fmt.Sprintf("/reservation/availability/calendar?hotelId=%s&checkIn=%s&checkOut=%s",
+				hotel.ID.String(),
+				checkIn.Format("2006-01-02"),
+				checkIn.AddDate(0, 0, nights-1).Format("2006-01-02"))
+
+			resp, err := reservationSuite.MakeAuthenticatedRequest("GET", url, nil, user)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, status, resp.StatusCode, "%d nights", nights)
+		}
+	})
+
+	t.Run("should_require_month_or_stay", func(t *testing.T) {
+		t.Parallel()
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		url := fmt.Sprintf("/reservation/availability/calendar?hotelId=%s", uuid.NewString())
+
+		resp, err := reservationSuite.MakeAuthenticatedRequest("GET", url, nil, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
 func TestReservationIdGeneration(t *testing.T) {
 	t.Parallel()
 
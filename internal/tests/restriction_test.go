@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/hotel"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var restrictionSuite *TestSuite
+
+func init() {
+	restrictionSuite = GetTestSuite()
+	hotelSvc := hotel.New(restrictionSuite.GetQueries(), restrictionSuite.GetValidator())
+	authSvc := restrictionSuite.GetAuthService()
+	restrictionSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Route("/{hotelId}/rooms/{id}/restrictions", func(r chi.Router) {
+			r.Use(authSvc.RequireHotelAdmin("hotelId"))
+			r.Get("/", hotelSvc.GetRoomTypeRestrictionsHandler)
+			r.Put("/", hotelSvc.SetRoomTypeRestrictionsHandler)
+			r.Delete("/", hotelSvc.ClearRoomTypeRestrictionsHandler)
+		})
+	}, "/hotel")
+}
+
+func TestStayRestrictions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_reject_stay_shorter_than_minimum", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := restrictionSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := restrictionSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := restrictionSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+		endDate := startDate.AddDate(0, 0, 1)
+
+		ctx := context.Background()
+		_, err = restrictionSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate, endDate},
+			TotalInventory: TestInventoryMax,
+		})
+		require.NoError(t, err)
+
+		_, err = restrictionSuite.GetQueries().UpsertRoomTypeRestrictions(ctx, database.UpsertRoomTypeRestrictionsParams{
+			HotelID:         hotel.ID,
+			RoomTypeID:      roomType.ID,
+			Dates:           []time.Time{startDate},
+			MinLengthOfStay: sql.NullInt32{Int32: 3, Valid: true},
+		})
+		require.NoError(t, err)
+
+		body := reservation.MakeReservationBody{
+			StartDate:     startDate,
+			EndDate:       endDate,
+			HotelID:       hotel.ID,
+			RoomTypeID:    roomType.ID,
+			ReservationId: uuid.New().String(),
+		}
+
+		resp, err := restrictionSuite.MakeAuthenticatedRequest("POST", "/reservation", body, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode, "Should fail due to minimum length of stay")
+	})
+
+	t.Run("should_check_closed_to_departure_on_the_day_after_the_last_night", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := restrictionSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := restrictionSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := restrictionSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+		closedDate := startDate.AddDate(0, 0, 1)
+
+		ctx := context.Background()
+		_, err = restrictionSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate, closedDate},
+			TotalInventory: TestInventoryMax,
+		})
+		require.NoError(t, err)
+
+		_, err = restrictionSuite.GetQueries().UpsertRoomTypeRestrictions(ctx, database.UpsertRoomTypeRestrictionsParams{
+			HotelID:           hotel.ID,
+			RoomTypeID:        roomType.ID,
+			Dates:             []time.Time{closedDate},
+			ClosedToDeparture: true,
+		})
+		require.NoError(t, err)
+
+		book := func(night time.Time) int {
+			resp, err := restrictionSuite.MakeAuthenticatedRequest("POST", "/reservation", reservation.MakeReservationBody{
+				StartDate:     night,
+				EndDate:       night,
+				HotelID:       hotel.ID,
+				RoomTypeID:    roomType.ID,
+				ReservationId: uuid.New().String(),
+			}, user)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		assert.Equal(t, http.StatusConflict, book(startDate), "Should fail: the guest leaves on the closed date")
+		assert.Equal(t, http.StatusCreated, book(closedDate), "Should pass: the guest sleeps on the closed date and leaves the day after")
+	})
+
+	t.Run("should_require_hotel_admin_to_manage_restrictions", func(t *testing.T) {
+		t.Parallel()
+
+		testHotel, err := restrictionSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := restrictionSuite.CreateTestRoomType(testHotel.ID)
+		require.NoError(t, err)
+
+		user, err := restrictionSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+		url := fmt.Sprintf("/hotel/%s/rooms/%s/restrictions", testHotel.ID, roomType.ID)
+		body := hotel.SetRestrictionsBody{
+			From:            shared.Date(startDate),
+			To:              shared.Date(startDate.AddDate(0, 0, 6)),
+			ClosedToArrival: true,
+		}
+
+		resp, err := restrictionSuite.MakeAuthenticatedRequest("PUT", url, body, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		err = restrictionSuite.GrantTestRole(user.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: testHotel.ID, Valid: true})
+		require.NoError(t, err)
+
+		resp2, err := restrictionSuite.MakeAuthenticatedRequest("PUT", url, body, user)
+		require.NoError(t, err)
+		defer resp2.Body.Close()
+		assert.Equal(t, http.StatusOK, resp2.StatusCode)
+
+		restrictions, err := restrictionSuite.GetQueries().GetRoomTypeRestrictionsForRange(context.Background(), database.GetRoomTypeRestrictionsForRangeParams{
+			HotelID:    testHotel.ID,
+			RoomTypeID: roomType.ID,
+			DateFrom:   startDate,
+			DateTo:     startDate.AddDate(0, 0, 6),
+		})
+		require.NoError(t, err)
+		assert.Len(t, restrictions, 7)
+		for _, r := range restrictions {
+			assert.True(t, r.ClosedToArrival)
+		}
+	})
+}
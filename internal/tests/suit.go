@@ -10,7 +10,6 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
-	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -19,12 +18,14 @@ import (
 	"github.com/AlexKhomenko00/hotel-system/internal/auth/jwt"
 	"github.com/AlexKhomenko00/hotel-system/internal/config"
 	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/logging"
+	"github.com/AlexKhomenko00/hotel-system/internal/migrate"
+	"github.com/AlexKhomenko00/hotel-system/internal/overbooking"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"github.com/pressly/goose/v3"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -37,19 +38,34 @@ var (
 
 func GetTestSuite() *TestSuite {
 	once.Do(func() {
-		validator := validator.New()
+		validator := shared.NewValidator()
 
 		testConfig := &config.Config{
-			JWTSecret:          "test-jwt-secret-key",
-			PORT:               "8080",
-			DB_HOST:            "localhost",
-			DB_PORT:            "5432",
-			DB_DATABASE:        "hotel_test",
-			DB_USERNAME:        "test",
-			DB_PASSWORD:        "test",
-			DB_SCHEMA:          "public",
-			DB_SSLMODE:         "disable",
-			OVERBOOKING_FACTOR: "1.2",
+			Server: config.Server{Port: 8080},
+			DB: config.DB{
+				Host:     "localhost",
+				Port:     5432,
+				Database: "hotel_test",
+				Username: "test",
+				Password: "test",
+				Schema:   "public",
+				SSLMode:  "disable",
+				// A statement timeout, so TestDatabase can check it reaches the connections of both pools.
+				StatementTimeout: 30 * time.Second,
+				MaxOpenConns:     20,
+				BatchMaxConns:    2,
+			},
+			Auth:    config.Auth{JWTSecret: "test-jwt-secret-key"},
+			Booking: config.Booking{OverbookingFactor: 1.2, MaxStayNights: 30},
+			Events:  config.Events{Wake: "poll"},
+			// Webhook receivers in tests are httptest servers on loopback.
+			Webhooks: config.Webhooks{AllowPrivateTargets: true},
+			// Each notification test uses its own FileSender, the shared config only has to validate.
+			Mail: config.Mail{
+				Sender:    "file",
+				From:      "reservations@hotel-system.test",
+				OutboxDir: os.TempDir(),
+			},
 		}
 
 		testSuite = &TestSuite{
@@ -66,17 +82,18 @@ func GetTestSuite() *TestSuite {
 }
 
 type TestSuite struct {
-	server      *httptest.Server
-	handler     http.Handler
-	db          database.Service
-	queries     *database.Queries
-	auth        jwt.Authenticator
-	config      *config.Config
-	validator   *validator.Validate
-	ctx         context.Context
-	pgContainer *postgres.PostgresContainer
-	r           *chi.Mux
-	authSvc     *auth.AuthService
+	server         *httptest.Server
+	handler        http.Handler
+	db             database.Service
+	queries        *database.Queries
+	auth           jwt.Authenticator
+	config         *config.Config
+	validator      *validator.Validate
+	ctx            context.Context
+	pgContainer    *postgres.PostgresContainer
+	r              *chi.Mux
+	authSvc        *auth.AuthService
+	overbookingSvc *overbooking.OverbookingService
 }
 
 func TestMain(m *testing.M) {
@@ -119,8 +136,8 @@ func (ts *TestSuite) SetupApplication() error {
 		return fmt.Errorf("failed to get container host: %w", err)
 	}
 
-	ts.config.DB_HOST = host
-	ts.config.DB_PORT = mappedPort.Port()
+	ts.config.DB.Host = host
+	ts.config.DB.Port = mappedPort.Int()
 
 	if err := ts.runMigrations(connStr); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
@@ -132,34 +149,29 @@ func (ts *TestSuite) SetupApplication() error {
 	}
 
 	ts.queries = database.New(ts.db.GetDB())
-	ts.auth = jwt.NewAuthenticator(ts.config.JWTSecret)
+	ts.auth = jwt.NewAuthenticator(ts.config.Auth.JWTSecret)
 
 	ts.handler = ts.createTestHandler()
 	ts.authSvc = auth.New(ts.queries, ts.validator, ts.config)
+	ts.overbookingSvc = overbooking.New(ts.queries, ts.validator, ts.config)
 
 	log.Printf("Test database connection: %s", connStr)
 	return nil
 }
 
 func (ts *TestSuite) runMigrations(connStr string) error {
-	projectRoot, err := filepath.Abs("../..")
-	if err != nil {
-		return fmt.Errorf("failed to get project root: %w", err)
-	}
-
-	migrationsPath := filepath.Join(projectRoot, "sql", "schema")
-
 	migrationDB, err := sql.Open("pgx", connStr)
 	if err != nil {
 		return fmt.Errorf("failed to open migration connection: %w", err)
 	}
 	defer migrationDB.Close()
 
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("failed to set goose dialect: %w", err)
+	migrator, err := migrate.New(migrationDB)
+	if err != nil {
+		return err
 	}
 
-	if err := goose.Up(migrationDB, migrationsPath); err != nil {
+	if _, err := migrator.Up(ts.ctx); err != nil {
 		return fmt.Errorf("failed to run goose migrations: %w", err)
 	}
 
@@ -203,14 +215,37 @@ func (ts *TestSuite) GetValidator() *validator.Validate {
 	return ts.validator
 }
 
+func (ts *TestSuite) GetAuthService() *auth.AuthService {
+	return ts.authSvc
+}
+
+func (ts *TestSuite) GetOverbookingService() *overbooking.OverbookingService {
+	return ts.overbookingSvc
+}
+
 func (ts *TestSuite) CleanupDatabase() error {
 	queries := []string{
+		"TRUNCATE TABLE booking.inventory_cron_run_errors CASCADE",
+		"TRUNCATE TABLE booking.inventory_cron_checkpoints CASCADE",
+		"TRUNCATE TABLE booking.inventory_cron_runs CASCADE",
+		"TRUNCATE TABLE booking.channel_reservations CASCADE",
+		"TRUNCATE TABLE booking.channel_room_mappings CASCADE",
+		"TRUNCATE TABLE booking.channels CASCADE",
+		"TRUNCATE TABLE booking.calendar_feeds CASCADE",
+		"TRUNCATE TABLE booking.guest_calendar_feeds CASCADE",
+		"TRUNCATE TABLE booking.notifications CASCADE",
+		"TRUNCATE TABLE booking.webhook_subscriptions CASCADE",
+		"TRUNCATE TABLE booking.outbox_events CASCADE",
+		"TRUNCATE TABLE booking.waitlist_entries CASCADE",
 		"TRUNCATE TABLE booking.reservations CASCADE",
+		"TRUNCATE TABLE booking.room_type_restrictions CASCADE",
+		"TRUNCATE TABLE booking.overbooking_policies CASCADE",
 		"TRUNCATE TABLE booking.room_type_inventory CASCADE",
 		"TRUNCATE TABLE booking.rooms CASCADE",
 		"TRUNCATE TABLE booking.room_types CASCADE",
 		"TRUNCATE TABLE booking.hotels CASCADE",
 		"TRUNCATE TABLE booking.guests CASCADE",
+		"TRUNCATE TABLE auth.user_roles CASCADE",
 		"TRUNCATE TABLE auth.users CASCADE",
 	}
 
@@ -254,6 +289,19 @@ func (ts *TestSuite) CreateTestUser() (database.AuthUser, error) {
 	return createdUser, nil
 }
 
+func (ts *TestSuite) GrantTestRole(userID uuid.UUID, role auth.Role, hotelID uuid.NullUUID) error {
+	err := ts.queries.GrantUserRole(ts.ctx, database.GrantUserRoleParams{
+		UserID:  userID,
+		Role:    string(role),
+		HotelID: hotelID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to grant test role: %w", err)
+	}
+
+	return nil
+}
+
 func (ts *TestSuite) CreateTestHotel() (database.BookingHotel, error) {
 	hotelID := uuid.New()
 	hotel := database.CreateHotelParams{
@@ -323,6 +371,7 @@ func (ts *TestSuite) CreateTestGuest() (database.BookingGuest, error) {
 		FirstName: "TestFirst",
 		LastName:  "TestLast",
 		Email:     fmt.Sprintf("guest_%s@example.com", guestID.String()[:8]),
+		Locale:    "en",
 	}, nil
 }
 
@@ -412,12 +461,14 @@ func (ts *TestSuite) CreateFullTestData() (*TestData, error) {
 
 func (ts *TestSuite) createTestHandler() http.Handler {
 	ts.r = chi.NewRouter()
-	ts.r.Use(middleware.Logger)
+	ts.r.Use(shared.RequestID)
+	ts.r.Use(logging.Middleware)
 
 	ts.r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", shared.RequestIDHeader},
+		ExposedHeaders:   []string{shared.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -435,6 +486,10 @@ func (ts *TestSuite) RegisterPrivateHandlers(registerHandlers func(r chi.Router)
 	})
 }
 
+func (ts *TestSuite) RegisterPublicHandlers(registerHandlers func(r chi.Router)) {
+	ts.r.Group(registerHandlers)
+}
+
 func (ts *TestSuite) GetHandler() http.Handler {
 	return ts.handler
 }
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	_, err := tracing.Setup(context.Background(), tracing.ExporterNone, "test")
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	logger := slog.New(tracing.LogHandler(slog.NewJSONHandler(&logs, nil)))
+
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Get("/tracing-test/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.Start(r.Context(), "inventory.update_date")
+		logger.InfoContext(ctx, "updating")
+		tracing.End(span, nil)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/tracing-test/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	server, ok := spans["GET /tracing-test/{id}"]
+	require.True(t, ok, "server span should be named after the route pattern")
+	assert.Equal(t, traceID, server.SpanContext().TraceID().String())
+
+	child := spans["inventory.update_date"]
+	require.NotNil(t, child)
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+
+	assert.Contains(t, logs.String(), `"trace_id":"`+traceID+`"`)
+	assert.Contains(t, logs.String(), `"span_id":"`+child.SpanContext().SpanID().String()+`"`)
+}
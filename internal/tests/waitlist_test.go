@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type WaitlistEntryResponse struct {
+	WaitlistEntry database.BookingWaitlistEntry `json:"waitlist_entry"`
+}
+
+func TestWaitlist(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should_offer_cancelled_inventory_to_waitlisted_guest", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := reservationSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user1, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		user2, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+		endDate := startDate.AddDate(0, 0, 1)
+
+		ctx := context.Background()
+		_, err = reservationSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate, endDate},
+			TotalInventory: TestInventorySingle,
+		})
+		require.NoError(t, err)
+
+		reservationID := uuid.New()
+		resp1, err := reservationSuite.MakeAuthenticatedRequest("POST", "/reservation", reservation.MakeReservationBody{
+			StartDate:     startDate,
+			EndDate:       endDate,
+			HotelID:       hotel.ID,
+			RoomTypeID:    roomType.ID,
+			ReservationId: reservationID.String(),
+		}, user1)
+		require.NoError(t, err)
+		defer resp1.Body.Close()
+		require.Equal(t, http.StatusCreated, resp1.StatusCode)
+
+		joinResp, err := reservationSuite.MakeAuthenticatedRequest("POST", "/reservation/waitlist", reservation.JoinWaitlistBody{
+			StartDate:  startDate,
+			EndDate:    endDate,
+			HotelID:    hotel.ID,
+			RoomTypeID: roomType.ID,
+		}, user2)
+		require.NoError(t, err)
+		defer joinResp.Body.Close()
+		require.Equal(t, http.StatusCreated, joinResp.StatusCode)
+
+		var joined WaitlistEntryResponse
+		require.NoError(t, json.NewDecoder(joinResp.Body).Decode(&joined))
+		assert.Equal(t, string(reservation.WaitlistStatusWaiting), joined.WaitlistEntry.Status)
+
+		cancelResp, err := reservationSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/reservation/%s/cancel", reservationID), nil, user1)
+		require.NoError(t, err)
+		defer cancelResp.Body.Close()
+		require.Equal(t, http.StatusOK, cancelResp.StatusCode)
+
+		// Other tests flush the shared outbox, so hand the cancellation to the reservation service directly.
+		var e events.Event
+		err = reservationSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT id, event_type, aggregate_type, aggregate_id, payload, created_at
+			FROM booking.outbox_events
+			WHERE aggregate_id = $1 AND event_type = $2
+		`, reservationID, events.ReservationCancelled).Scan(&e.ID, &e.Type, &e.AggregateType, &e.AggregateID, &e.Payload, &e.OccurredAt)
+		require.NoError(t, err)
+		require.NoError(t, reservationSvc.HandleEvent(ctx, e))
+
+		entryURL := fmt.Sprintf("/reservation/waitlist/%s", joined.WaitlistEntry.ID)
+		entryResp, err := reservationSuite.MakeAuthenticatedRequest("GET", entryURL, nil, user2)
+		require.NoError(t, err)
+		defer entryResp.Body.Close()
+
+		var offered WaitlistEntryResponse
+		require.NoError(t, json.NewDecoder(entryResp.Body).Decode(&offered))
+		assert.Equal(t, string(reservation.WaitlistStatusOffered), offered.WaitlistEntry.Status)
+		require.True(t, offered.WaitlistEntry.ReservationID.Valid)
+
+		confirmResp, err := reservationSuite.MakeAuthenticatedRequest("POST", entryURL+"/confirm", nil, user2)
+		require.NoError(t, err)
+		defer confirmResp.Body.Close()
+		assert.Equal(t, http.StatusOK, confirmResp.StatusCode)
+
+		held, err := reservationSuite.GetQueries().GetReservationById(ctx, offered.WaitlistEntry.ReservationID.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, string(reservation.ReservationStatusPending), held.Status)
+	})
+
+	t.Run("should_reject_unknown_room_type", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		otherHotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		foreignRoomType, err := reservationSuite.CreateTestRoomType(otherHotel.ID)
+		require.NoError(t, err)
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+		for _, roomTypeID := range []uuid.UUID{uuid.New(), foreignRoomType.ID} {
+			resp, err := reservationSuite.MakeAuthenticatedRequest("POST", "/reservation/waitlist", reservation.JoinWaitlistBody{
+				StartDate:  startDate,
+				EndDate:    startDate,
+				HotelID:    hotel.ID,
+				RoomTypeID: roomTypeID,
+			}, user)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode, roomTypeID)
+		}
+	})
+
+	t.Run("should_reject_confirmation_of_expired_offer", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := reservationSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+		ctx := context.Background()
+		_, err = reservationSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate},
+			TotalInventory: TestInventorySingle,
+		})
+		require.NoError(t, err)
+
+		// Capacity is free, so joining the waitlist offers a hold straight away.
+		joinResp, err := reservationSuite.MakeAuthenticatedRequest("POST", "/reservation/waitlist", reservation.JoinWaitlistBody{
+			StartDate:  startDate,
+			EndDate:    startDate,
+			HotelID:    hotel.ID,
+			RoomTypeID: roomType.ID,
+		}, user)
+		require.NoError(t, err)
+		defer joinResp.Body.Close()
+		require.Equal(t, http.StatusCreated, joinResp.StatusCode)
+
+		var joined WaitlistEntryResponse
+		require.NoError(t, json.NewDecoder(joinResp.Body).Decode(&joined))
+		require.Equal(t, string(reservation.WaitlistStatusOffered), joined.WaitlistEntry.Status)
+
+		_, err = reservationSuite.GetDB().GetDB().ExecContext(ctx, `
+			UPDATE booking.waitlist_entries
+			SET offer_expires_at = CURRENT_TIMESTAMP - INTERVAL '1 minute'
+			WHERE id = $1
+		`, joined.WaitlistEntry.ID)
+		require.NoError(t, err)
+
+		confirmResp, err := reservationSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/reservation/waitlist/%s/confirm", joined.WaitlistEntry.ID), nil, user)
+		require.NoError(t, err)
+		defer confirmResp.Body.Close()
+		assert.Equal(t, http.StatusConflict, confirmResp.StatusCode)
+	})
+
+	t.Run("should_reject_confirmation_of_cancelled_hold", func(t *testing.T) {
+		t.Parallel()
+
+		hotel, err := reservationSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := reservationSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := reservationSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+		ctx := context.Background()
+		_, err = reservationSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate},
+			TotalInventory: TestInventorySingle,
+		})
+		require.NoError(t, err)
+
+		joinResp, err := reservationSuite.MakeAuthenticatedRequest("POST", "/reservation/waitlist", reservation.JoinWaitlistBody{
+			StartDate:  startDate,
+			EndDate:    startDate,
+			HotelID:    hotel.ID,
+			RoomTypeID: roomType.ID,
+		}, user)
+		require.NoError(t, err)
+		defer joinResp.Body.Close()
+		require.Equal(t, http.StatusCreated, joinResp.StatusCode)
+
+		var joined WaitlistEntryResponse
+		require.NoError(t, json.NewDecoder(joinResp.Body).Decode(&joined))
+		require.Equal(t, string(reservation.WaitlistStatusOffered), joined.WaitlistEntry.Status)
+		require.True(t, joined.WaitlistEntry.ReservationID.Valid)
+		holdID := joined.WaitlistEntry.ReservationID.UUID
+
+		cancelResp, err := reservationSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/reservation/%s/cancel", holdID), nil, user)
+		require.NoError(t, err)
+		defer cancelResp.Body.Close()
+		require.Equal(t, http.StatusOK, cancelResp.StatusCode)
+
+		entryURL := fmt.Sprintf("/reservation/waitlist/%s", joined.WaitlistEntry.ID)
+		confirmResp, err := reservationSuite.MakeAuthenticatedRequest("POST", entryURL+"/confirm", nil, user)
+		require.NoError(t, err)
+		defer confirmResp.Body.Close()
+		assert.Equal(t, http.StatusConflict, confirmResp.StatusCode)
+
+		entry, err := reservationSuite.GetQueries().GetWaitlistEntryById(ctx, joined.WaitlistEntry.ID)
+		require.NoError(t, err)
+		assert.Equal(t, string(reservation.WaitlistStatusCancelled), entry.Status)
+
+		hold, err := reservationSuite.GetQueries().GetReservationById(ctx, holdID)
+		require.NoError(t, err)
+		assert.Equal(t, string(reservation.ReservationStatusCancelled), hold.Status)
+
+		var totalReserved int32
+		err = reservationSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT total_reserved FROM booking.room_type_inventory
+			WHERE hotel_id = $1 AND room_type_id = $2 AND date = $3
+		`, hotel.ID, roomType.ID, startDate).Scan(&totalReserved)
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), totalReserved)
+	})
+}
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/auth"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/AlexKhomenko00/hotel-system/internal/reservation"
+	"github.com/AlexKhomenko00/hotel-system/internal/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CreateSubscriptionResponse struct {
+	Subscription webhook.CreatedSubscription `json:"subscription"`
+}
+
+var (
+	webhookSuite *TestSuite
+	webhookSvc   *webhook.WebhookService
+)
+
+func init() {
+	webhookSuite = GetTestSuite()
+	cfg := webhookSuite.GetConfig()
+	webhookSvc = webhook.New(webhookSuite.GetQueries(), webhookSuite.GetValidator(), &cfg)
+	authSvc := webhookSuite.GetAuthService()
+	webhookSuite.RegisterPrivateHandlers(func(r chi.Router) {
+		r.Use(authSvc.RequireHotelAdmin("hotelId"))
+		webhookSvc.RegisterHotelHandlers(r)
+	}, "/hotel/{hotelId}/webhooks")
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	// Not parallel: its deliveries run through a service refusing loopback, which would fail the other
+	// subtests' deliveries it claims.
+	t.Run("should_refuse_private_targets", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("webhook was delivered to a loopback address")
+		}))
+		defer receiver.Close()
+
+		cfg := webhookSuite.GetConfig()
+		cfg.Webhooks.AllowPrivateTargets = false
+		strictSvc := webhook.New(webhookSuite.GetQueries(), webhookSuite.GetValidator(), &cfg)
+
+		r := chi.NewRouter()
+		r.Route("/hotel/{hotelId}/webhooks", strictSvc.RegisterHotelHandlers)
+
+		hotel, err := webhookSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		for _, target := range []string{receiver.URL, "http://169.254.169.254/latest/meta-data", "http://10.0.0.1/hooks"} {
+			body, err := json.Marshal(webhook.SubscriptionBody{URL: target, EventTypes: []string{string(events.ReservationCreated)}})
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/hotel/%s/webhooks/", hotel.ID), bytes.NewReader(body)))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		}
+
+		// Hosts can resolve elsewhere after registering, so the address is checked again when dialed.
+		ctx := context.Background()
+		subscription, err := webhookSuite.GetQueries().CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+			ID:         uuid.New(),
+			HotelID:    hotel.ID,
+			Url:        receiver.URL,
+			Secret:     "whsec_test",
+			EventTypes: []string{string(events.ReservationCreated)},
+			Active:     true,
+		})
+		require.NoError(t, err)
+
+		payload, err := json.Marshal(events.ReservationPayload{HotelID: hotel.ID})
+		require.NoError(t, err)
+		require.NoError(t, strictSvc.HandleEvent(ctx, events.Event{
+			ID:            uuid.New(),
+			Type:          events.ReservationCreated,
+			AggregateType: events.AggregateReservation,
+			AggregateID:   uuid.New(),
+			Payload:       payload,
+			OccurredAt:    time.Now(),
+		}))
+
+		_, err = strictSvc.DeliverDue(ctx)
+		require.NoError(t, err)
+
+		var lastError sql.NullString
+		err = webhookSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT last_error FROM booking.webhook_deliveries WHERE subscription_id = $1
+		`, subscription.ID).Scan(&lastError)
+		require.NoError(t, err)
+		assert.Contains(t, lastError.String, webhook.ErrTargetNotAllowed.Error())
+	})
+
+	t.Run("should_deliver_signed_reservation_event", func(t *testing.T) {
+		t.Parallel()
+
+		type received struct {
+			header http.Header
+			body   []byte
+		}
+		deliveries := make(chan received, 10)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			deliveries <- received{header: r.Header.Clone(), body: body}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		hotel, err := webhookSuite.CreateTestHotel()
+		require.NoError(t, err)
+
+		roomType, err := webhookSuite.CreateTestRoomType(hotel.ID)
+		require.NoError(t, err)
+
+		user, err := webhookSuite.CreateTestUser()
+		require.NoError(t, err)
+
+		err = webhookSuite.GrantTestRole(user.ID, auth.RoleHotelAdmin, uuid.NullUUID{UUID: hotel.ID, Valid: true})
+		require.NoError(t, err)
+
+		subResp, err := webhookSuite.MakeAuthenticatedRequest("POST", fmt.Sprintf("/hotel/%s/webhooks", hotel.ID), webhook.SubscriptionBody{
+			URL:        receiver.URL,
+			EventTypes: []string{string(events.ReservationCreated)},
+		}, user)
+		require.NoError(t, err)
+		defer subResp.Body.Close()
+		require.Equal(t, http.StatusCreated, subResp.StatusCode)
+
+		var created CreateSubscriptionResponse
+		require.NoError(t, json.NewDecoder(subResp.Body).Decode(&created))
+		require.NotEmpty(t, created.Subscription.Secret)
+
+		startDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+
+		ctx := context.Background()
+		_, err = webhookSuite.GetQueries().BatchUpdateRoomTypeInventory(ctx, database.BatchUpdateRoomTypeInventoryParams{
+			HotelID:        hotel.ID,
+			RoomTypeID:     roomType.ID,
+			Dates:          []time.Time{startDate},
+			TotalInventory: TestInventoryMax,
+		})
+		require.NoError(t, err)
+
+		reservationID := uuid.New()
+		resp, err := webhookSuite.MakeAuthenticatedRequest("POST", "/reservation", reservation.MakeReservationBody{
+			StartDate:     startDate,
+			EndDate:       startDate,
+			HotelID:       hotel.ID,
+			RoomTypeID:    roomType.ID,
+			ReservationId: reservationID.String(),
+		}, user)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		// Other tests flush the shared outbox, so hand the event to the webhook service directly.
+		var e events.Event
+		err = webhookSuite.GetDB().GetDB().QueryRowContext(ctx, `
+			SELECT id, event_type, aggregate_type, aggregate_id, payload, created_at
+			FROM booking.outbox_events
+			WHERE aggregate_id = $1 AND event_type = $2
+		`, reservationID, events.ReservationCreated).Scan(&e.ID, &e.Type, &e.AggregateType, &e.AggregateID, &e.Payload, &e.OccurredAt)
+		require.NoError(t, err)
+		require.NoError(t, webhookSvc.HandleEvent(ctx, e))
+
+		_, err = webhookSvc.DeliverDue(ctx)
+		require.NoError(t, err)
+
+		select {
+		case delivery := <-deliveries:
+			assert.Equal(t, string(events.ReservationCreated), delivery.header.Get(webhook.HeaderEventType))
+
+			timestamp, err := strconv.ParseInt(delivery.header.Get(webhook.HeaderTimestamp), 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, "v1="+webhook.Sign(created.Subscription.Secret, timestamp, delivery.body), delivery.header.Get(webhook.HeaderSignature))
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook was not delivered")
+		}
+	})
+}
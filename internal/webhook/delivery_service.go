@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/egress"
+	"github.com/AlexKhomenko00/hotel-system/internal/events"
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderEventID    = "X-Hotel-Event-Id"
+	HeaderEventType  = "X-Hotel-Event-Type"
+	HeaderDeliveryID = "X-Hotel-Delivery-Id"
+	HeaderTimestamp  = "X-Hotel-Timestamp"
+	HeaderSignature  = "X-Hotel-Signature"
+)
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>". Receivers recompute it with their secret and
+// should reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HandleEvent queues a delivery for every active subscription of the event's hotel.
+// It is meant to be subscribed to the event bus.
+func (s *WebhookService) HandleEvent(ctx context.Context, e events.Event) error {
+	var scope struct {
+		HotelID uuid.UUID `json:"hotel_id"`
+	}
+	if err := json.Unmarshal(e.Payload, &scope); err != nil || scope.HotelID == uuid.Nil {
+		return nil
+	}
+
+	subscriptions, err := s.queries.GetActiveWebhookSubscriptionsForEvent(ctx, database.GetActiveWebhookSubscriptionsForEventParams{
+		HotelID:   scope.HotelID,
+		EventType: string(e.Type),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscriptions for hotel %q: %w", scope.HotelID, err)
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %q: %w", e.ID, err)
+	}
+
+	for _, subscription := range subscriptions {
+		if err := s.queries.InsertWebhookDelivery(ctx, database.InsertWebhookDeliveryParams{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        e.ID,
+			EventType:      string(e.Type),
+			Payload:        body,
+		}); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery for subscription %q: %w", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunDeliveryWorker sends due deliveries until ctx is done.
+func (s *WebhookService) RunDeliveryWorker(ctx context.Context) {
+	ticker := time.NewTicker(deliveryInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.DeliverDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "Webhook delivery run failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many succeeded.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	// The lease outlives the HTTP timeout and the deliveries of a batch are sent concurrently, so every one of
+	// them is done before the lease runs out and isn't picked up twice while it's in flight.
+	deliveries, err := s.queries.ClaimDueWebhookDeliveries(ctx, database.ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: int32((2 * deliveryTimeout).Seconds()),
+		BatchSize:    deliveryBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	var delivered atomic.Int32
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := s.deliver(ctx, delivery)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+				return
+			}
+			if ok {
+				delivered.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return int(delivered.Load()), nil
+}
+
+func (s *WebhookService) deliver(ctx context.Context, delivery database.BookingWebhookDelivery) (bool, error) {
+	subscription, err := s.queries.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get webhook subscription %q: %w", delivery.SubscriptionID, err)
+	}
+
+	started := time.Now()
+	statusCode, sendErr := s.send(ctx, subscription, delivery)
+	duration := time.Since(started)
+
+	attempt := database.InsertWebhookDeliveryAttemptParams{
+		DeliveryID: delivery.ID,
+		DurationMs: int32(duration.Milliseconds()),
+	}
+	if statusCode != 0 {
+		attempt.StatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	}
+	if sendErr != nil {
+		attempt.Error = sql.NullString{String: sendErr.Error(), Valid: true}
+	}
+	if err := s.queries.InsertWebhookDeliveryAttempt(ctx, attempt); err != nil {
+		return false, fmt.Errorf("failed to log webhook delivery attempt: %w", err)
+	}
+
+	if sendErr == nil {
+		return true, s.queries.MarkWebhookDeliveryDelivered(ctx, database.MarkWebhookDeliveryDeliveredParams{
+			LastStatusCode: attempt.StatusCode,
+			ID:             delivery.ID,
+		})
+	}
+
+	attempts := delivery.Attempts + 1
+	status := DeliveryStatusPending
+	if attempts >= maxDeliveryAttempts || !subscription.Active {
+		status = DeliveryStatusDead
+	}
+
+	return false, s.queries.MarkWebhookDeliveryFailed(ctx, database.MarkWebhookDeliveryFailedParams{
+		Status:         string(status),
+		NextAttemptAt:  time.Now().UTC().Add(retryDelay(attempts)),
+		LastStatusCode: attempt.StatusCode,
+		LastError:      attempt.Error,
+		ID:             delivery.ID,
+	})
+}
+
+func (s *WebhookService) send(ctx context.Context, subscription database.BookingWebhookSubscription, delivery database.BookingWebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, delivery.EventID.String())
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderDeliveryID, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, "v1="+Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if errors.Is(err, egress.ErrNotPublic) {
+		return 0, fmt.Errorf("%w: %v", ErrTargetNotAllowed, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// retryDelay doubles the wait after every failed attempt, capped at maxRetryDelay.
+func retryDelay(attempts int32) time.Duration {
+	delay := baseRetryDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+type ListDeliveriesQuery struct {
+	Status  *DeliveryState
+	HotelID *uuid.UUID
+	Limit   int32
+}
+
+func (s *WebhookService) listDeliveries(ctx context.Context, query ListDeliveriesQuery) ([]database.ListWebhookDeliveriesRow, error) {
+	params := database.ListWebhookDeliveriesParams{RowLimit: query.Limit}
+	if query.Status != nil {
+		params.Status = sql.NullString{String: string(*query.Status), Valid: true}
+	}
+	if query.HotelID != nil {
+		params.HotelID = uuid.NullUUID{UUID: *query.HotelID, Valid: true}
+	}
+
+	deliveries, err := s.queries.ListWebhookDeliveries(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *WebhookService) getDeliveryAttempts(ctx context.Context, deliveryID uuid.UUID) ([]database.BookingWebhookDeliveryAttempt, error) {
+	attempts, err := s.queries.GetWebhookDeliveryAttempts(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempts of webhook delivery %q: %w", deliveryID, err)
+	}
+	return attempts, nil
+}
+
+// replayDelivery queues a delivery again with a fresh retry budget, whatever its current state.
+func (s *WebhookService) replayDelivery(ctx context.Context, deliveryID uuid.UUID) error {
+	replayed, err := s.queries.ReplayWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to replay webhook delivery %q: %w", deliveryID, err)
+	}
+	if replayed == 0 {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
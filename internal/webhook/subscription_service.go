@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/google/uuid"
+)
+
+type SubscriptionBody struct {
+	URL        string   `json:"url" validate:"required,http_url"`
+	EventTypes []string `json:"eventTypes" validate:"required,min=1,dive,oneof=ReservationCreated ReservationConfirmed ReservationModified ReservationCancelled InventoryChanged"`
+	// Defaults to true when omitted.
+	Active *bool `json:"active"`
+}
+
+type Subscription struct {
+	ID         uuid.UUID `json:"id"`
+	HotelID    uuid.UUID `json:"hotel_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreatedSubscription is returned once, on creation. The secret isn't exposed afterwards.
+type CreatedSubscription struct {
+	Subscription
+	Secret string `json:"secret"`
+}
+
+func newSubscription(s database.BookingWebhookSubscription) Subscription {
+	return Subscription{
+		ID:         s.ID,
+		HotelID:    s.HotelID,
+		URL:        s.Url,
+		EventTypes: s.EventTypes,
+		Active:     s.Active,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+func (s *WebhookService) listSubscriptions(ctx context.Context, hotelID uuid.UUID) ([]Subscription, error) {
+	rows, err := s.queries.ListHotelWebhookSubscriptions(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions for hotel %q: %w", hotelID, err)
+	}
+
+	subscriptions := make([]Subscription, 0, len(rows))
+	for _, row := range rows {
+		subscriptions = append(subscriptions, newSubscription(row))
+	}
+	return subscriptions, nil
+}
+
+func (s *WebhookService) createSubscription(ctx context.Context, hotelID uuid.UUID, body SubscriptionBody) (CreatedSubscription, error) {
+	_, err := s.queries.GetHotelById(ctx, hotelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CreatedSubscription{}, ErrHotelNotFound
+	}
+	if err != nil {
+		return CreatedSubscription{}, fmt.Errorf("failed to get hotel %q: %w", hotelID, err)
+	}
+
+	if err := s.checkTarget(ctx, body.URL); err != nil {
+		return CreatedSubscription{}, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return CreatedSubscription{}, err
+	}
+
+	subscription, err := s.queries.CreateWebhookSubscription(ctx, database.CreateWebhookSubscriptionParams{
+		ID:         uuid.New(),
+		HotelID:    hotelID,
+		Url:        body.URL,
+		Secret:     secret,
+		EventTypes: body.EventTypes,
+		Active:     body.Active == nil || *body.Active,
+	})
+	if err != nil {
+		return CreatedSubscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return CreatedSubscription{Subscription: newSubscription(subscription), Secret: subscription.Secret}, nil
+}
+
+func (s *WebhookService) updateSubscription(ctx context.Context, hotelID, subscriptionID uuid.UUID, body SubscriptionBody) (Subscription, error) {
+	if err := s.checkTarget(ctx, body.URL); err != nil {
+		return Subscription{}, err
+	}
+
+	subscription, err := s.queries.UpdateWebhookSubscription(ctx, database.UpdateWebhookSubscriptionParams{
+		Url:        body.URL,
+		EventTypes: body.EventTypes,
+		Active:     body.Active == nil || *body.Active,
+		ID:         subscriptionID,
+		HotelID:    hotelID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return Subscription{}, fmt.Errorf("failed to update webhook subscription %q: %w", subscriptionID, err)
+	}
+
+	return newSubscription(subscription), nil
+}
+
+func (s *WebhookService) deleteSubscription(ctx context.Context, hotelID, subscriptionID uuid.UUID) error {
+	deleted, err := s.queries.DeleteWebhookSubscription(ctx, database.DeleteWebhookSubscriptionParams{
+		ID:      subscriptionID,
+		HotelID: hotelID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription %q: %w", subscriptionID, err)
+	}
+	if deleted == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
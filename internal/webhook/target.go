@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/egress"
+)
+
+// checkTarget rejects a subscription URL whose host resolves to an address that isn't public.
+func (s *WebhookService) checkTarget(ctx context.Context, rawURL string) error {
+	if s.allowPrivateTargets {
+		return nil
+	}
+
+	if err := egress.CheckURL(ctx, rawURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrTargetNotAllowed, err)
+	}
+	return nil
+}
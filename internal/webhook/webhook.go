@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/config"
+	"github.com/AlexKhomenko00/hotel-system/internal/database"
+	"github.com/AlexKhomenko00/hotel-system/internal/egress"
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhookService: Subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhookService: Delivery not found")
+	ErrHotelNotFound        = errors.New("webhookService: Hotel not found")
+	ErrTargetNotAllowed     = errors.New("webhookService: Target not allowed")
+)
+
+func init() {
+	shared.RegisterErrors(map[error]shared.DomainError{
+		ErrSubscriptionNotFound: {Status: http.StatusNotFound, Code: "webhook.subscription_not_found", Message: "Webhook subscription not found"},
+		ErrDeliveryNotFound:     {Status: http.StatusNotFound, Code: "webhook.delivery_not_found", Message: "Webhook delivery not found"},
+		ErrHotelNotFound:        {Status: http.StatusNotFound, Code: "hotel.not_found", Message: "Hotel not found"},
+		ErrTargetNotAllowed:     {Status: http.StatusBadRequest, Code: "webhook.target_not_allowed", Message: "Webhook URL must resolve to a public address"},
+	})
+}
+
+type DeliveryState string
+
+const (
+	DeliveryStatusPending   DeliveryState = "pending"
+	DeliveryStatusDelivered DeliveryState = "delivered"
+	// Dead deliveries exhausted their retries and are only sent again when replayed by an admin.
+	DeliveryStatusDead DeliveryState = "dead"
+)
+
+const (
+	deliveryTimeout     = 10 * time.Second
+	deliveryInterval    = 5 * time.Second
+	deliveryBatchSize   = 20
+	maxDeliveryAttempts = 8
+	baseRetryDelay      = 30 * time.Second
+	maxRetryDelay       = 6 * time.Hour
+)
+
+type WebhookService struct {
+	queries             *database.Queries
+	validator           *validator.Validate
+	client              *http.Client
+	allowPrivateTargets bool
+}
+
+func New(queries *database.Queries, validator *validator.Validate, cfg *config.Config) *WebhookService {
+	return &WebhookService{
+		queries:             queries,
+		validator:           validator,
+		client:              egress.NewClient(deliveryTimeout, cfg.Webhooks.AllowPrivateTargets),
+		allowPrivateTargets: cfg.Webhooks.AllowPrivateTargets,
+	}
+}
@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexKhomenko00/hotel-system/internal/shared"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// RegisterHotelHandlers mounts subscription management. The router is expected to carry a {hotelId} URL parameter.
+func (s *WebhookService) RegisterHotelHandlers(r chi.Router) {
+	r.Get("/", s.ListSubscriptionsHandler)
+	r.Post("/", s.CreateSubscriptionHandler)
+	r.Put("/{subscriptionId}", s.UpdateSubscriptionHandler)
+	r.Delete("/{subscriptionId}", s.DeleteSubscriptionHandler)
+}
+
+// RegisterAdminHandlers mounts delivery inspection and replay.
+func (s *WebhookService) RegisterAdminHandlers(r chi.Router) {
+	r.Get("/deliveries", s.ListDeliveriesHandler)
+	r.Get("/deliveries/{deliveryId}/attempts", s.GetDeliveryAttemptsHandler)
+	r.Post("/deliveries/{deliveryId}/replay", s.ReplayDeliveryHandler)
+}
+
+func (s *WebhookService) ListSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	subscriptions, err := s.listSubscriptions(r.Context(), hotelID)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to list webhook subscriptions")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"subscriptions": subscriptions})
+}
+
+func (s *WebhookService) CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	var body SubscriptionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	subscription, err := s.createSubscription(r.Context(), hotelID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, shared.Envelope{"subscription": subscription})
+}
+
+func (s *WebhookService) UpdateSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(chi.URLParam(r, "subscriptionId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	var body SubscriptionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid body %v", err))
+		return
+	}
+
+	if err := s.validator.Struct(&body); err != nil {
+		shared.WriteValidationError(w, err)
+		return
+	}
+
+	subscription, err := s.updateSubscription(r.Context(), hotelID, subscriptionID, body)
+	if err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"subscription": subscription})
+}
+
+func (s *WebhookService) DeleteSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	hotelID, err := uuid.Parse(chi.URLParam(r, "hotelId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid hotel ID")
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(chi.URLParam(r, "subscriptionId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid subscription ID")
+		return
+	}
+
+	if err := s.deleteSubscription(r.Context(), hotelID, subscriptionID); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *WebhookService) ListDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	query := ListDeliveriesQuery{Limit: defaultDeliveriesLimit}
+
+	switch status := DeliveryState(r.URL.Query().Get("status")); status {
+	case "":
+	case DeliveryStatusPending, DeliveryStatusDelivered, DeliveryStatusDead:
+		query.Status = &status
+	default:
+		shared.WriteError(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	if hotelIDStr := r.URL.Query().Get("hotelId"); hotelIDStr != "" {
+		hotelID, err := uuid.Parse(hotelIDStr)
+		if err != nil {
+			shared.WriteError(w, http.StatusBadRequest, "Invalid hotelId")
+			return
+		}
+		query.HotelID = &hotelID
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxDeliveriesLimit {
+			shared.WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit, expected 1-%d", maxDeliveriesLimit))
+			return
+		}
+		query.Limit = int32(limit)
+	}
+
+	deliveries, err := s.listDeliveries(r.Context(), query)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to list webhook deliveries")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"deliveries": deliveries})
+}
+
+func (s *WebhookService) GetDeliveryAttemptsHandler(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid delivery ID")
+		return
+	}
+
+	attempts, err := s.getDeliveryAttempts(r.Context(), deliveryID)
+	if err != nil {
+		shared.WriteError(w, http.StatusInternalServerError, "Failed to get webhook delivery attempts")
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, shared.Envelope{"attempts": attempts})
+}
+
+func (s *WebhookService) ReplayDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, "Invalid delivery ID")
+		return
+	}
+
+	if err := s.replayDelivery(r.Context(), deliveryID); err != nil {
+		shared.WriteDomainError(w, err)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusAccepted, shared.Envelope{"message": "Delivery queued for replay", "delivery_id": deliveryID})
+}
@@ -0,0 +1,97 @@
+-- name: CreateCalendarFeed :one
+INSERT INTO
+	booking.calendar_feeds (id, hotel_id, room_type_id, token)
+VALUES
+	($1, $2, $3, $4)
+RETURNING
+	*;
+
+-- name: ListHotelCalendarFeeds :many
+SELECT
+	*
+FROM
+	booking.calendar_feeds
+WHERE
+	hotel_id = $1
+ORDER BY
+	created_at;
+
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM booking.calendar_feeds
+WHERE
+	id = $1
+	AND hotel_id = $2;
+
+-- name: GetCalendarFeedByToken :one
+SELECT
+	*
+FROM
+	booking.calendar_feeds
+WHERE
+	token = $1;
+
+-- name: UpsertGuestCalendarFeed :one
+INSERT INTO
+	booking.guest_calendar_feeds (guest_id, token)
+VALUES
+	($1, $2)
+ON CONFLICT (guest_id) DO UPDATE
+SET
+	token = EXCLUDED.token,
+	created_at = CURRENT_TIMESTAMP
+RETURNING
+	*;
+
+-- name: DeleteGuestCalendarFeed :execrows
+DELETE FROM booking.guest_calendar_feeds
+WHERE
+	guest_id = $1;
+
+-- name: GetGuestCalendarFeedByToken :one
+SELECT
+	*
+FROM
+	booking.guest_calendar_feeds
+WHERE
+	token = $1;
+
+-- name: GetGuestCalendarReservations :many
+-- Cancelled reservations stay in the feed, so calendar apps drop them through a CANCELLED update.
+SELECT
+	r.*,
+	h.name AS hotel_name,
+	h.location AS hotel_location,
+	rt.name AS room_type_name
+FROM
+	booking.reservations r
+	JOIN booking.hotels h ON h.id = r.hotel_id
+	JOIN booking.room_types rt ON rt.id = r.room_type_id
+WHERE
+	r.guest_id = @guest_id
+	AND r.end_date >= @from_date
+ORDER BY
+	r.start_date,
+	r.id;
+
+-- name: GetHotelCalendarReservations :many
+SELECT
+	r.*,
+	g.first_name AS guest_first_name,
+	g.last_name AS guest_last_name,
+	h.name AS hotel_name,
+	rt.name AS room_type_name
+FROM
+	booking.reservations r
+	JOIN booking.guests g ON g.id = r.guest_id
+	JOIN booking.hotels h ON h.id = r.hotel_id
+	JOIN booking.room_types rt ON rt.id = r.room_type_id
+WHERE
+	r.hotel_id = @hotel_id
+	AND (
+		sqlc.narg ('room_type_id')::uuid IS NULL
+		OR r.room_type_id = sqlc.narg ('room_type_id')::uuid
+	)
+	AND r.end_date >= @from_date
+ORDER BY
+	r.start_date,
+	r.id;
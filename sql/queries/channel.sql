@@ -0,0 +1,124 @@
+-- name: CreateChannel :one
+INSERT INTO
+	booking.channels (
+		id,
+		hotel_id,
+		name,
+		adapter,
+		endpoint_url,
+		hotel_code,
+		api_key,
+		active
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+	*;
+
+-- name: GetChannelById :one
+SELECT
+	*
+FROM
+	booking.channels
+WHERE
+	id = $1;
+
+-- name: ListHotelChannels :many
+SELECT
+	*
+FROM
+	booking.channels
+WHERE
+	hotel_id = $1
+ORDER BY
+	created_at;
+
+-- name: DeleteChannel :execrows
+DELETE FROM booking.channels
+WHERE
+	id = $1
+	AND hotel_id = $2;
+
+-- name: GetActiveChannels :many
+SELECT
+	*
+FROM
+	booking.channels
+WHERE
+	active
+ORDER BY
+	id;
+
+-- name: GetActiveChannelsForRoomType :many
+-- Channels that sell the room type, used to push inventory changes as they happen.
+SELECT
+	c.*
+FROM
+	booking.channels c
+	JOIN booking.channel_room_mappings m ON m.channel_id = c.id
+WHERE
+	c.active
+	AND c.hotel_id = @hotel_id
+	AND m.room_type_id = @room_type_id;
+
+-- name: RecordChannelSync :exec
+UPDATE booking.channels
+SET
+	last_synced_at = CURRENT_TIMESTAMP,
+	last_sync_error = @last_sync_error,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
+
+-- name: UpsertChannelRoomMapping :one
+INSERT INTO
+	booking.channel_room_mappings (
+		channel_id,
+		room_type_id,
+		external_room_code,
+		rate_plan_code,
+		rate,
+		currency
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT (channel_id, room_type_id) DO UPDATE
+SET
+	external_room_code = EXCLUDED.external_room_code,
+	rate_plan_code = EXCLUDED.rate_plan_code,
+	rate = EXCLUDED.rate,
+	currency = EXCLUDED.currency,
+	updated_at = CURRENT_TIMESTAMP
+RETURNING
+	*;
+
+-- name: ListChannelRoomMappings :many
+SELECT
+	*
+FROM
+	booking.channel_room_mappings
+WHERE
+	channel_id = $1
+ORDER BY
+	external_room_code;
+
+-- name: DeleteChannelRoomMapping :execrows
+DELETE FROM booking.channel_room_mappings
+WHERE
+	channel_id = $1
+	AND room_type_id = $2;
+
+-- name: GetChannelReservation :one
+SELECT
+	*
+FROM
+	booking.channel_reservations
+WHERE
+	channel_id = $1
+	AND external_id = $2;
+
+-- name: InsertChannelReservation :exec
+INSERT INTO
+	booking.channel_reservations (channel_id, external_id, reservation_id)
+VALUES
+	($1, $2, $3);
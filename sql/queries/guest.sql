@@ -1,4 +1,12 @@
 -- name: InsertGuest :one
-INSERT INTO booking.guests (id, first_name, last_name, email)
-VALUES ($1, $2, $3, $4)
-RETURNING id, first_name, last_name, email;
+INSERT INTO booking.guests (id, first_name, last_name, email, locale)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, first_name, last_name, email, locale;
+
+-- name: UpsertGuestByEmail :one
+-- Guests booking through a channel are matched by email, their stored details are left untouched.
+INSERT INTO booking.guests (id, first_name, last_name, email, locale)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (email) DO UPDATE
+SET email = EXCLUDED.email
+RETURNING id, first_name, last_name, email, locale;
@@ -1,8 +1,8 @@
 -- name: CreateHotel :one
 INSERT INTO
-	booking.hotels (id, name, location)
+	booking.hotels (id, name, location, time_zone)
 VALUES
-	($1, $2, $3)
+	($1, $2, $3, $4)
 RETURNING
 	*;
 
@@ -27,6 +27,7 @@ UPDATE booking.hotels
 SET
 	name = $2,
 	location = $3,
+	time_zone = COALESCE(NULLIF($4::text, ''), time_zone),
 	updated_at = CURRENT_TIMESTAMP
 WHERE
 	id = $1
@@ -109,3 +110,13 @@ FROM
 	booking.room_types
 WHERE
 	hotel_id = $1;
+
+-- name: GetHotelRoomTypes :many
+SELECT
+	*
+FROM
+	booking.room_types
+WHERE
+	hotel_id = $1
+ORDER BY
+	name;
@@ -1,3 +1,22 @@
+-- name: GetOverbookedNights :many
+SELECT
+	rti.room_type_id,
+	rt.name as room_type_name,
+	rti.date,
+	rti.total_inventory,
+	rti.total_reserved,
+	(rti.total_reserved - rti.total_inventory) as overbooked
+FROM
+	booking.room_type_inventory rti
+	INNER JOIN booking.room_types rt ON rti.room_type_id = rt.id
+WHERE
+	rti.hotel_id = @hotel_id
+	AND rti.date BETWEEN @date_from AND @date_to
+	AND rti.total_reserved > rti.total_inventory
+ORDER BY
+	rti.date,
+	rt.name;
+
 -- name: GetHotelInventoryForRange :many
 SELECT
 	*
@@ -8,6 +27,18 @@ WHERE
 	AND hotel_id = $2
 	AND date BETWEEN $3 AND $4;
 
+-- name: GetHotelInventoryForDates :many
+SELECT
+	*
+FROM
+	booking.room_type_inventory
+WHERE
+	hotel_id = @hotel_id
+	AND date BETWEEN @date_from AND @date_to
+ORDER BY
+	room_type_id,
+	date;
+
 -- name: UpdateRoomTypeInventoryForDate :execrows
 UPDATE booking.room_type_inventory
 SET
@@ -49,10 +80,112 @@ SELECT
 	rt.name as room_type_name,
 	rt.description,
 	rti.date,
-	(rti.total_inventory - rti.total_reserved) as available_capacity
+	(rti.total_inventory - rti.total_reserved) as available_capacity,
+	rti.total_inventory,
+	rti.total_reserved,
+	rtr.min_length_of_stay,
+	rtr.max_length_of_stay,
+	COALESCE(rtr.closed_to_arrival, FALSE) as closed_to_arrival,
+	COALESCE(rtr.closed_to_departure, FALSE) as closed_to_departure
 FROM booking.room_type_inventory rti
 INNER JOIN booking.room_types rt ON rti.room_type_id = rt.id
+LEFT JOIN booking.room_type_restrictions rtr ON rtr.hotel_id = rti.hotel_id
+	AND rtr.room_type_id = rti.room_type_id
+	AND rtr.date = rti.date
 WHERE rti.hotel_id = @hotel_id
 	AND rti.date BETWEEN @check_in AND @check_out
-	AND rti.total_reserved < ((@overbooking)::float * rti.total_inventory)
+	AND NOT COALESCE(rtr.stop_sell, FALSE)
 ORDER BY rt.name, rti.date;
+
+-- name: GetRoomTypeInventoryForDates :many
+SELECT
+	*
+FROM
+	booking.room_type_inventory
+WHERE
+	hotel_id = @hotel_id
+	AND room_type_id = @room_type_id
+	AND date = ANY (@dates::date[])
+ORDER BY
+	date;
+
+-- name: SetRoomTypeInventoryTotal :execrows
+-- Bumps the version even when total_inventory stays the same, so concurrent writers notice the change.
+UPDATE booking.room_type_inventory
+SET
+	total_inventory = @total_inventory,
+	version = version + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	hotel_id = @hotel_id
+	AND room_type_id = @room_type_id
+	AND date = @date
+	AND version = @version
+	-- Overbooked nights may keep or raise their count, only a cut below the reservations is refused.
+	AND (
+		@total_inventory >= total_inventory
+		OR @total_inventory >= total_reserved
+	);
+
+-- name: InsertRoomTypeInventoryForDate :execrows
+INSERT INTO
+	booking.room_type_inventory (
+		hotel_id,
+		room_type_id,
+		date,
+		total_inventory,
+		total_reserved
+	)
+VALUES
+	(@hotel_id, @room_type_id, @date, @total_inventory, 0)
+ON CONFLICT (hotel_id, room_type_id, date) DO NOTHING;
+
+-- name: GetRoomTypeInventoryDrift :many
+-- Counts reserved rooms from the reservations and returns the dates where total_reserved disagrees.
+-- Both come from the same snapshot, so the version can guard a repair against concurrent bookings.
+SELECT
+	rti.hotel_id,
+	rti.room_type_id,
+	rti.date,
+	rti.version,
+	rti.total_inventory,
+	rti.total_reserved,
+	COALESCE(r.reserved, 0)::int AS actual_reserved
+FROM
+	booking.room_type_inventory rti
+	LEFT JOIN (
+		SELECT
+			res.room_type_id,
+			d::date AS date,
+			COUNT(*) AS reserved
+		FROM
+			booking.reservations res
+			CROSS JOIN generate_series(res.start_date, res.end_date, interval '1 day') AS d
+		WHERE
+			res.hotel_id = @hotel_id
+			AND res.status = ANY (@statuses::text[])
+			AND res.end_date >= @date_from
+		GROUP BY
+			res.room_type_id,
+			d::date
+	) r ON r.room_type_id = rti.room_type_id
+	AND r.date = rti.date
+WHERE
+	rti.hotel_id = @hotel_id
+	AND rti.date >= @date_from
+	AND rti.total_reserved <> COALESCE(r.reserved, 0)
+ORDER BY
+	rti.room_type_id,
+	rti.date;
+
+-- name: RepairRoomTypeInventoryReserved :execrows
+UPDATE booking.room_type_inventory
+SET
+	total_reserved = @total_reserved,
+	version = version + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	hotel_id = @hotel_id
+	AND room_type_id = @room_type_id
+	AND date = @date
+	AND version = @version;
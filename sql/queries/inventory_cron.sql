@@ -0,0 +1,178 @@
+-- name: GetActiveHotelsInPartition :many
+-- An empty time zone matches every zone, a shard count below 2 matches every shard.
+SELECT
+	*
+FROM
+	booking.hotels
+WHERE
+	is_active
+	AND deleted_at IS NULL
+	AND (
+		@time_zone::text = ''
+		OR time_zone = @time_zone
+	)
+	AND (
+		@shard_count::int < 2
+		OR mod(abs(hashtext(id::text)::bigint), @shard_count) = @shard_id::int
+	)
+ORDER BY
+	id;
+
+-- name: GetHotelRoomTypeInventoryEnds :many
+-- Returns the last date with inventory of every room type of a hotel. Room types without inventory
+-- from @from_date on get the day before it, so filling always starts at @from_date at the earliest.
+SELECT
+	rt.id AS room_type_id,
+	GREATEST(MAX(rti.date), @from_date::date - 1)::date AS last_date
+FROM
+	booking.room_types rt
+	LEFT JOIN booking.room_type_inventory rti ON rti.hotel_id = rt.hotel_id
+	AND rti.room_type_id = rt.id
+WHERE
+	rt.hotel_id = @hotel_id
+GROUP BY
+	rt.id
+ORDER BY
+	rt.id;
+
+-- name: CreateInventoryCronRun :one
+INSERT INTO
+	booking.inventory_cron_runs (id, partition_key, window_end, hotel_id)
+VALUES
+	($1, $2, $3, $4)
+RETURNING
+	*;
+
+-- name: GetUnfinishedInventoryCronRun :one
+SELECT
+	*
+FROM
+	booking.inventory_cron_runs
+WHERE
+	partition_key = $1
+	AND status = 'running'
+ORDER BY
+	started_at DESC
+LIMIT
+	1;
+
+-- name: FinishInventoryCronRun :exec
+UPDATE booking.inventory_cron_runs
+SET
+	status = $2,
+	failed_hotels = $3,
+	finished_at = CURRENT_TIMESTAMP
+WHERE
+	id = $1;
+
+-- name: InterruptInventoryCronRun :exec
+-- Only a run still running is marked, one that finished keeps its outcome.
+UPDATE booking.inventory_cron_runs
+SET
+	status = 'interrupted',
+	finished_at = CURRENT_TIMESTAMP
+WHERE
+	id = $1
+	AND status = 'running';
+
+-- name: GetInventoryCronCheckpoints :many
+SELECT
+	hotel_id
+FROM
+	booking.inventory_cron_checkpoints
+WHERE
+	run_id = $1;
+
+-- name: CreateInventoryCronCheckpoint :exec
+INSERT INTO
+	booking.inventory_cron_checkpoints (run_id, hotel_id)
+VALUES
+	($1, $2)
+ON CONFLICT (run_id, hotel_id) DO NOTHING;
+
+-- name: AddInventoryCronRunProgress :exec
+UPDATE booking.inventory_cron_runs
+SET
+	hotels_processed = hotels_processed + $2,
+	rows_inserted = rows_inserted + $3
+WHERE
+	id = $1;
+
+-- name: CreateInventoryCronRunError :exec
+INSERT INTO
+	booking.inventory_cron_run_errors (run_id, hotel_id, room_type_id, error)
+VALUES
+	($1, $2, $3, $4);
+
+-- name: GetInventoryCronRun :one
+SELECT
+	*
+FROM
+	booking.inventory_cron_runs
+WHERE
+	id = $1;
+
+-- name: ListInventoryCronRuns :many
+-- Filtering by hotel matches runs triggered for it and cron runs that populated it or failed on it.
+SELECT
+	r.*
+FROM
+	booking.inventory_cron_runs r
+WHERE
+	sqlc.narg ('hotel_id')::uuid IS NULL
+	OR r.hotel_id = sqlc.narg ('hotel_id')::uuid
+	OR EXISTS (
+		SELECT
+			1
+		FROM
+			booking.inventory_cron_checkpoints c
+		WHERE
+			c.run_id = r.id
+			AND c.hotel_id = sqlc.narg ('hotel_id')::uuid
+	)
+	OR EXISTS (
+		SELECT
+			1
+		FROM
+			booking.inventory_cron_run_errors e
+		WHERE
+			e.run_id = r.id
+			AND e.hotel_id = sqlc.narg ('hotel_id')::uuid
+	)
+ORDER BY
+	r.started_at DESC
+LIMIT
+	@row_limit::int;
+
+-- name: GetInventoryCronRunErrors :many
+SELECT
+	*
+FROM
+	booking.inventory_cron_run_errors
+WHERE
+	run_id = $1
+ORDER BY
+	id;
+
+-- name: GetLastCompletedInventoryCronRun :one
+-- Runs triggered for a single hotel don't count, they say nothing about the other hotels.
+SELECT
+	*
+FROM
+	booking.inventory_cron_runs
+WHERE
+	status = 'completed'
+	AND hotel_id IS NULL
+ORDER BY
+	finished_at DESC
+LIMIT
+	1;
+
+-- name: ListRunningTriggeredInventoryCronRuns :many
+SELECT
+	*
+FROM
+	booking.inventory_cron_runs
+WHERE
+	hotel_id IS NOT NULL
+	AND status = 'running';
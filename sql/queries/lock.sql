@@ -0,0 +1,25 @@
+-- name: TryAdvisoryLock :one
+SELECT
+	pg_try_advisory_lock(@lock_key::bigint) AS locked;
+
+-- name: AdvisoryUnlock :one
+SELECT
+	pg_advisory_unlock(@lock_key::bigint) AS unlocked;
+
+-- name: HoldsAdvisoryLock :one
+-- Reports whether the current session still holds the lock. A bigint key is stored in pg_locks split into
+-- its high and low halves, with objsubid 1.
+SELECT
+	EXISTS (
+		SELECT
+			1
+		FROM
+			pg_locks
+		WHERE
+			locktype = 'advisory'
+			AND pid = pg_backend_pid()
+			AND granted
+			AND objsubid = 1
+			AND classid::bigint = (@lock_key::bigint >> 32) & 4294967295
+			AND objid::bigint = @lock_key::bigint & 4294967295
+	) AS held;
@@ -0,0 +1,103 @@
+-- name: GetReservationNotificationDetails :one
+SELECT
+	r.*,
+	g.first_name AS guest_first_name,
+	g.last_name AS guest_last_name,
+	g.email AS guest_email,
+	g.locale AS guest_locale,
+	h.name AS hotel_name,
+	h.location AS hotel_location,
+	rt.name AS room_type_name
+FROM
+	booking.reservations r
+	JOIN booking.guests g ON g.id = r.guest_id
+	JOIN booking.hotels h ON h.id = r.hotel_id
+	JOIN booking.room_types rt ON rt.id = r.room_type_id
+WHERE
+	r.id = $1;
+
+-- name: GetReservationsArrivingBetween :many
+-- Reservations that still expect the guest, used by the pre-arrival reminder sweep.
+SELECT
+	id
+FROM
+	booking.reservations
+WHERE
+	start_date BETWEEN @from_date AND @to_date
+	AND status IN ('pending', 'paid');
+
+-- name: InsertNotification :execrows
+-- Returns 0 when a notification with the same dedupe key was already queued.
+INSERT INTO
+	booking.notifications (
+		id,
+		reservation_id,
+		kind,
+		locale,
+		recipient,
+		subject,
+		body_text,
+		body_html,
+		calendar,
+		dedupe_key,
+		status
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'pending')
+ON CONFLICT (dedupe_key) DO NOTHING;
+
+-- name: ClaimDueNotifications :many
+-- Leases due notifications the same way webhook deliveries are leased, so sending happens outside of a transaction.
+UPDATE booking.notifications
+SET
+	next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => @lease_seconds::int),
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			booking.notifications
+		WHERE
+			status = 'pending'
+			AND next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY
+			next_attempt_at
+		LIMIT
+			@batch_size::int
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING
+	*;
+
+-- name: MarkNotificationSent :exec
+UPDATE booking.notifications
+SET
+	status = 'sent',
+	attempts = attempts + 1,
+	last_error = NULL,
+	sent_at = CURRENT_TIMESTAMP,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = $1;
+
+-- name: MarkNotificationFailed :exec
+UPDATE booking.notifications
+SET
+	status = @status,
+	attempts = attempts + 1,
+	next_attempt_at = @next_attempt_at,
+	last_error = @last_error,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
+
+-- name: ListReservationNotifications :many
+SELECT
+	*
+FROM
+	booking.notifications
+WHERE
+	reservation_id = $1
+ORDER BY
+	created_at;
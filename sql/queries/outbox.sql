@@ -0,0 +1,87 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO
+	booking.outbox_events (
+		id,
+		aggregate_type,
+		aggregate_id,
+		event_type,
+		payload,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, CURRENT_TIMESTAMP);
+
+-- name: ClaimUnpublishedOutboxEvents :many
+-- SKIP LOCKED lets several relay instances drain the outbox concurrently.
+SELECT
+	*
+FROM
+	booking.outbox_events
+WHERE
+	published_at IS NULL
+	AND attempts < @max_attempts::int
+	AND next_attempt_at <= CURRENT_TIMESTAMP
+ORDER BY
+	created_at
+LIMIT
+	@batch_size::int
+FOR UPDATE SKIP LOCKED;
+
+-- name: GetOutboxBacklog :one
+-- Counts the events waiting for the relay and how long the oldest of them has been waiting.
+SELECT
+	COUNT(*) AS pending,
+	COALESCE(EXTRACT(EPOCH FROM LOCALTIMESTAMP - MIN(created_at)), 0)::float8 AS oldest_age_seconds
+FROM
+	booking.outbox_events
+WHERE
+	published_at IS NULL
+	AND attempts < @max_attempts::int;
+
+-- name: ListOutboxEventSubscribers :many
+SELECT
+	subscriber
+FROM
+	booking.outbox_deliveries
+WHERE
+	event_id = $1;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE booking.outbox_events
+SET
+	published_at = CURRENT_TIMESTAMP,
+	attempts = attempts + 1,
+	last_error = NULL
+WHERE
+	id = $1;
+
+-- name: RecordOutboxEventFailure :exec
+UPDATE booking.outbox_events
+SET
+	attempts = attempts + 1,
+	last_error = @last_error,
+	next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => @retry_after_seconds::float8)
+WHERE
+	id = @id;
+
+-- name: RecordOutboxDelivery :exec
+INSERT INTO
+	booking.outbox_deliveries (event_id, subscriber)
+VALUES
+	($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ReplayFailedOutboxEvents :execrows
+-- Gives unpublished events that failed a fresh set of attempts, starting now. Without an ID every such event
+-- is replayed.
+UPDATE booking.outbox_events
+SET
+	attempts = 0,
+	next_attempt_at = CURRENT_TIMESTAMP
+WHERE
+	published_at IS NULL
+	AND attempts > 0
+	AND (
+		sqlc.narg ('id')::uuid IS NULL
+		OR id = sqlc.narg ('id')::uuid
+	);
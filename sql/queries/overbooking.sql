@@ -0,0 +1,70 @@
+-- name: GetApplicableOverbookingPolicies :many
+SELECT
+	*
+FROM
+	booking.overbooking_policies
+WHERE
+	hotel_id = @hotel_id::uuid
+	OR hotel_id IS NULL;
+
+-- name: ListHotelOverbookingPolicies :many
+SELECT
+	*
+FROM
+	booking.overbooking_policies
+WHERE
+	hotel_id = @hotel_id::uuid
+ORDER BY
+	room_type_id NULLS FIRST,
+	valid_from NULLS FIRST;
+
+-- name: GetGlobalOverbookingPolicy :one
+SELECT
+	*
+FROM
+	booking.overbooking_policies
+WHERE
+	hotel_id IS NULL;
+
+-- name: CreateOverbookingPolicy :one
+INSERT INTO
+	booking.overbooking_policies (id, hotel_id, room_type_id, valid_from, valid_to, factor)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+RETURNING
+	*;
+
+-- name: UpdateOverbookingPolicy :one
+UPDATE booking.overbooking_policies
+SET
+	room_type_id = @room_type_id,
+	valid_from = @valid_from,
+	valid_to = @valid_to,
+	factor = @factor,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id
+	AND hotel_id = @hotel_id::uuid
+RETURNING
+	*;
+
+-- name: DeleteOverbookingPolicy :execrows
+DELETE FROM booking.overbooking_policies
+WHERE
+	id = @id
+	AND hotel_id = @hotel_id::uuid;
+
+-- name: UpsertGlobalOverbookingPolicy :one
+INSERT INTO
+	booking.overbooking_policies (id, factor)
+VALUES
+	($1, $2)
+ON CONFLICT ((hotel_id IS NULL))
+WHERE
+	hotel_id IS NULL
+DO UPDATE
+SET
+	factor = EXCLUDED.factor,
+	updated_at = CURRENT_TIMESTAMP
+RETURNING
+	*;
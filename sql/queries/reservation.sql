@@ -33,3 +33,31 @@ VALUES
 	)
 RETURNING
 	id;
+
+-- name: GetReservationByIdForUpdate :one
+SELECT
+	*
+FROM
+	booking.reservations
+WHERE
+	id = $1
+FOR UPDATE;
+
+-- name: UpdateReservationDates :exec
+UPDATE booking.reservations
+SET
+	start_date = @start_date,
+	end_date = @end_date,
+	revision = revision + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
+
+-- name: UpdateReservationStatus :exec
+UPDATE booking.reservations
+SET
+	status = @status,
+	revision = revision + 1,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
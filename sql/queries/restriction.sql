@@ -0,0 +1,89 @@
+-- name: GetRoomTypeRestrictionsForRange :many
+SELECT
+	*
+FROM
+	booking.room_type_restrictions
+WHERE
+	hotel_id = @hotel_id
+	AND room_type_id = @room_type_id
+	AND date BETWEEN @date_from AND @date_to
+ORDER BY
+	date;
+
+-- name: GetHotelRestrictionsForDates :many
+SELECT
+	*
+FROM
+	booking.room_type_restrictions
+WHERE
+	hotel_id = @hotel_id
+	AND date BETWEEN @date_from AND @date_to
+ORDER BY
+	room_type_id,
+	date;
+
+-- name: UpsertRoomTypeRestrictions :execrows
+INSERT INTO
+	booking.room_type_restrictions (
+		hotel_id,
+		room_type_id,
+		date,
+		min_length_of_stay,
+		max_length_of_stay,
+		closed_to_arrival,
+		closed_to_departure,
+		stop_sell,
+		updated_at,
+		created_at
+	)
+SELECT
+	@hotel_id,
+	@room_type_id,
+	unnest(@dates::date[]),
+	sqlc.narg(min_length_of_stay),
+	sqlc.narg(max_length_of_stay),
+	@closed_to_arrival,
+	@closed_to_departure,
+	@stop_sell,
+	CURRENT_TIMESTAMP,
+	CURRENT_TIMESTAMP
+	ON CONFLICT (hotel_id, room_type_id, date)
+DO UPDATE
+SET
+	min_length_of_stay = EXCLUDED.min_length_of_stay,
+	max_length_of_stay = EXCLUDED.max_length_of_stay,
+	closed_to_arrival = EXCLUDED.closed_to_arrival,
+	closed_to_departure = EXCLUDED.closed_to_departure,
+	stop_sell = EXCLUDED.stop_sell,
+	updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteRoomTypeRestrictions :execrows
+DELETE FROM booking.room_type_restrictions
+WHERE
+	hotel_id = @hotel_id
+	AND room_type_id = @room_type_id
+	AND date = ANY (@dates::date[]);
+
+-- name: SetRoomTypeStopSell :execrows
+-- Only touches stop_sell, other restrictions of the dates are kept.
+INSERT INTO
+	booking.room_type_restrictions (
+		hotel_id,
+		room_type_id,
+		date,
+		stop_sell,
+		updated_at,
+		created_at
+	)
+SELECT
+	@hotel_id,
+	@room_type_id,
+	unnest(@dates::date[]),
+	@stop_sell,
+	CURRENT_TIMESTAMP,
+	CURRENT_TIMESTAMP
+	ON CONFLICT (hotel_id, room_type_id, date)
+DO UPDATE
+SET
+	stop_sell = EXCLUDED.stop_sell,
+	updated_at = CURRENT_TIMESTAMP;
@@ -23,3 +23,18 @@ VALUES
 	($1, $2, $3, $4)
 RETURNING
 	*;
+
+-- name: GetUserRoles :many
+SELECT
+	*
+FROM
+	auth.user_roles
+WHERE
+	user_id = $1;
+
+-- name: GrantUserRole :exec
+INSERT INTO
+	auth.user_roles (user_id, role, hotel_id)
+VALUES
+	($1, $2, $3)
+ON CONFLICT DO NOTHING;
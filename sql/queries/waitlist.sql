@@ -0,0 +1,123 @@
+-- name: InsertWaitlistEntry :one
+INSERT INTO
+	booking.waitlist_entries (
+		id,
+		hotel_id,
+		room_type_id,
+		guest_id,
+		start_date,
+		end_date,
+		status
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+	*;
+
+-- name: GetWaitlistEntryById :one
+SELECT
+	*
+FROM
+	booking.waitlist_entries
+WHERE
+	id = $1;
+
+-- name: GetWaitlistEntryForUpdate :one
+SELECT
+	*
+FROM
+	booking.waitlist_entries
+WHERE
+	id = $1
+FOR UPDATE;
+
+-- name: LockWaitingEntry :one
+-- SKIP LOCKED lets several workers walk the same queue without blocking on each other.
+SELECT
+	*
+FROM
+	booking.waitlist_entries
+WHERE
+	id = $1
+	AND status = 'waiting'
+FOR UPDATE SKIP LOCKED;
+
+-- name: ListGuestWaitlistEntries :many
+SELECT
+	*
+FROM
+	booking.waitlist_entries
+WHERE
+	guest_id = $1
+ORDER BY
+	created_at DESC;
+
+-- name: GetWaitingEntriesForRoomType :many
+SELECT
+	*
+FROM
+	booking.waitlist_entries
+WHERE
+	hotel_id = @hotel_id
+	AND room_type_id = @room_type_id
+	AND status = 'waiting'
+ORDER BY
+	created_at;
+
+-- name: GetRoomTypesWithWaitingEntries :many
+SELECT DISTINCT
+	hotel_id,
+	room_type_id
+FROM
+	booking.waitlist_entries
+WHERE
+	status = 'waiting';
+
+-- name: GetExpiredWaitlistOffers :many
+SELECT
+	*
+FROM
+	booking.waitlist_entries
+WHERE
+	status = 'offered'
+	AND offer_expires_at < CURRENT_TIMESTAMP
+ORDER BY
+	offer_expires_at;
+
+-- name: MarkWaitlistEntryOffered :exec
+UPDATE booking.waitlist_entries
+SET
+	status = 'offered',
+	reservation_id = @reservation_id,
+	offer_expires_at = @offer_expires_at,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
+
+-- name: UpdateWaitlistEntryStatus :exec
+UPDATE booking.waitlist_entries
+SET
+	status = @status,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
+
+-- name: CancelWaitlistOfferForReservation :exec
+-- A cancelled hold can no longer be confirmed.
+UPDATE booking.waitlist_entries
+SET
+	status = 'cancelled',
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	reservation_id = $1
+	AND status = 'offered';
+
+-- name: ExpireStaleWaitlistEntries :execrows
+-- Entries whose stay has already started can no longer be offered.
+UPDATE booking.waitlist_entries
+SET
+	status = 'expired',
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	status = 'waiting'
+	AND start_date < CURRENT_DATE;
@@ -0,0 +1,157 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO
+	booking.webhook_subscriptions (id, hotel_id, url, secret, event_types, active)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+RETURNING
+	*;
+
+-- name: GetWebhookSubscription :one
+SELECT
+	*
+FROM
+	booking.webhook_subscriptions
+WHERE
+	id = $1;
+
+-- name: ListHotelWebhookSubscriptions :many
+SELECT
+	*
+FROM
+	booking.webhook_subscriptions
+WHERE
+	hotel_id = $1
+ORDER BY
+	created_at;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE booking.webhook_subscriptions
+SET
+	url = @url,
+	event_types = @event_types,
+	active = @active,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id
+	AND hotel_id = @hotel_id
+RETURNING
+	*;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM booking.webhook_subscriptions
+WHERE
+	id = $1
+	AND hotel_id = $2;
+
+-- name: GetActiveWebhookSubscriptionsForEvent :many
+SELECT
+	*
+FROM
+	booking.webhook_subscriptions
+WHERE
+	hotel_id = @hotel_id
+	AND active
+	AND @event_type::text = ANY (event_types);
+
+-- name: InsertWebhookDelivery :exec
+INSERT INTO
+	booking.webhook_deliveries (id, subscription_id, event_id, event_type, payload, status)
+VALUES
+	($1, $2, $3, $4, $5, 'pending')
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Leases due deliveries by pushing next_attempt_at forward, so the HTTP calls run outside of a transaction
+-- and a crashed worker's deliveries become due again once the lease runs out.
+UPDATE booking.webhook_deliveries
+SET
+	next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => @lease_seconds::int),
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			booking.webhook_deliveries
+		WHERE
+			status = 'pending'
+			AND next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY
+			next_attempt_at
+		LIMIT
+			@batch_size::int
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING
+	*;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE booking.webhook_deliveries
+SET
+	status = 'delivered',
+	attempts = attempts + 1,
+	last_status_code = @last_status_code,
+	last_error = NULL,
+	delivered_at = CURRENT_TIMESTAMP,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE booking.webhook_deliveries
+SET
+	status = @status,
+	attempts = attempts + 1,
+	next_attempt_at = @next_attempt_at,
+	last_status_code = @last_status_code,
+	last_error = @last_error,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = @id;
+
+-- name: InsertWebhookDeliveryAttempt :exec
+INSERT INTO
+	booking.webhook_delivery_attempts (delivery_id, status_code, error, duration_ms)
+VALUES
+	($1, $2, $3, $4);
+
+-- name: ListWebhookDeliveries :many
+SELECT
+	d.*,
+	s.hotel_id
+FROM
+	booking.webhook_deliveries d
+	INNER JOIN booking.webhook_subscriptions s ON d.subscription_id = s.id
+WHERE
+	(
+		sqlc.narg ('status')::text IS NULL
+		OR d.status = sqlc.narg ('status')::text
+	)
+	AND (
+		sqlc.narg ('hotel_id')::uuid IS NULL
+		OR s.hotel_id = sqlc.narg ('hotel_id')::uuid
+	)
+ORDER BY
+	d.created_at DESC
+LIMIT
+	@row_limit::int;
+
+-- name: GetWebhookDeliveryAttempts :many
+SELECT
+	*
+FROM
+	booking.webhook_delivery_attempts
+WHERE
+	delivery_id = $1
+ORDER BY
+	attempted_at;
+
+-- name: ReplayWebhookDelivery :execrows
+UPDATE booking.webhook_deliveries
+SET
+	status = 'pending',
+	attempts = 0,
+	next_attempt_at = CURRENT_TIMESTAMP,
+	updated_at = CURRENT_TIMESTAMP
+WHERE
+	id = $1;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.room_type_restrictions (
+		hotel_id UUID NOT NULL,
+		room_type_id UUID NOT NULL,
+		date DATE NOT NULL,
+		min_length_of_stay INT CHECK (min_length_of_stay > 0),
+		max_length_of_stay INT CHECK (max_length_of_stay > 0),
+		closed_to_arrival BOOLEAN NOT NULL DEFAULT FALSE,
+		closed_to_departure BOOLEAN NOT NULL DEFAULT FALSE,
+		stop_sell BOOLEAN NOT NULL DEFAULT FALSE,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hotel_id, room_type_id, date),
+		FOREIGN KEY (hotel_id) REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		FOREIGN KEY (room_type_id) REFERENCES booking.room_types (id) ON DELETE CASCADE,
+		CHECK (max_length_of_stay >= min_length_of_stay)
+	);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.room_type_restrictions;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	auth.user_roles (
+		user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+		role VARCHAR(50) NOT NULL,
+		-- NULL hotel_id means the role applies to every hotel
+		hotel_id UUID REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+CREATE UNIQUE INDEX idx_user_roles_unique ON auth.user_roles (
+	user_id,
+	role,
+	COALESCE(hotel_id, '00000000-0000-0000-0000-000000000000')
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE auth.user_roles;
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.overbooking_policies (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+		-- NULL hotel_id marks the global default policy
+		hotel_id UUID REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		-- NULL room_type_id applies the policy to every room type of the hotel
+		room_type_id UUID REFERENCES booking.room_types (id) ON DELETE CASCADE,
+		valid_from DATE,
+		valid_to DATE,
+		factor DOUBLE PRECISION NOT NULL CHECK (factor >= 1),
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		CHECK (valid_to >= valid_from),
+		CHECK (
+			hotel_id IS NOT NULL
+			OR (
+				room_type_id IS NULL
+				AND valid_from IS NULL
+				AND valid_to IS NULL
+			)
+		)
+	);
+
+CREATE INDEX idx_overbooking_policies_hotel ON booking.overbooking_policies (hotel_id);
+
+-- Only one global default may exist
+CREATE UNIQUE INDEX idx_overbooking_policies_global ON booking.overbooking_policies ((hotel_id IS NULL))
+WHERE
+	hotel_id IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.overbooking_policies;
+
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.waitlist_entries (
+		id UUID PRIMARY KEY,
+		hotel_id UUID NOT NULL,
+		room_type_id UUID NOT NULL,
+		guest_id UUID NOT NULL,
+		start_date DATE NOT NULL,
+		end_date DATE NOT NULL,
+		status VARCHAR(50) NOT NULL,
+		-- Set while the entry holds a reservation offered to the guest
+		reservation_id UUID,
+		offer_expires_at TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		CHECK (end_date >= start_date),
+		FOREIGN KEY (hotel_id) REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		FOREIGN KEY (room_type_id) REFERENCES booking.room_types (id) ON DELETE CASCADE,
+		FOREIGN KEY (guest_id) REFERENCES booking.guests (id) ON DELETE CASCADE,
+		FOREIGN KEY (reservation_id) REFERENCES booking.reservations (id) ON DELETE SET NULL
+	);
+
+-- Queue order within a room type
+CREATE INDEX idx_waitlist_entries_queue ON booking.waitlist_entries (hotel_id, room_type_id, status, created_at);
+
+CREATE INDEX idx_waitlist_entries_guest ON booking.waitlist_entries (guest_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.waitlist_entries;
+
+-- +goose StatementEnd
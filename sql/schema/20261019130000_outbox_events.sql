@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.outbox_events (
+		id UUID PRIMARY KEY,
+		aggregate_type VARCHAR(50) NOT NULL,
+		aggregate_id UUID NOT NULL,
+		event_type VARCHAR(100) NOT NULL,
+		payload JSONB NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT,
+		-- NULL until the relay has handed the event to the publisher
+		published_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+CREATE INDEX idx_outbox_events_unpublished ON booking.outbox_events (created_at)
+WHERE
+	published_at IS NULL;
+
+CREATE INDEX idx_outbox_events_aggregate ON booking.outbox_events (aggregate_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.outbox_events;
+
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.webhook_subscriptions (
+		id UUID PRIMARY KEY,
+		hotel_id UUID NOT NULL REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		url TEXT NOT NULL,
+		-- Shared secret used to HMAC-sign deliveries
+		secret TEXT NOT NULL,
+		event_types TEXT[] NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+CREATE INDEX idx_webhook_subscriptions_hotel ON booking.webhook_subscriptions (hotel_id);
+
+CREATE TABLE
+	booking.webhook_deliveries (
+		id UUID PRIMARY KEY,
+		subscription_id UUID NOT NULL REFERENCES booking.webhook_subscriptions (id) ON DELETE CASCADE,
+		event_id UUID NOT NULL,
+		event_type VARCHAR(100) NOT NULL,
+		payload JSONB NOT NULL,
+		-- pending, delivered or dead
+		status VARCHAR(50) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_status_code INT,
+		last_error TEXT,
+		delivered_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		-- Events are relayed at-least-once, deliver each of them once per subscription
+		UNIQUE (subscription_id, event_id)
+	);
+
+CREATE INDEX idx_webhook_deliveries_due ON booking.webhook_deliveries (next_attempt_at)
+WHERE
+	status = 'pending';
+
+CREATE TABLE
+	booking.webhook_delivery_attempts (
+		id BIGSERIAL PRIMARY KEY,
+		delivery_id UUID NOT NULL REFERENCES booking.webhook_deliveries (id) ON DELETE CASCADE,
+		status_code INT,
+		error TEXT,
+		duration_ms INT NOT NULL,
+		attempted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON booking.webhook_delivery_attempts (delivery_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.webhook_delivery_attempts;
+
+DROP TABLE booking.webhook_deliveries;
+
+DROP TABLE booking.webhook_subscriptions;
+
+-- +goose StatementEnd
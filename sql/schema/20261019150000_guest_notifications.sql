@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE booking.guests
+ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+
+CREATE TABLE
+	booking.notifications (
+		id UUID PRIMARY KEY,
+		reservation_id UUID NOT NULL REFERENCES booking.reservations (id) ON DELETE CASCADE,
+		-- booking_confirmation, pre_arrival_reminder, modification or cancellation
+		kind VARCHAR(50) NOT NULL,
+		locale VARCHAR(10) NOT NULL,
+		recipient VARCHAR(255) NOT NULL,
+		subject TEXT NOT NULL,
+		body_text TEXT NOT NULL,
+		body_html TEXT NOT NULL,
+		-- iCalendar attachment, rendered together with the bodies
+		calendar TEXT NOT NULL,
+		-- Makes enqueueing idempotent: the source event ID, or the reservation ID for reminders
+		dedupe_key VARCHAR(255) NOT NULL UNIQUE,
+		-- pending, sent or failed
+		status VARCHAR(50) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_error TEXT,
+		sent_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+CREATE INDEX idx_notifications_reservation ON booking.notifications (reservation_id);
+
+CREATE INDEX idx_notifications_due ON booking.notifications (next_attempt_at)
+WHERE
+	status = 'pending';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.notifications;
+
+ALTER TABLE booking.guests
+DROP COLUMN locale;
+
+-- +goose StatementEnd
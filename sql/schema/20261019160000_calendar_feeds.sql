@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.calendar_feeds (
+		id UUID PRIMARY KEY,
+		hotel_id UUID NOT NULL REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		-- NULL covers every room type of the hotel
+		room_type_id UUID REFERENCES booking.room_types (id) ON DELETE CASCADE,
+		-- Secret part of the feed URL, calendar apps can't send Authorization headers
+		token VARCHAR(100) NOT NULL UNIQUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+CREATE INDEX idx_calendar_feeds_hotel ON booking.calendar_feeds (hotel_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.calendar_feeds;
+
+-- +goose StatementEnd
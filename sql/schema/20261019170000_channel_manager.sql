@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.channels (
+		id UUID PRIMARY KEY,
+		hotel_id UUID NOT NULL REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL,
+		-- Protocol spoken by the channel, e.g. ota_xml
+		adapter VARCHAR(50) NOT NULL,
+		endpoint_url TEXT NOT NULL,
+		-- Code the channel knows the hotel by
+		hotel_code VARCHAR(100) NOT NULL,
+		-- Sent with outgoing messages and expected on incoming notifications
+		api_key TEXT NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		last_synced_at TIMESTAMP,
+		last_sync_error TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (hotel_id, name)
+	);
+
+CREATE TABLE
+	booking.channel_room_mappings (
+		channel_id UUID NOT NULL REFERENCES booking.channels (id) ON DELETE CASCADE,
+		room_type_id UUID NOT NULL REFERENCES booking.room_types (id) ON DELETE CASCADE,
+		external_room_code VARCHAR(100) NOT NULL,
+		rate_plan_code VARCHAR(100) NOT NULL,
+		-- Nightly rate pushed together with availability
+		rate NUMERIC(10, 2) NOT NULL,
+		currency CHAR(3) NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (channel_id, room_type_id),
+		UNIQUE (channel_id, external_room_code)
+	);
+
+CREATE TABLE
+	booking.channel_reservations (
+		channel_id UUID NOT NULL REFERENCES booking.channels (id) ON DELETE CASCADE,
+		-- Booking reference assigned by the channel
+		external_id VARCHAR(100) NOT NULL,
+		reservation_id UUID NOT NULL REFERENCES booking.reservations (id) ON DELETE CASCADE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (channel_id, external_id)
+	);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.channel_reservations;
+
+DROP TABLE booking.channel_room_mappings;
+
+DROP TABLE booking.channels;
+
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE booking.hotels
+ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE
+	booking.inventory_cron_runs (
+		id UUID PRIMARY KEY,
+		-- Hotels the run covers, e.g. tz=Europe/Kyiv,shard=1/4
+		partition_key VARCHAR(255) NOT NULL,
+		-- Last date the run fills inventory up to, kept so a resumed run extends to the same date
+		window_end DATE NOT NULL,
+		-- running, completed or failed. Runs left running by a crash are resumed by the next run of the partition
+		status VARCHAR(20) NOT NULL DEFAULT 'running',
+		failed_hotels INT NOT NULL DEFAULT 0,
+		started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		finished_at TIMESTAMP
+	);
+
+CREATE INDEX inventory_cron_runs_partition_idx ON booking.inventory_cron_runs (partition_key, started_at DESC);
+
+CREATE TABLE
+	booking.inventory_cron_checkpoints (
+		run_id UUID NOT NULL REFERENCES booking.inventory_cron_runs (id) ON DELETE CASCADE,
+		hotel_id UUID NOT NULL REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		completed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (run_id, hotel_id)
+	);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.inventory_cron_checkpoints;
+
+DROP TABLE booking.inventory_cron_runs;
+
+ALTER TABLE booking.hotels
+DROP COLUMN time_zone;
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE booking.inventory_cron_runs
+-- Set for runs an operator triggered for a single hotel
+ADD COLUMN hotel_id UUID REFERENCES booking.hotels (id) ON DELETE CASCADE,
+ADD COLUMN hotels_processed INT NOT NULL DEFAULT 0,
+ADD COLUMN rows_inserted BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE
+	booking.inventory_cron_run_errors (
+		id BIGSERIAL PRIMARY KEY,
+		run_id UUID NOT NULL REFERENCES booking.inventory_cron_runs (id) ON DELETE CASCADE,
+		hotel_id UUID NOT NULL REFERENCES booking.hotels (id) ON DELETE CASCADE,
+		-- Empty when the hotel failed before any of its room types were populated
+		room_type_id UUID REFERENCES booking.room_types (id) ON DELETE CASCADE,
+		error TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+CREATE INDEX inventory_cron_run_errors_run_idx ON booking.inventory_cron_run_errors (run_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.inventory_cron_run_errors;
+
+ALTER TABLE booking.inventory_cron_runs
+DROP COLUMN rows_inserted,
+DROP COLUMN hotels_processed,
+DROP COLUMN hotel_id;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Wakes the relays listening on hotel_events once events commit, the payload is empty: the rows stay the
+-- source of truth and the relay claiming them marks them published.
+CREATE FUNCTION booking.notify_outbox_events () RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM pg_notify('hotel_events', '');
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_events_notify
+AFTER INSERT ON booking.outbox_events FOR EACH STATEMENT
+EXECUTE FUNCTION booking.notify_outbox_events ();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER outbox_events_notify ON booking.outbox_events;
+
+DROP FUNCTION booking.notify_outbox_events ();
+
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE booking.reservations
+-- Bumped by every change, calendar clients keep the copy of an entry with the highest SEQUENCE
+ADD COLUMN revision INT NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE booking.reservations
+DROP COLUMN revision;
+
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE booking.outbox_events
+-- A failed event isn't claimed again before this time, the relay backs off exponentially
+ADD COLUMN next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Subscribers that handled an event, retries of a failed event only call the others
+CREATE TABLE
+	booking.outbox_deliveries (
+		event_id UUID NOT NULL REFERENCES booking.outbox_events (id) ON DELETE CASCADE,
+		subscriber VARCHAR(100) NOT NULL,
+		delivered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (event_id, subscriber)
+	);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.outbox_deliveries;
+
+ALTER TABLE booking.outbox_events
+DROP COLUMN next_attempt_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+	booking.guest_calendar_feeds (
+		-- A guest has one feed, issuing a new token revokes the previous URL
+		guest_id UUID PRIMARY KEY REFERENCES booking.guests (id) ON DELETE CASCADE,
+		-- Secret part of the feed URL, calendar apps can't send Authorization headers
+		token VARCHAR(100) NOT NULL UNIQUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE booking.guest_calendar_feeds;
+
+-- +goose StatementEnd